@@ -6,6 +6,9 @@ import (
 
 	"github.com/Yw332/campus-moments-go/internal/models"
 	"github.com/Yw332/campus-moments-go/internal/routes"
+	"github.com/Yw332/campus-moments-go/internal/search"
+	"github.com/Yw332/campus-moments-go/internal/service"
+	"github.com/Yw332/campus-moments-go/internal/timeline"
 	"github.com/Yw332/campus-moments-go/pkg/config"
 	"github.com/Yw332/campus-moments-go/pkg/database"
 	"github.com/joho/godotenv"
@@ -41,6 +44,17 @@ func main() {
 		log.Println("✅ 数据库连接正常")
 		// 自动迁移数据库表结构
 		models.AutoMigrate()
+		// 启动后台热度分重算任务
+		service.StartHotScoreWorker()
+		// 启动后台定时发布任务
+		service.StartPostScheduler()
+		// 启动过期快拍清理任务
+		service.StartStoryCleaner()
+		// 首页时间线使用进程内存储
+		timeline.SetStore(timeline.NewMemoryStore(config.Cfg.Feed.TimelineMaxLength, config.Cfg.Feed.TimelineIdleTTL))
+		// 全文搜索使用进程内索引，启动后在后台建立
+		search.SetEngine(search.NewMemoryEngine())
+		service.StartSearchIndexer()
 	} else {
 		log.Println("⚠️  数据库未连接，某些功能可能不可用")
 	}
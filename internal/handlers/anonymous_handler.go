@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Yw332/campus-moments-go/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateAnonymousPost 发布树洞帖子
+func CreateAnonymousPost(c *gin.Context) {
+	var req struct {
+		Title   string   `json:"title" binding:"max=100"`
+		Content string   `json:"content" binding:"required,min=1,max=10000"`
+		Images  []string `json:"images"`
+		Video   string   `json:"video"`
+		Tags    []string `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	post, err := service.CreateAnonymousPost(c.GetString("userID"), req.Title, req.Content, req.Images, req.Video, req.Tags)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "发布失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    200,
+		"message": "发布成功",
+		"data":    post,
+	})
+}
+
+// GetMyAnonymousPosts 获取我发布的树洞帖子
+func GetMyAnonymousPosts(c *gin.Context) {
+	opts := parsePageOptions(c, 20)
+
+	posts, pageInfo, err := service.GetMyAnonymousPosts(c.GetString("userID"), opts)
+	if err != nil {
+		respondPageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": setPageInfo(gin.H{
+			"posts": posts,
+		}, opts, pageInfo),
+	})
+}
+
+// AdminRevealAnonymousAuthor 管理员查看树洞帖子的真实作者（必须填写原因，记录审计日志）
+func AdminRevealAnonymousAuthor(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的帖子ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason" binding:"required,min=2,max=500"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请填写查看原因: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	reveal, err := service.RevealAnonymousAuthor(c.GetString("userID"), postID, req.Reason)
+	if err != nil {
+		status := http.StatusInternalServerError
+		message := "查看失败: " + err.Error()
+		switch {
+		case err == service.ErrNotAnonymousPost:
+			status, message = http.StatusBadRequest, err.Error()
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			status, message = http.StatusNotFound, "帖子不存在"
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": message,
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "查看成功，本次操作已记录",
+		"data":    reveal,
+	})
+}
+
+// AdminGetAnonymousReveals 管理员查看揭示树洞作者的审计日志，可按 postId 筛选
+func AdminGetAnonymousReveals(c *gin.Context) {
+	postID, _ := strconv.ParseInt(c.Query("postId"), 10, 64)
+	opts := parsePageOptions(c, 20)
+
+	reveals, pageInfo, err := service.GetAnonymousReveals(postID, opts)
+	if err != nil {
+		respondPageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": setPageInfo(gin.H{
+			"reveals": reveals,
+		}, opts, pageInfo),
+	})
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Yw332/campus-moments-go/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AddBookmark 收藏帖子，可指定收藏夹
+func AddBookmark(c *gin.Context) {
+	postID, ok := parseBookmarkPostID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		FolderID int64 `json:"folderId"` // 为0或不传时不放入收藏夹
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "参数错误: " + err.Error(),
+				"data":    nil,
+			})
+			return
+		}
+	}
+
+	bookmark, err := service.AddBookmark(c.GetString("userID"), postID, req.FolderID)
+	if err != nil {
+		respondBookmarkError(c, "收藏失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "收藏成功",
+		"data":    bookmark,
+	})
+}
+
+// MoveBookmark 把收藏移动到另一个收藏夹
+func MoveBookmark(c *gin.Context) {
+	postID, ok := parseBookmarkPostID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		FolderID *int64 `json:"folderId" binding:"required"` // 为0时移出收藏夹
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	bookmark, err := service.MoveBookmark(c.GetString("userID"), postID, *req.FolderID)
+	if err != nil {
+		respondBookmarkError(c, "移动失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "移动成功",
+		"data":    bookmark,
+	})
+}
+
+// RemoveBookmark 取消收藏
+func RemoveBookmark(c *gin.Context) {
+	postID, ok := parseBookmarkPostID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.RemoveBookmark(c.GetString("userID"), postID); err != nil {
+		respondBookmarkError(c, "取消收藏失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已取消收藏",
+		"data":    gin.H{"bookmarked": false},
+	})
+}
+
+// GetBookmarks 获取我的收藏，folderId 不传时返回全部，为0时只返回未分类的收藏
+func GetBookmarks(c *gin.Context) {
+	var folderID *int64
+	if s := c.Query("folderId"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "无效的收藏夹ID",
+				"data":    nil,
+			})
+			return
+		}
+		folderID = &id
+	}
+	opts := parsePageOptions(c, 20)
+
+	bookmarks, pageInfo, err := service.GetBookmarks(c.GetString("userID"), folderID, opts)
+	if err != nil {
+		respondPageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": setPageInfo(gin.H{
+			"bookmarks": bookmarks,
+		}, opts, pageInfo),
+	})
+}
+
+// GetBookmarkFolders 获取我的收藏夹
+func GetBookmarkFolders(c *gin.Context) {
+	folders, err := service.GetBookmarkFolders(c.GetString("userID"))
+	if err != nil {
+		respondBookmarkError(c, "获取失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    gin.H{"folders": folders},
+	})
+}
+
+// CreateBookmarkFolder 创建收藏夹
+func CreateBookmarkFolder(c *gin.Context) {
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	folder, err := service.CreateBookmarkFolder(c.GetString("userID"), req.Name)
+	if err != nil {
+		respondBookmarkError(c, "创建失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    200,
+		"message": "创建成功",
+		"data":    folder,
+	})
+}
+
+// RenameBookmarkFolder 重命名收藏夹
+func RenameBookmarkFolder(c *gin.Context) {
+	folderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的收藏夹ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	folder, err := service.RenameBookmarkFolder(c.GetString("userID"), folderID, req.Name)
+	if err != nil {
+		respondBookmarkError(c, "重命名失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "重命名成功",
+		"data":    folder,
+	})
+}
+
+// DeleteBookmarkFolder 删除收藏夹，其中的收藏移回未分类
+func DeleteBookmarkFolder(c *gin.Context) {
+	folderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的收藏夹ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	if err := service.DeleteBookmarkFolder(c.GetString("userID"), folderID); err != nil {
+		respondBookmarkError(c, "删除失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除成功",
+		"data":    nil,
+	})
+}
+
+// parseBookmarkPostID 解析路径中的帖子ID，无效时直接返回 400
+func parseBookmarkPostID(c *gin.Context) (int64, bool) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的帖子ID",
+			"data":    nil,
+		})
+		return 0, false
+	}
+	return postID, true
+}
+
+// respondBookmarkError 返回收藏相关的错误
+func respondBookmarkError(c *gin.Context, prefix string, err error) {
+	status := http.StatusInternalServerError
+	message := prefix + err.Error()
+	switch {
+	case err == service.ErrBookmarkFolderName, err == service.ErrBookmarkFolderLimit:
+		status, message = http.StatusBadRequest, err.Error()
+	case err == service.ErrBookmarkFolderExists:
+		status, message = http.StatusConflict, err.Error()
+	case err == service.ErrBookmarkFolderNotFound, err == service.ErrBookmarkNotFound:
+		status, message = http.StatusNotFound, err.Error()
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status, message = http.StatusNotFound, "帖子不存在"
+	}
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": message,
+		"data":    nil,
+	})
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/Yw332/campus-moments-go/internal/service"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"net/http"
 	"strconv"
 )
@@ -34,8 +36,7 @@ func CreateComment(c *gin.Context) {
 	}
 	
 	var req struct {
-		Content string                 `json:"content" binding:"required,min=1,max=1000"`
-		Replies []map[string]interface{} `json:"replies"`
+		Content string `json:"content" binding:"required,min=1,max=1000"`
 	}
 	
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -48,8 +49,16 @@ func CreateComment(c *gin.Context) {
 	}
 	
 	userID := c.GetString("userID")
-	comment, err := service.CreateComment(postID, userID, req.Content, req.Replies)
+	comment, err := service.CreateComment(postID, userID, req.Content)
 	if err != nil {
+		if err == service.ErrUserBlocked || err == service.ErrCommentsClosed || err == service.ErrCommentsFriendsOnly {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    http.StatusForbidden,
+				"message": err.Error(),
+				"data":    nil,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    http.StatusInternalServerError,
 			"message": "创建失败: " + err.Error(),
@@ -65,7 +74,8 @@ func CreateComment(c *gin.Context) {
 	})
 }
 
-// GetCommentList 获取评论列表
+// GetCommentList 获取一级评论列表，replies 参数指定每条评论附带的回复数（默认3，最多10）
+// sort 参数：不传按时间正序，latest 按时间倒序，hot 按点赞数倒序
 func GetCommentList(c *gin.Context) {
 	postID, err := getPostID(c)
 	if err != nil {
@@ -77,28 +87,63 @@ func GetCommentList(c *gin.Context) {
 		return
 	}
 	
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	opts := parsePageOptions(c, 20)
+	switch c.Query("sort") {
+	case service.SortHot, service.SortLatest:
+		opts.Sort = c.Query("sort")
+	}
+	previewN, _ := strconv.Atoi(c.Query("replies"))
 	
-	comments, total, err := service.GetCommentList(postID, page, pageSize)
+	comments, pageInfo, err := service.GetCommentList(postID, c.GetString("userID"), opts, previewN)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    http.StatusInternalServerError,
-			"message": "获取失败: " + err.Error(),
+		respondPageError(c, err)
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"message":  "获取成功",
+		"data": setPageInfo(gin.H{
+			"comments": comments,
+		}, opts, pageInfo),
+	})
+}
+
+// GetCommentReplies 分页获取一条一级评论下的全部回复
+func GetCommentReplies(c *gin.Context) {
+	commentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    http.StatusBadRequest,
+			"message": "无效的评论ID",
 			"data":    nil,
 		})
 		return
 	}
 	
+	opts := parsePageOptions(c, 20)
+	
+	root, replies, pageInfo, err := service.GetCommentReplies(commentID, c.GetString("userID"), opts)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    http.StatusNotFound,
+			"message": "评论不存在或不是一级评论",
+			"data":    nil,
+		})
+		return
+	}
+	if err != nil {
+		respondPageError(c, err)
+		return
+	}
+	
 	c.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"message":  "获取成功",
-		"data": gin.H{
-			"comments": comments,
-			"total": total,
-			"page":  page,
-			"pageSize": pageSize,
-		},
+		"data": setPageInfo(gin.H{
+			"comment": root,
+			"replies": replies,
+		}, opts, pageInfo),
 	})
 }
 
@@ -145,7 +190,7 @@ func UpdateComment(c *gin.Context) {
 	})
 }
 
-// DeleteComment 删除评论
+// DeleteComment 删除评论（评论作者或帖子作者）
 func DeleteComment(c *gin.Context) {
 	commentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -189,6 +234,14 @@ func LikeComment(c *gin.Context) {
 	userID := c.GetString("userID")
 	liked, err := service.ToggleLikeComment(commentID, userID)
 	if err != nil {
+		if err == service.ErrUserBlocked {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    http.StatusForbidden,
+				"message": err.Error(),
+				"data":    nil,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    http.StatusInternalServerError,
 			"message": "操作失败: " + err.Error(),
@@ -241,6 +294,14 @@ func ReplyComment(c *gin.Context) {
 	userID := c.GetString("userID")
 	comment, err := service.ReplyComment(commentID, userID, req.Content)
 	if err != nil {
+		if err == service.ErrUserBlocked || err == service.ErrCommentsClosed || err == service.ErrCommentsFriendsOnly {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    http.StatusForbidden,
+				"message": err.Error(),
+				"data":    nil,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    http.StatusInternalServerError,
 			"message": "回复失败: " + err.Error(),
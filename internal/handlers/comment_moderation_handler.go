@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Yw332/campus-moments-go/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SetCommentPolicy 帖子作者设置评论权限：0-所有人 1-仅好友 2-关闭评论
+func SetCommentPolicy(c *gin.Context) {
+	postID, ok := parseModerationPostID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Policy *int `json:"policy" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	post, err := service.SetCommentPolicy(postID, c.GetString("userID"), *req.Policy)
+	if err != nil {
+		respondCommentModerationError(c, "设置失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "设置成功",
+		"data": gin.H{
+			"postId":        post.ID,
+			"commentPolicy": post.CommentPolicy,
+		},
+	})
+}
+
+// PinComment 帖子作者置顶一条一级评论
+func PinComment(c *gin.Context) {
+	postID, ok := parseModerationPostID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		CommentID int64 `json:"commentId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	if err := service.PinComment(postID, c.GetString("userID"), req.CommentID); err != nil {
+		respondCommentModerationError(c, "置顶失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "置顶成功",
+		"data":    gin.H{"pinnedCommentId": req.CommentID},
+	})
+}
+
+// UnpinComment 帖子作者取消置顶
+func UnpinComment(c *gin.Context) {
+	postID, ok := parseModerationPostID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.UnpinComment(postID, c.GetString("userID")); err != nil {
+		respondCommentModerationError(c, "取消置顶失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已取消置顶",
+		"data":    gin.H{"pinnedCommentId": 0},
+	})
+}
+
+// GetHiddenComments 帖子作者查看自己帖子下被隐藏的评论
+func GetHiddenComments(c *gin.Context) {
+	postID, ok := parseModerationPostID(c)
+	if !ok {
+		return
+	}
+	opts := parsePageOptions(c, 20)
+
+	comments, pageInfo, err := service.GetHiddenComments(postID, c.GetString("userID"), opts)
+	if err == service.ErrInvalidCursor {
+		respondPageError(c, err)
+		return
+	}
+	if err != nil {
+		respondCommentModerationError(c, "获取失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": setPageInfo(gin.H{
+			"comments": comments,
+		}, opts, pageInfo),
+	})
+}
+
+// HideComment 帖子作者隐藏自己帖子下的评论
+func HideComment(c *gin.Context) {
+	commentID, ok := parseModerationCommentID(c)
+	if !ok {
+		return
+	}
+
+	comment, err := service.HideComment(commentID, c.GetString("userID"))
+	if err != nil {
+		respondCommentModerationError(c, "隐藏失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已隐藏",
+		"data": gin.H{
+			"commentId": comment.ID,
+			"status":    comment.Status,
+		},
+	})
+}
+
+// UnhideComment 帖子作者恢复被隐藏的评论
+func UnhideComment(c *gin.Context) {
+	commentID, ok := parseModerationCommentID(c)
+	if !ok {
+		return
+	}
+
+	comment, err := service.UnhideComment(commentID, c.GetString("userID"))
+	if err != nil {
+		respondCommentModerationError(c, "恢复失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已恢复",
+		"data": gin.H{
+			"commentId": comment.ID,
+			"status":    comment.Status,
+		},
+	})
+}
+
+// parseModerationPostID 解析路径中的帖子ID，无效时直接返回 400
+func parseModerationPostID(c *gin.Context) (int64, bool) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的帖子ID",
+			"data":    nil,
+		})
+		return 0, false
+	}
+	return postID, true
+}
+
+// parseModerationCommentID 解析路径中的评论ID，无效时直接返回 400
+func parseModerationCommentID(c *gin.Context) (int64, bool) {
+	commentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的评论ID",
+			"data":    nil,
+		})
+		return 0, false
+	}
+	return commentID, true
+}
+
+// respondCommentModerationError 返回评论管理相关的错误
+func respondCommentModerationError(c *gin.Context, prefix string, err error) {
+	status := http.StatusInternalServerError
+	message := prefix + err.Error()
+	switch {
+	case err == service.ErrInvalidCommentPolicy, err == service.ErrAnonymousFriendsPolicy, err == service.ErrCommentNotPinnable:
+		status, message = http.StatusBadRequest, err.Error()
+	case err == service.ErrNotPostAuthor:
+		status, message = http.StatusForbidden, err.Error()
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status, message = http.StatusNotFound, "帖子或评论不存在，或无权限管理"
+	}
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": message,
+		"data":    nil,
+	})
+}
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
+	"github.com/Yw332/campus-moments-go/internal/service"
 	"github.com/Yw332/campus-moments-go/pkg/database"
 	"github.com/gin-gonic/gin"
 )
@@ -41,3 +43,67 @@ func HealthCheck(c *gin.Context) {
 		},
 	})
 }
+
+// parsePageOptions 解析列表分页参数：传 cursor 时按游标分页，否则按 page 页码分页
+// 游标分页默认不统计总数，页码分页默认统计以兼容旧客户端，可用 withTotal 显式指定
+func parsePageOptions(c *gin.Context, defaultPageSize int) service.PageOptions {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", strconv.Itoa(defaultPageSize)))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = defaultPageSize
+	}
+
+	opts := service.PageOptions{
+		Page:     page,
+		PageSize: pageSize,
+		Cursor:   c.Query("cursor"),
+	}
+	opts.WithTotal = opts.Cursor == ""
+	if withTotal, err := strconv.ParseBool(c.Query("withTotal")); err == nil {
+		opts.WithTotal = withTotal
+	}
+
+	return opts
+}
+
+// parsePostSort 解析帖子列表排序方式，sort=hot 按热度排序，其余按发布时间排序
+func parsePostSort(c *gin.Context) string {
+	if c.Query("sort") == service.SortHot {
+		return service.SortHot
+	}
+	return service.SortLatest
+}
+
+// setPageInfo 在列表响应中附加分页信息，未统计总数时不返回 total
+func setPageInfo(data gin.H, opts service.PageOptions, info service.PageInfo) gin.H {
+	data["pageSize"] = opts.PageSize
+	data["nextCursor"] = info.NextCursor
+	data["hasMore"] = info.HasMore
+	if opts.Cursor == "" {
+		data["page"] = opts.Page
+	}
+	if opts.WithTotal {
+		data["total"] = info.Total
+	}
+	return data
+}
+
+// respondPageError 列表查询失败的统一响应
+func respondPageError(c *gin.Context, err error) {
+	if err == service.ErrInvalidCursor {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    500,
+		"message": "获取失败: " + err.Error(),
+		"data":    nil,
+	})
+}
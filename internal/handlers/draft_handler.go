@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetDraftPosts 获取我的草稿
+func GetDraftPosts(c *gin.Context) {
+	opts := parsePageOptions(c, 20)
+
+	posts, pageInfo, err := service.GetDraftPosts(c.GetString("userID"), opts)
+	if err != nil {
+		respondPageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": setPageInfo(gin.H{
+			"posts": unpublishedPosts(posts),
+		}, opts, pageInfo),
+	})
+}
+
+// GetScheduledPosts 获取我的定时帖子
+func GetScheduledPosts(c *gin.Context) {
+	opts := parsePageOptions(c, 20)
+
+	posts, pageInfo, err := service.GetScheduledPosts(c.GetString("userID"), opts)
+	if err != nil {
+		respondPageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": setPageInfo(gin.H{
+			"posts": unpublishedPosts(posts),
+		}, opts, pageInfo),
+	})
+}
+
+// unpublishedPosts 转换草稿和定时帖子的响应格式
+func unpublishedPosts(posts []models.Post) []gin.H {
+	result := make([]gin.H, 0, len(posts))
+	for _, post := range posts {
+		result = append(result, gin.H{
+			"postId":     post.ID,
+			"title":      post.Title,
+			"content":    post.Content,
+			"images":     post.Images,
+			"video":      post.Video,
+			"tags":       post.Tags,
+			"visibility": post.Visibility,
+			"status":     post.Status,
+			"publishAt":  post.PublishAt,
+			"createdAt":  post.CreatedAt,
+			"updatedAt":  post.UpdatedAt,
+		})
+	}
+	return result
+}
+
+// PublishPost 立即发布草稿或定时帖子
+func PublishPost(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的帖子ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	post, err := service.PublishPost(postID, c.GetString("userID"))
+	if err != nil {
+		respondUnpublishedPostError(c, "发布失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "发布成功",
+		"data":    post,
+	})
+}
+
+// SchedulePost 设置或取消草稿的定时发布
+func SchedulePost(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的帖子ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var req struct {
+		PublishAt *time.Time `json:"publishAt"` // 为空时取消定时，转回草稿
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	post, err := service.SchedulePost(postID, c.GetString("userID"), req.PublishAt)
+	if err != nil {
+		respondUnpublishedPostError(c, "设置失败: ", err)
+		return
+	}
+
+	message := "已设置定时发布"
+	if req.PublishAt == nil {
+		message = "已取消定时发布"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message,
+		"data":    post,
+	})
+}
+
+// respondUnpublishedPostError 返回草稿和定时帖子操作的错误
+func respondUnpublishedPostError(c *gin.Context, prefix string, err error) {
+	status := http.StatusInternalServerError
+	message := prefix + err.Error()
+	switch {
+	case err == service.ErrPublishAtInPast:
+		status, message = http.StatusBadRequest, err.Error()
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status, message = http.StatusNotFound, "草稿不存在或已发布"
+	}
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": message,
+		"data":    nil,
+	})
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FollowUser 关注用户
+func FollowUser(c *gin.Context) {
+	targetID := c.Param("userId")
+	userID := c.GetString("userID")
+
+	if err := service.FollowUser(userID, targetID); err != nil {
+		status := http.StatusInternalServerError
+		message := "关注失败: " + err.Error()
+		switch {
+		case err == service.ErrCannotFollowSelf:
+			status, message = http.StatusBadRequest, err.Error()
+		case err == service.ErrUserBlocked:
+			status, message = http.StatusForbidden, err.Error()
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			status, message = http.StatusNotFound, "用户不存在"
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": message,
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "关注成功",
+		"data":    gin.H{"following": true},
+	})
+}
+
+// UnfollowUser 取消关注
+func UnfollowUser(c *gin.Context) {
+	targetID := c.Param("userId")
+	userID := c.GetString("userID")
+
+	if err := service.UnfollowUser(userID, targetID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "取消关注失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已取消关注",
+		"data":    gin.H{"following": false},
+	})
+}
+
+// GetFollowers 获取粉丝列表
+func GetFollowers(c *gin.Context) {
+	opts := parsePageOptions(c, 20)
+
+	follows, pageInfo, err := service.GetFollowers(c.GetString("userID"), c.Param("userId"), opts)
+	if err != nil {
+		respondPageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": setPageInfo(gin.H{
+			"users": followUsers(follows),
+		}, opts, pageInfo),
+	})
+}
+
+// GetFollowing 获取关注列表
+func GetFollowing(c *gin.Context) {
+	opts := parsePageOptions(c, 20)
+
+	follows, pageInfo, err := service.GetFollowing(c.GetString("userID"), c.Param("userId"), opts)
+	if err != nil {
+		respondPageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": setPageInfo(gin.H{
+			"users": followUsers(follows),
+		}, opts, pageInfo),
+	})
+}
+
+// followUsers 转换关注列表的响应格式
+func followUsers(follows []models.Follow) []gin.H {
+	users := make([]gin.H, 0, len(follows))
+	for _, f := range follows {
+		if f.User == nil {
+			continue
+		}
+		users = append(users, gin.H{
+			"userId":      f.User.ID,
+			"username":    f.User.Username,
+			"avatarUrl":   f.User.AvatarURL,
+			"signature":   f.User.Signature,
+			"followedAt":  f.CreatedAt,
+			"isFollowing": f.IsFollowing,
+		})
+	}
+	return users
+}
+
+// GetFollowingFeed 获取关注动态
+func GetFollowingFeed(c *gin.Context) {
+	opts := parsePageOptions(c, 20)
+	opts.Sort = parsePostSort(c)
+	userID := c.GetString("userID")
+
+	posts, pageInfo, err := service.GetFollowingFeed(userID, opts)
+	if err != nil {
+		respondPageError(c, err)
+		return
+	}
+
+	// 转换为响应格式（id -> postId, user -> author）
+	convertedPosts := make([]map[string]interface{}, 0, len(posts))
+	for _, post := range posts {
+		postData := map[string]interface{}{
+			"postId":       post.ID,
+			"title":        post.Title,
+			"content":      post.Content,
+			"images":       post.Images,
+			"video":        post.Video,
+			"tags":         post.Tags,
+			"createdAt":    post.CreatedAt,
+			"editedAt":     post.EditedAt,
+			"shareCount":   post.ShareCount,
+			"anonymous":    post.Anonymous,
+			"repostOf":     post.RepostOf,
+			"poll":         post.Poll,
+			"isBookmarked": post.IsBookmarked,
+			"commentPolicy": post.CommentPolicy,
+			"reactions": post.Reactions,
+			"myReaction": post.MyReaction,
+			"isLiked": post.IsLiked,
+			"likedByFriends": post.LikedByFriends,
+			"friendLikeCount": post.FriendLikeCount,
+			"likeCount":    post.LikeCount,
+			"commentCount": post.CommentCount,
+			"viewCount":    post.ViewCount,
+			"visibility":   post.Visibility,
+			"mentions":     post.Mentions,
+		}
+
+		// 添加作者信息
+		if post.User != nil {
+			postData["author"] = map[string]interface{}{
+				"userId":    post.User.ID,
+				"username":  post.User.Username,
+				"avatarUrl": post.User.AvatarURL,
+			}
+		}
+
+		convertedPosts = append(convertedPosts, postData)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": setPageInfo(gin.H{
+			"posts": convertedPosts,
+		}, opts, pageInfo),
+	})
+}
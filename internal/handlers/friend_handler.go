@@ -3,6 +3,7 @@ package handlers
 import (
 	"github.com/Yw332/campus-moments-go/internal/service"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"net/http"
 	"strconv"
 )
@@ -22,6 +23,10 @@ func SendFriendRequest(c *gin.Context) {
 	fromUserID := c.GetString("userID")
 	request, err := service.SendFriendRequest(fromUserID, req.ToUserID, req.Message)
 	if err != nil {
+		if err == service.ErrUserBlocked {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "发送失败: " + err.Error()})
 		return
 	}
@@ -202,4 +207,72 @@ func SearchFriends(c *gin.Context) {
 			"pageSize": pageSize,
 		},
 	})
-}
\ No newline at end of file
+}
+// BlockUser 拉黑用户
+func BlockUser(c *gin.Context) {
+	targetID := c.Param("userId")
+	userID := c.GetString("userID")
+
+	if err := service.BlockUser(userID, targetID); err != nil {
+		switch err {
+		case service.ErrCannotBlockSelf:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case gorm.ErrRecordNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "拉黑失败: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "已拉黑",
+		"data":    nil,
+	})
+}
+
+// UnblockUser 取消拉黑
+func UnblockUser(c *gin.Context) {
+	targetID := c.Param("userId")
+	userID := c.GetString("userID")
+
+	if err := service.UnblockUser(userID, targetID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "未拉黑该用户"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "取消拉黑失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "已取消拉黑",
+		"data":    nil,
+	})
+}
+
+// GetBlockList 获取黑名单
+func GetBlockList(c *gin.Context) {
+	userID := c.GetString("userID")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+
+	blocks, total, err := service.GetBlockList(userID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "获取成功",
+		"data": gin.H{
+			"blocks":   blocks,
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+		},
+	})
+}
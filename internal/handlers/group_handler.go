@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Yw332/campus-moments-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateGroup 创建群聊
+func CreateGroup(c *gin.Context) {
+	var req struct {
+		Name      string   `json:"name" binding:"required,max=50"`
+		Avatar    string   `json:"avatar"`
+		MemberIDs []string `json:"memberIds"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    http.StatusBadRequest,
+			"message": "参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	userID := c.GetString("userID")
+	group, err := service.CreateGroup(userID, req.Name, req.Avatar, req.MemberIDs)
+	if err != nil {
+		respondGroupError(c, err, "创建失败")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    http.StatusOK,
+		"message": "创建成功",
+		"data":    group,
+	})
+}
+
+// GetMyGroups 获取我加入的群聊
+func GetMyGroups(c *gin.Context) {
+	userID := c.GetString("userID")
+	groups, err := service.GetMyGroups(userID)
+	if err != nil {
+		respondGroupError(c, err, "获取失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "获取成功",
+		"data":    groups,
+	})
+}
+
+// GetGroupDetail 获取群详情
+func GetGroupDetail(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetString("userID")
+	detail, err := service.GetGroupDetail(userID, groupID)
+	if err != nil {
+		respondGroupError(c, err, "获取失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "获取成功",
+		"data":    detail,
+	})
+}
+
+// UpdateGroup 修改群名称/头像
+func UpdateGroup(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name   string `json:"name" binding:"max=50"`
+		Avatar string `json:"avatar"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    http.StatusBadRequest,
+			"message": "参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	userID := c.GetString("userID")
+	group, err := service.UpdateGroup(userID, groupID, req.Name, req.Avatar)
+	if err != nil {
+		respondGroupError(c, err, "修改失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "修改成功",
+		"data":    group,
+	})
+}
+
+// InviteGroupMembers 邀请好友入群
+func InviteGroupMembers(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		MemberIDs []string `json:"memberIds" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    http.StatusBadRequest,
+			"message": "参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	userID := c.GetString("userID")
+	added, err := service.InviteGroupMembers(userID, groupID, req.MemberIDs)
+	if err != nil {
+		respondGroupError(c, err, "邀请失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "邀请成功",
+		"data": gin.H{
+			"added": added,
+		},
+	})
+}
+
+// RemoveGroupMember 移出群成员
+func RemoveGroupMember(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetString("userID")
+	if err := service.RemoveGroupMember(userID, groupID, c.Param("userId")); err != nil {
+		respondGroupError(c, err, "移出失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "移出成功",
+		"data":    nil,
+	})
+}
+
+// LeaveGroup 退出群聊
+func LeaveGroup(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetString("userID")
+	if err := service.LeaveGroup(userID, groupID); err != nil {
+		respondGroupError(c, err, "退出失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "已退出群聊",
+		"data":    nil,
+	})
+}
+
+// SetGroupAdmin 设置管理员
+func SetGroupAdmin(c *gin.Context) {
+	setGroupAdmin(c, true, "设置成功")
+}
+
+// UnsetGroupAdmin 取消管理员
+func UnsetGroupAdmin(c *gin.Context) {
+	setGroupAdmin(c, false, "已取消管理员")
+}
+
+func setGroupAdmin(c *gin.Context, isAdmin bool, successMsg string) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetString("userID")
+	if err := service.SetGroupAdmin(userID, groupID, c.Param("userId"), isAdmin); err != nil {
+		respondGroupError(c, err, "操作失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": successMsg,
+		"data":    nil,
+	})
+}
+
+// GetGroupMessages 获取群消息列表
+func GetGroupMessages(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "50"))
+	beforeMsgID := c.Query("beforeMsgId") // 分页使用
+
+	userID := c.GetString("userID")
+	messages, total, err := service.GetGroupMessageList(userID, groupID, beforeMsgID, pageSize)
+	if err != nil {
+		respondGroupError(c, err, "获取失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "获取成功",
+		"data": gin.H{
+			"messages": messages,
+			"total":    total,
+			"pageSize": pageSize,
+		},
+	})
+}
+
+// MarkGroupMessagesAsRead 清空群会话未读数
+func MarkGroupMessagesAsRead(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetString("userID")
+	if err := service.MarkGroupMessagesAsRead(userID, groupID); err != nil {
+		respondGroupError(c, err, "标记失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "标记成功",
+		"data":    nil,
+	})
+}
+
+// PinGroupConversation 置顶群会话
+func PinGroupConversation(c *gin.Context) {
+	updateGroupConversation(c, service.PinGroupConversation, true, "置顶成功")
+}
+
+// UnpinGroupConversation 取消置顶群会话
+func UnpinGroupConversation(c *gin.Context) {
+	updateGroupConversation(c, service.PinGroupConversation, false, "取消置顶成功")
+}
+
+// MuteGroupConversation 静音群会话
+func MuteGroupConversation(c *gin.Context) {
+	updateGroupConversation(c, service.MuteGroupConversation, true, "静音成功")
+}
+
+// UnmuteGroupConversation 取消静音群会话
+func UnmuteGroupConversation(c *gin.Context) {
+	updateGroupConversation(c, service.MuteGroupConversation, false, "取消静音成功")
+}
+
+func updateGroupConversation(c *gin.Context, update func(string, int64, bool) error, value bool, successMsg string) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetString("userID")
+	if err := update(userID, groupID, value); err != nil {
+		respondGroupError(c, err, "操作失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": successMsg,
+		"data":    nil,
+	})
+}
+
+// parseGroupID 解析路径中的群ID，失败时直接返回400
+func parseGroupID(c *gin.Context) (int64, bool) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || groupID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    http.StatusBadRequest,
+			"message": "无效的群ID",
+			"data":    nil,
+		})
+		return 0, false
+	}
+	return groupID, true
+}
+
+// groupErrorStatus 群聊业务错误对应的HTTP状态码
+func groupErrorStatus(err error) (int, bool) {
+	switch err {
+	case service.ErrGroupNotFound:
+		return http.StatusNotFound, true
+	case service.ErrNotGroupMember, service.ErrGroupPermissionDenied:
+		return http.StatusForbidden, true
+	case service.ErrGroupMemberLimit, service.ErrGroupInviteNotFriend:
+		return http.StatusBadRequest, true
+	}
+	return 0, false
+}
+
+// respondGroupError 输出群聊接口错误
+func respondGroupError(c *gin.Context, err error, prefix string) {
+	if status, ok := groupErrorStatus(err); ok {
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    http.StatusInternalServerError,
+		"message": prefix + ": " + err.Error(),
+		"data":    nil,
+	})
+}
@@ -10,15 +10,16 @@ import (
 // GetHomePage 获取主页内容（包含公开帖子和好友帖子）
 func GetHomePage(c *gin.Context) {
 	userID := c.GetString("userID")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	opts := parsePageOptions(c, 20)
+	opts.Sort = parsePostSort(c)
+	// 首页统计总数代价较高，默认不统计
+	if c.Query("withTotal") == "" {
+		opts.WithTotal = false
+	}
 
-	posts, total, err := service.GetHomePagePosts(userID, page, pageSize)
+	posts, pageInfo, err := service.GetHomePagePosts(userID, opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": http.StatusInternalServerError,
-			"message":  "获取失败: " + err.Error(),
-		})
+		respondPageError(c, err)
 		return
 	}
 
@@ -33,6 +34,18 @@ func GetHomePage(c *gin.Context) {
 			"video":     post.Video,
 			"tags":      post.Tags,
 			"createdAt": post.CreatedAt,
+			"editedAt":  post.EditedAt,
+			"shareCount": post.ShareCount,
+			"anonymous": post.Anonymous,
+			"repostOf":  post.RepostOf,
+			"poll":      post.Poll,
+			"isBookmarked": post.IsBookmarked,
+			"commentPolicy": post.CommentPolicy,
+			"reactions": post.Reactions,
+			"myReaction": post.MyReaction,
+			"isLiked": post.IsLiked,
+			"likedByFriends": post.LikedByFriends,
+			"friendLikeCount": post.FriendLikeCount,
 			"likeCount": post.LikeCount,
 			"commentCount": post.CommentCount,
 			"viewCount": post.ViewCount,
@@ -54,12 +67,9 @@ func GetHomePage(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"message":  "获取成功",
-		"data": gin.H{
+		"data": setPageInfo(gin.H{
 			"posts": convertedPosts,
-			"total": total,
-			"page":  page,
-			"pageSize": pageSize,
-		},
+		}, opts, pageInfo),
 	})
 }
 
@@ -89,6 +99,18 @@ func GetPostListEnhanced(c *gin.Context) {
 			"images":       post.Images,
 			"video":        post.Video,
 			"createdAt":    post.CreatedAt,
+			"editedAt":     post.EditedAt,
+			"shareCount":   post.ShareCount,
+			"anonymous":    post.Anonymous,
+			"repostOf":     post.RepostOf,
+			"poll":         post.Poll,
+			"isBookmarked": post.IsBookmarked,
+			"commentPolicy": post.CommentPolicy,
+			"reactions": post.Reactions,
+			"myReaction": post.MyReaction,
+			"isLiked": post.IsLiked,
+			"likedByFriends": post.LikedByFriends,
+			"friendLikeCount": post.FriendLikeCount,
 			"likeCount":    post.LikeCount,
 			"commentCount": post.CommentCount,
 			"viewCount":    post.ViewCount,
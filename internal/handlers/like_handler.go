@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 // LikePost 点赞/取消点赞帖子
@@ -22,6 +23,14 @@ func LikePost(c *gin.Context) {
 	userID := c.GetString("userID")
 	liked, err := service.ToggleLikePost(postID, userID)
 	if err != nil {
+		if err == service.ErrUserBlocked {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    http.StatusForbidden,
+				"message": err.Error(),
+				"data":    nil,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    http.StatusInternalServerError,
 			"message": "操作失败: " + err.Error(),
@@ -46,7 +55,7 @@ func LikePost(c *gin.Context) {
 	})
 }
 
-// GetPostLikes 获取帖子点赞列表
+// GetPostLikes 获取帖子点赞（表情回应）列表，reaction 参数只返回某个表情的回应，friends=1 只返回好友的点赞
 func GetPostLikes(c *gin.Context) {
 	postID, err := strconv.ParseInt(c.Param("postId"), 10, 64)
 	if err != nil {
@@ -58,32 +67,29 @@ func GetPostLikes(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	opts := parsePageOptions(c, 20)
+
+	friendsOf := ""
+	if c.Query("friends") == "1" {
+		friendsOf = c.GetString("userID")
+	}
 
-	likes, total, err := service.GetPostLikes(postID, page, pageSize)
+	likes, pageInfo, err := service.GetPostLikes(postID, c.Query("reaction"), friendsOf, opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    http.StatusInternalServerError,
-			"message": "获取失败: " + err.Error(),
-			"data":    nil,
-		})
+		respondPageError(c, err)
 		return
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"message":  "获取成功",
-		"data": gin.H{
+		"data": setPageInfo(gin.H{
 			"likes": likes,
-			"total": total,
-			"page":  page,
-			"pageSize": pageSize,
-		},
+		}, opts, pageInfo),
 	})
 }
 
-// GetCommentLikes 获取评论点赞列表
+// GetCommentLikes 获取评论点赞（表情回应）列表，reaction 参数只返回某个表情的回应
 func GetCommentLikes(c *gin.Context) {
 	commentID, err := strconv.ParseInt(c.Param("commentId"), 10, 64)
 	if err != nil {
@@ -95,27 +101,73 @@ func GetCommentLikes(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	opts := parsePageOptions(c, 20)
 
-	likes, total, err := service.GetCommentLikes(commentID, page, pageSize)
+	likes, pageInfo, err := service.GetCommentLikes(commentID, c.Query("reaction"), opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    http.StatusInternalServerError,
-			"message": "获取失败: " + err.Error(),
-			"data":    nil,
-		})
+		respondPageError(c, err)
 		return
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"message":  "获取成功",
-		"data": gin.H{
+		"data": setPageInfo(gin.H{
 			"likes": likes,
-			"total": total,
-			"page":  page,
-			"pageSize": pageSize,
+		}, opts, pageInfo),
+	})
+}
+
+// GetLikeStatus 批量获取当前用户对一页帖子或评论的点赞状态，ids 为逗号分隔的目标ID
+func GetLikeStatus(c *gin.Context) {
+	targetType, err := strconv.Atoi(c.DefaultQuery("type", "1"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    http.StatusBadRequest,
+			"message": "无效的目标类型",
+			"data":    nil,
+		})
+		return
+	}
+
+	var targetIDs []int64
+	for _, raw := range strings.Split(c.Query("ids"), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    http.StatusBadRequest,
+				"message": "无效的目标ID: " + raw,
+				"data":    nil,
+			})
+			return
+		}
+		targetIDs = append(targetIDs, id)
+	}
+
+	statuses, err := service.GetLikeStatus(c.GetString("userID"), targetType, targetIDs)
+	if err != nil {
+		status := http.StatusInternalServerError
+		message := "获取失败: " + err.Error()
+		if err == service.ErrInvalidLikeTarget || err == service.ErrTooManyLikeTargets {
+			status, message = http.StatusBadRequest, err.Error()
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": message,
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "获取成功",
+		"data": gin.H{
+			"statuses": statuses,
 		},
 	})
 }
@@ -128,16 +180,11 @@ func GetUserLikes(c *gin.Context) {
 	}
 
 	targetType := c.DefaultQuery("type", "1") // 默认获取帖子点赞
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	opts := parsePageOptions(c, 20)
 
-	likes, total, err := service.GetUserLikes(targetUserID, targetType, page, pageSize)
+	likes, pageInfo, err := service.GetUserLikes(c.GetString("userID"), targetUserID, targetType, opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    http.StatusInternalServerError,
-			"message": "获取失败: " + err.Error(),
-			"data":    nil,
-		})
+		respondPageError(c, err)
 		return
 	}
 
@@ -152,7 +199,7 @@ func GetUserLikes(c *gin.Context) {
 		}
 
 		// 添加用户信息,统一使用avatarUrl
-		if like.User.ID != "" {
+		if like.User != nil {
 			likeData["user"] = map[string]interface{}{
 				"id":              like.User.ID,
 				"username":        like.User.Username,
@@ -163,8 +210,11 @@ func GetUserLikes(c *gin.Context) {
 			}
 		}
 
-		// 如果是帖子点赞,添加帖子信息
-		if like.TargetType == 1 && like.Post.ID != 0 {
+		// 如果是帖子点赞,添加帖子信息，已删除或无权查看的帖子只返回墓碑
+		if like.Tombstone != "" {
+			likeData["tombstone"] = like.Tombstone
+		}
+		if like.TargetType == 1 && like.Post != nil {
 			likeData["post"] = map[string]interface{}{
 				"id":          like.Post.ID,
 				"title":       like.Post.Title,
@@ -185,11 +235,8 @@ func GetUserLikes(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"message":  "获取成功",
-		"data": gin.H{
-			"likes":    convertedLikes,
-			"total":    total,
-			"page":     page,
-			"pageSize": pageSize,
-		},
+		"data": setPageInfo(gin.H{
+			"likes": convertedLikes,
+		}, opts, pageInfo),
 	})
 }
\ No newline at end of file
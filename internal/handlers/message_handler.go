@@ -3,6 +3,7 @@ package handlers
 import (
 	"github.com/Yw332/campus-moments-go/internal/service"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"net/http"
 	"strconv"
 )
@@ -10,13 +11,15 @@ import (
 // SendMessage 发送消息
 func SendMessage(c *gin.Context) {
 	var req struct {
-		ReceiverID     string `json:"receiverId" binding:"required"`
+		ReceiverID     string `json:"receiverId"` // 单聊接收者
+		GroupID        int64  `json:"groupId"`    // 群聊ID，与 receiverId 二选一
 		MsgType        int    `json:"msgType" binding:"required,oneof=1 2 3 4"` // 1-文本 2-图片 3-视频 4-文件
 		ContentPreview string `json:"contentPreview"`
 		FileURL        string `json:"fileUrl"`
 		FileSize       int    `json:"fileSize"`
 		IsEncrypted    bool   `json:"isEncrypted"`
 		DeviceID       string `json:"deviceId"`
+		ClientMsgID    string `json:"clientMsgId" binding:"max=64"` // 客户端生成的消息ID，重试时保持不变
 		ServerMsgID    string `json:"serverMsgId"`
 	}
 	
@@ -25,11 +28,28 @@ func SendMessage(c *gin.Context) {
 		return
 	}
 	
+	if (req.ReceiverID == "") == (req.GroupID == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误: receiverId 和 groupId 必须且只能指定一个"})
+		return
+	}
+	
 	senderID := c.GetString("userID")
-	message, err := service.SendMessage(senderID, req.ReceiverID, req.MsgType, 
+	message, err := service.SendMessage(senderID, req.ReceiverID, req.GroupID, req.MsgType, 
 		req.ContentPreview, req.FileURL, req.FileSize, req.IsEncrypted, 
-		req.DeviceID, req.ServerMsgID)
+		req.DeviceID, req.ClientMsgID, req.ServerMsgID)
 	if err != nil {
+		if status, ok := groupErrorStatus(err); ok {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if err == service.ErrUserBlocked {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if err == service.ErrClientMsgIDConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "发送失败: " + err.Error()})
 		return
 	}
@@ -72,6 +92,124 @@ func GetMessageList(c *gin.Context) {
 	})
 }
 
+// SyncMessages 多端同步：获取 since 之后的消息、已读、撤回、编辑及会话变化事件
+func SyncMessages(c *gin.Context) {
+	since, err := strconv.ParseInt(c.DefaultQuery("since", "0"), 10, 64)
+	if err != nil || since < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的同步序号"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "200"))
+	
+	userID := c.GetString("userID")
+	events, nextSince, hasMore, err := service.GetSyncEvents(userID, since, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "同步失败: " + err.Error()})
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"message":  "同步成功",
+		"data": gin.H{
+			"events":    events,
+			"nextSince": nextSince,
+			"hasMore":   hasMore,
+			"latestSeq": service.GetCurrentSyncSeq(userID),
+		},
+	})
+}
+
+// RecallMessage 撤回消息
+func RecallMessage(c *gin.Context) {
+	messageID, err := strconv.ParseInt(c.Param("msgId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的消息ID"})
+		return
+	}
+	
+	userID := c.GetString("userID")
+	message, err := service.RecallMessage(userID, messageID)
+	if err != nil {
+		respondMessageEditError(c, err, "撤回失败")
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"message":  "撤回成功",
+		"data": message,
+	})
+}
+
+// EditMessage 编辑消息
+func EditMessage(c *gin.Context) {
+	messageID, err := strconv.ParseInt(c.Param("msgId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的消息ID"})
+		return
+	}
+	
+	var req struct {
+		ContentPreview string `json:"contentPreview" binding:"required,max=255"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误: " + err.Error()})
+		return
+	}
+	
+	userID := c.GetString("userID")
+	message, err := service.EditMessage(userID, messageID, req.ContentPreview)
+	if err != nil {
+		respondMessageEditError(c, err, "编辑失败")
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"message":  "编辑成功",
+		"data": message,
+	})
+}
+
+// GetMessageEditHistory 获取消息编辑历史
+func GetMessageEditHistory(c *gin.Context) {
+	messageID, err := strconv.ParseInt(c.Param("msgId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的消息ID"})
+		return
+	}
+	
+	userID := c.GetString("userID")
+	edits, err := service.GetMessageEditHistory(userID, messageID)
+	if err != nil {
+		respondMessageEditError(c, err, "获取失败")
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"message":  "获取成功",
+		"data": gin.H{
+			"edits": edits,
+		},
+	})
+}
+
+// respondMessageEditError 输出撤回/编辑接口的错误
+func respondMessageEditError(c *gin.Context, err error, prefix string) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "消息不存在"})
+	case service.ErrRecallWindowExpired, service.ErrEditWindowExpired:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case service.ErrMessageRecalled, service.ErrMessageNotEditable, service.ErrMessageContentMissing:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": prefix + ": " + err.Error()})
+	}
+}
+
 // GetConversationList 获取会话列表
 func GetConversationList(c *gin.Context) {
 	userID := c.GetString("userID")
@@ -54,8 +54,7 @@ func CreateMoment(c *gin.Context) {
 
 // GetMoments 获取动态列表（支持分页）
 func GetMoments(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	opts := parsePageOptions(c, 10)
 	
 	// 支持按用户ID筛选
 	var userID *string
@@ -63,18 +62,18 @@ func GetMoments(c *gin.Context) {
 		userID = &uidStr
 	}
 
-	list, total, err := momentService.ListMoments(page, pageSize, userID)
+	list, pageInfo, err := momentService.ListMoments(opts, userID)
+	if err == service.ErrInvalidCursor {
+		respondPageError(c, err)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
 			"code":    200,
 			"message": "success",
 			"data": gin.H{
 				"list": []gin.H{},
-				"pagination": gin.H{
-					"page":     page,
-					"pageSize": pageSize,
-					"total":    0,
-				},
+				"pagination": setPageInfo(gin.H{}, opts, service.PageInfo{}),
 			},
 		})
 		return
@@ -118,11 +117,7 @@ func GetMoments(c *gin.Context) {
 		"message": "success",
 		"data": gin.H{
 			"list": convertedList,
-			"pagination": gin.H{
-				"page":     page,
-				"pageSize": pageSize,
-				"total":    total,
-			},
+			"pagination": setPageInfo(gin.H{}, opts, pageInfo),
 		},
 	})
 }
@@ -170,7 +165,6 @@ func GetMomentDetail(c *gin.Context) {
 		data["visibility"] = moment.Visibility
 		data["status"] = moment.Status
 		data["tags"] = moment.Tags
-		data["likedUsers"] = moment.LikedUsers
 		data["commentsSummary"] = moment.CommentsSummary
 		data["likeCount"] = moment.LikeCount
 		data["commentCount"] = moment.CommentCount
@@ -230,6 +224,8 @@ func UpdateMoment(c *gin.Context) {
 		
 		if err.Error() == "动态不存在或无权限修改" {
 			statusCode = http.StatusNotFound
+		} else if err == service.ErrRepostVisibility {
+			statusCode = http.StatusBadRequest
 		}
 		
 		c.JSON(statusCode, gin.H{
@@ -346,22 +342,21 @@ func GetUserMoments(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	opts := parsePageOptions(c, 10)
 
 	uid := userID.(string)
-	list, total, err := momentService.GetUserMoments(uid, page, pageSize)
+	list, pageInfo, err := momentService.GetUserMoments(uid, opts)
+	if err == service.ErrInvalidCursor {
+		respondPageError(c, err)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
 			"code":    200,
 			"message": "success",
 			"data": gin.H{
 				"list": []gin.H{},
-				"pagination": gin.H{
-					"page":     page,
-					"pageSize": pageSize,
-					"total":    0,
-				},
+				"pagination": setPageInfo(gin.H{}, opts, service.PageInfo{}),
 			},
 		})
 		return
@@ -372,11 +367,7 @@ func GetUserMoments(c *gin.Context) {
 		"message": "success",
 		"data": gin.H{
 			"list": list,
-			"pagination": gin.H{
-				"page":     page,
-				"pageSize": pageSize,
-				"total":    total,
-			},
+			"pagination": setPageInfo(gin.H{}, opts, pageInfo),
 		},
 	})
 }
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Yw332/campus-moments-go/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetNotifications 获取通知列表（游标分页）
+func GetNotifications(c *gin.Context) {
+	userID := c.GetString("userID")
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	cursor := c.Query("cursor")
+
+	notifType := 0
+	if typeName := c.Query("type"); typeName != "" {
+		if notifType = service.ParseNotificationType(typeName); notifType == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    http.StatusBadRequest,
+				"message": "无效的通知类型",
+				"data":    nil,
+			})
+			return
+		}
+	}
+
+	items, nextCursor, hasMore, err := service.GetNotificationList(userID, notifType, cursor, limit)
+	if err != nil {
+		if err == service.ErrInvalidCursor {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    http.StatusBadRequest,
+				"message": err.Error(),
+				"data":    nil,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    http.StatusInternalServerError,
+			"message": "获取失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "获取成功",
+		"data": gin.H{
+			"notifications": items,
+			"nextCursor":    nextCursor,
+			"hasMore":       hasMore,
+		},
+	})
+}
+
+// MarkNotificationRead 标记单条通知为已读
+func MarkNotificationRead(c *gin.Context) {
+	notificationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    http.StatusBadRequest,
+			"message": "无效的通知ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	userID := c.GetString("userID")
+	if err := service.MarkNotificationRead(userID, notificationID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    http.StatusNotFound,
+				"message": "通知不存在",
+				"data":    nil,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    http.StatusInternalServerError,
+			"message": "标记失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "标记成功",
+		"data":    nil,
+	})
+}
+
+// MarkAllNotificationsRead 标记全部通知为已读（可按类型）
+func MarkAllNotificationsRead(c *gin.Context) {
+	notifType := 0
+	if typeName := c.Query("type"); typeName != "" {
+		if notifType = service.ParseNotificationType(typeName); notifType == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    http.StatusBadRequest,
+				"message": "无效的通知类型",
+				"data":    nil,
+			})
+			return
+		}
+	}
+
+	userID := c.GetString("userID")
+	count, err := service.MarkAllNotificationsRead(userID, notifType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    http.StatusInternalServerError,
+			"message": "标记失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "标记成功",
+		"data": gin.H{
+			"count": count,
+		},
+	})
+}
+
+// GetNotificationUnreadCount 获取未读通知数（按类型统计）
+func GetNotificationUnreadCount(c *gin.Context) {
+	userID := c.GetString("userID")
+	total, byType, err := service.GetNotificationUnreadCount(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    http.StatusInternalServerError,
+			"message": "获取失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    http.StatusOK,
+		"message": "获取成功",
+		"data": gin.H{
+			"total":  total,
+			"byType": byType,
+		},
+	})
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// VotePoll 投票
+func VotePoll(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的帖子ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var req struct {
+		OptionIDs []int64 `json:"optionIds" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	poll, err := service.VotePoll(c.GetString("userID"), postID, req.OptionIDs)
+	if err != nil {
+		respondPollError(c, "投票失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "投票成功",
+		"data":    poll,
+	})
+}
+
+// UnvotePoll 撤销投票
+func UnvotePoll(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的帖子ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	poll, err := service.UnvotePoll(c.GetString("userID"), postID)
+	if err != nil {
+		respondPollError(c, "撤销失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已撤销投票",
+		"data":    poll,
+	})
+}
+
+// GetPollVoters 获取公开投票的投票人
+func GetPollVoters(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的帖子ID",
+			"data":    nil,
+		})
+		return
+	}
+	optionID, _ := strconv.ParseInt(c.Query("optionId"), 10, 64)
+	opts := parsePageOptions(c, 20)
+
+	votes, pageInfo, err := service.GetPollVoters(c.GetString("userID"), postID, optionID, opts)
+	if err == service.ErrInvalidCursor {
+		respondPageError(c, err)
+		return
+	}
+	if err != nil {
+		respondPollError(c, "获取失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": setPageInfo(gin.H{
+			"voters": pollVoters(votes),
+		}, opts, pageInfo),
+	})
+}
+
+// pollVoters 转换投票人列表的响应格式
+func pollVoters(votes []models.PollVote) []gin.H {
+	voters := make([]gin.H, 0, len(votes))
+	for _, v := range votes {
+		if v.User == nil {
+			continue
+		}
+		voters = append(voters, gin.H{
+			"userId":    v.User.ID,
+			"username":  v.User.Username,
+			"avatarUrl": v.User.AvatarURL,
+			"optionId":  v.OptionID,
+			"votedAt":   v.CreatedAt,
+		})
+	}
+	return voters
+}
+
+// respondPollError 返回投票相关的错误
+func respondPollError(c *gin.Context, prefix string, err error) {
+	status := http.StatusInternalServerError
+	message := prefix + err.Error()
+	switch {
+	case err == service.ErrInvalidPollChoice:
+		status, message = http.StatusBadRequest, err.Error()
+	case err == service.ErrAlreadyVoted, err == service.ErrNotVoted, err == service.ErrPollClosed:
+		status, message = http.StatusConflict, err.Error()
+	case err == service.ErrPollVotersHidden, err == service.ErrPollResultsHidden:
+		status, message = http.StatusForbidden, err.Error()
+	case err == service.ErrPollNotFound:
+		status, message = http.StatusNotFound, err.Error()
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status, message = http.StatusNotFound, "帖子不存在"
+	}
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": message,
+		"data":    nil,
+	})
+}
@@ -5,17 +5,21 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 // CreatePost 创建帖子
 func CreatePost(c *gin.Context) {
 	var req struct {
-		Title      string   `json:"title" binding:"max=100"`
-		Content    string   `json:"content" binding:"required,min=1,max=10000"`
-		Images     []string `json:"images"`
-		Video      string   `json:"video"`
-		Visibility int      `json:"visibility" binding:"oneof=0 1 2"`
-		Tags       []string `json:"tags"`
+		Title      string             `json:"title" binding:"max=100"`
+		Content    string             `json:"content" binding:"required,min=1,max=10000"`
+		Images     []string           `json:"images"`
+		Video      string             `json:"video"`
+		Visibility int                `json:"visibility" binding:"oneof=0 1 2"`
+		Tags       []string           `json:"tags"`
+		Draft      bool               `json:"draft"`     // 保存为草稿
+		PublishAt  *time.Time         `json:"publishAt"` // 定时发布时间，不为空时保存为定时帖子
+		Poll       *service.PollInput `json:"poll"`      // 附带的投票
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -28,7 +32,16 @@ func CreatePost(c *gin.Context) {
 	}
 
 	userID := c.GetString("userID")
-	post, err := service.CreatePost(userID, req.Title, req.Content, req.Images, req.Video, req.Visibility, req.Tags)
+	post, err := service.CreatePost(userID, req.Title, req.Content, req.Images, req.Video, req.Visibility, req.Tags, req.Draft, req.PublishAt, req.Poll)
+	switch err {
+	case service.ErrPublishAtInPast, service.ErrPollOptionCount, service.ErrPollOptionInvalid, service.ErrPollDeadlineInvalid:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -47,19 +60,15 @@ func CreatePost(c *gin.Context) {
 
 // GetPostList 获取帖子列表
 func GetPostList(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	opts := parsePageOptions(c, 20)
+	opts.Sort = parsePostSort(c)
 	visibility := c.DefaultQuery("visibility", "0")
 
 	userID := c.GetString("userID")
 
-	posts, total, err := service.GetPostList(userID, visibility, page, pageSize)
+	posts, pageInfo, err := service.GetPostList(userID, visibility, opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "获取失败: " + err.Error(),
-			"data":    nil,
-		})
+		respondPageError(c, err)
 		return
 	}
 
@@ -74,6 +83,18 @@ func GetPostList(c *gin.Context) {
 			"video":     post.Video,
 			"tags":      post.Tags,
 			"createdAt": post.CreatedAt,
+			"editedAt":  post.EditedAt,
+			"shareCount": post.ShareCount,
+			"anonymous": post.Anonymous,
+			"repostOf":  post.RepostOf,
+			"poll":      post.Poll,
+			"isBookmarked": post.IsBookmarked,
+			"commentPolicy": post.CommentPolicy,
+			"reactions": post.Reactions,
+			"myReaction": post.MyReaction,
+			"isLiked": post.IsLiked,
+			"likedByFriends": post.LikedByFriends,
+			"friendLikeCount": post.FriendLikeCount,
 		}
 
 		// 添加作者信息
@@ -97,12 +118,9 @@ func GetPostList(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "获取成功",
-		"data": gin.H{
-			"posts":    convertedPosts,
-			"total":    total,
-			"page":     page,
-			"pageSize": pageSize,
-		},
+		"data": setPageInfo(gin.H{
+			"posts": convertedPosts,
+		}, opts, pageInfo),
 	})
 }
 
@@ -141,6 +159,18 @@ func GetPostDetail(c *gin.Context) {
 		"video":     post.Video,
 		"tags":      post.Tags,
 		"createdAt": post.CreatedAt,
+		"editedAt":  post.EditedAt,
+		"shareCount": post.ShareCount,
+		"anonymous": post.Anonymous,
+		"repostOf":  post.RepostOf,
+		"poll":      post.Poll,
+		"isBookmarked": post.IsBookmarked,
+		"commentPolicy": post.CommentPolicy,
+		"reactions": post.Reactions,
+		"myReaction": post.MyReaction,
+		"isLiked": post.IsLiked,
+		"likedByFriends": post.LikedByFriends,
+		"friendLikeCount": post.FriendLikeCount,
 	}
 
 	// 添加作者信息
@@ -197,6 +227,14 @@ func UpdatePost(c *gin.Context) {
 
 	userID := c.GetString("userID")
 	post, err := service.UpdatePost(postID, userID, req.Title, req.Content, req.Images, req.Video, req.Visibility, req.Tags)
+	if err == service.ErrRepostVisibility {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusForbidden, gin.H{
 			"code":    403,
@@ -275,6 +313,18 @@ func GetUserPosts(c *gin.Context) {
 			"video":     post.Video,
 			"tags":      post.Tags,
 			"createdAt": post.CreatedAt,
+			"editedAt":  post.EditedAt,
+			"shareCount": post.ShareCount,
+			"anonymous": post.Anonymous,
+			"repostOf":  post.RepostOf,
+			"poll":      post.Poll,
+			"isBookmarked": post.IsBookmarked,
+			"commentPolicy": post.CommentPolicy,
+			"reactions": post.Reactions,
+			"myReaction": post.MyReaction,
+			"isLiked": post.IsLiked,
+			"likedByFriends": post.LikedByFriends,
+			"friendLikeCount": post.FriendLikeCount,
 		}
 
 		// 添加作者信息
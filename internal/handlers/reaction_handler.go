@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetReactionOptions 获取可用的表情回应
+func GetReactionOptions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    gin.H{"reactions": service.ReactionEmojis()},
+	})
+}
+
+// SetPostReaction 对帖子做出表情回应
+func SetPostReaction(c *gin.Context) {
+	setReaction(c, models.ReactionTargetPost, "id")
+}
+
+// RemovePostReaction 取消对帖子的表情回应
+func RemovePostReaction(c *gin.Context) {
+	removeReaction(c, models.ReactionTargetPost, "id")
+}
+
+// SetCommentReaction 对评论做出表情回应
+func SetCommentReaction(c *gin.Context) {
+	setReaction(c, models.ReactionTargetComment, "id")
+}
+
+// RemoveCommentReaction 取消对评论的表情回应
+func RemoveCommentReaction(c *gin.Context) {
+	removeReaction(c, models.ReactionTargetComment, "id")
+}
+
+// SetMessageReaction 对消息做出表情回应
+func SetMessageReaction(c *gin.Context) {
+	setReaction(c, models.ReactionTargetMessage, "msgId")
+}
+
+// RemoveMessageReaction 取消对消息的表情回应
+func RemoveMessageReaction(c *gin.Context) {
+	removeReaction(c, models.ReactionTargetMessage, "msgId")
+}
+
+// setReaction 做出表情回应，已有回应时替换
+func setReaction(c *gin.Context, targetType int, param string) {
+	targetID, ok := parseReactionTargetID(c, param)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Emoji string `json:"emoji" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	summary, err := service.SetReaction(c.GetString("userID"), targetType, targetID, req.Emoji)
+	if err != nil {
+		respondReactionError(c, "操作失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "回应成功",
+		"data":    summary,
+	})
+}
+
+// removeReaction 取消表情回应
+func removeReaction(c *gin.Context, targetType int, param string) {
+	targetID, ok := parseReactionTargetID(c, param)
+	if !ok {
+		return
+	}
+
+	summary, err := service.RemoveReaction(c.GetString("userID"), targetType, targetID)
+	if err != nil {
+		respondReactionError(c, "操作失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已取消回应",
+		"data":    summary,
+	})
+}
+
+// parseReactionTargetID 解析路径中的目标ID，无效时直接返回 400
+func parseReactionTargetID(c *gin.Context, param string) (int64, bool) {
+	targetID, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的ID",
+			"data":    nil,
+		})
+		return 0, false
+	}
+	return targetID, true
+}
+
+// respondReactionError 返回表情回应相关的错误
+func respondReactionError(c *gin.Context, prefix string, err error) {
+	status := http.StatusInternalServerError
+	message := prefix + err.Error()
+	switch {
+	case err == service.ErrInvalidReaction, err == service.ErrMessageRecalled:
+		status, message = http.StatusBadRequest, err.Error()
+	case err == service.ErrUserBlocked:
+		status, message = http.StatusForbidden, err.Error()
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status, message = http.StatusNotFound, "内容不存在或无权查看"
+	}
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": message,
+		"data":    nil,
+	})
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Yw332/campus-moments-go/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RepostPost 转发帖子
+func RepostPost(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的帖子ID",
+			"data":    nil,
+		})
+		return
+	}
+
+	var req struct {
+		Content    string `json:"content" binding:"max=10000"` // 转发评论，可为空
+		Visibility int    `json:"visibility" binding:"oneof=0 1 2"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	post, err := service.CreateRepost(c.GetString("userID"), postID, req.Content, req.Visibility)
+	if err != nil {
+		status := http.StatusInternalServerError
+		message := "转发失败: " + err.Error()
+		switch {
+		case err == service.ErrRepostNotAllowed, err == service.ErrRepostVisibility:
+			status, message = http.StatusBadRequest, err.Error()
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			status, message = http.StatusNotFound, "帖子不存在"
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": message,
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    200,
+		"message": "转发成功",
+		"data":    post,
+	})
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetPostRevisions 获取我的帖子的编辑历史
+func GetPostRevisions(c *gin.Context) {
+	postID, ok := parseRevisionPostID(c)
+	if !ok {
+		return
+	}
+
+	post, revisions, err := service.GetPostRevisions(postID, c.GetString("userID"))
+	respondPostRevisions(c, post, revisions, err)
+}
+
+// AdminGetPostRevisions 管理员获取帖子的编辑历史
+func AdminGetPostRevisions(c *gin.Context) {
+	postID, ok := parseRevisionPostID(c)
+	if !ok {
+		return
+	}
+
+	post, revisions, err := service.AdminGetPostRevisions(postID)
+	respondPostRevisions(c, post, revisions, err)
+}
+
+// GetPostRevisionDiff 获取我的帖子某次编辑的差异
+func GetPostRevisionDiff(c *gin.Context) {
+	postID, ok := parseRevisionPostID(c)
+	if !ok {
+		return
+	}
+	version, ok := parseRevisionVersion(c)
+	if !ok {
+		return
+	}
+
+	diff, err := service.GetPostRevisionDiff(postID, c.GetString("userID"), version)
+	respondPostRevisionDiff(c, diff, err)
+}
+
+// AdminGetPostRevisionDiff 管理员获取帖子某次编辑的差异
+func AdminGetPostRevisionDiff(c *gin.Context) {
+	postID, ok := parseRevisionPostID(c)
+	if !ok {
+		return
+	}
+	version, ok := parseRevisionVersion(c)
+	if !ok {
+		return
+	}
+
+	diff, err := service.AdminGetPostRevisionDiff(postID, version)
+	respondPostRevisionDiff(c, diff, err)
+}
+
+// parseRevisionPostID 解析路径中的帖子ID，无效时直接返回 400
+func parseRevisionPostID(c *gin.Context) (int64, bool) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的帖子ID",
+			"data":    nil,
+		})
+		return 0, false
+	}
+	return postID, true
+}
+
+// parseRevisionVersion 解析路径中的版本号，无效时直接返回 400
+func parseRevisionVersion(c *gin.Context) (int, bool) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的版本号",
+			"data":    nil,
+		})
+		return 0, false
+	}
+	return version, true
+}
+
+// respondPostRevisions 返回编辑历史，revisions 为历史版本，current 为当前版本
+func respondPostRevisions(c *gin.Context, post *models.Post, revisions []models.PostRevision, err error) {
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	current := gin.H{
+		"version":    len(revisions) + 1,
+		"title":      post.Title,
+		"content":    post.Content,
+		"images":     post.Images,
+		"video":      post.Video,
+		"tags":       post.Tags,
+		"visibility": post.Visibility,
+		"status":     post.Status,
+		"createdAt":  post.CreatedAt,
+		"editedAt":   post.EditedAt,
+	}
+	if post.User != nil {
+		current["author"] = gin.H{
+			"userId":    post.User.ID,
+			"username":  post.User.Username,
+			"avatarUrl": post.User.AvatarURL,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"postId":    post.ID,
+			"current":   current,
+			"revisions": revisions,
+		},
+	})
+}
+
+// respondPostRevisionDiff 返回某次编辑的差异
+func respondPostRevisionDiff(c *gin.Context, diff *service.PostRevisionDiff, err error) {
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    diff,
+	})
+}
+
+// respondRevisionError 帖子或版本不存在返回 404，其余返回 500
+func respondRevisionError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	message := "获取失败: " + err.Error()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		status, message = http.StatusNotFound, "帖子或版本不存在"
+	}
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": message,
+		"data":    nil,
+	})
+}
@@ -10,7 +10,7 @@ import (
 
 var searchService = service.NewSearchService()
 
-// SearchContent 搜索内容
+// SearchContent 全文搜索帖子、用户和标签，type 为 all（默认）、posts、users 或 tags，各类结果单独分页
 func SearchContent(c *gin.Context) {
 	keyword := c.Query("keyword")
 	if keyword == "" {
@@ -26,7 +26,15 @@ func SearchContent(c *gin.Context) {
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
 
 	// 执行搜索
-	results, err := searchService.SearchContent(keyword, page, pageSize)
+	results, err := searchService.SearchContent(c.GetString("userID"), keyword, c.DefaultQuery("type", service.SearchTypeAll), page, pageSize)
+	if err == service.ErrInvalidSearchType {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+			"data":    nil,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    http.StatusInternalServerError,
@@ -47,10 +55,23 @@ func SearchContent(c *gin.Context) {
 			"video":     post.Video,
 			"tags":      post.Tags,
 			"createdAt": post.CreatedAt,
+			"editedAt":  post.EditedAt,
+			"shareCount": post.ShareCount,
+			"anonymous": post.Anonymous,
+			"repostOf":  post.RepostOf,
+			"poll":      post.Poll,
+			"isBookmarked": post.IsBookmarked,
+			"commentPolicy": post.CommentPolicy,
+			"reactions": post.Reactions,
+			"myReaction": post.MyReaction,
+			"isLiked": post.IsLiked,
+			"likedByFriends": post.LikedByFriends,
+			"friendLikeCount": post.FriendLikeCount,
 			"likeCount": post.LikeCount,
 			"commentCount": post.CommentCount,
 			"viewCount": post.ViewCount,
 			"visibility": post.Visibility,
+			"highlight": results.PostHighlights[post.ID],
 		}
 
 		// 添加作者信息
@@ -71,6 +92,7 @@ func SearchContent(c *gin.Context) {
 		"data": map[string]interface{}{
 			"posts":      convertedPosts,
 			"users":      results.Users,
+			"tags":       results.Tags,
 			"pagination": results.Pagination,
 		},
 	})
@@ -208,6 +230,18 @@ func GetFilteredContent(c *gin.Context) {
 			"video":     post.Video,
 			"tags":      post.Tags,
 			"createdAt": post.CreatedAt,
+			"editedAt":  post.EditedAt,
+			"shareCount": post.ShareCount,
+			"anonymous": post.Anonymous,
+			"repostOf":  post.RepostOf,
+			"poll":      post.Poll,
+			"isBookmarked": post.IsBookmarked,
+			"commentPolicy": post.CommentPolicy,
+			"reactions": post.Reactions,
+			"myReaction": post.MyReaction,
+			"isLiked": post.IsLiked,
+			"likedByFriends": post.LikedByFriends,
+			"friendLikeCount": post.FriendLikeCount,
 			"likeCount": post.LikeCount,
 			"commentCount": post.CommentCount,
 			"viewCount": post.ViewCount,
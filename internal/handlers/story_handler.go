@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// 快拍支持的媒体格式
+var storyMediaTypes = map[string]int{
+	".jpg":  models.StoryMediaImage,
+	".jpeg": models.StoryMediaImage,
+	".png":  models.StoryMediaImage,
+	".gif":  models.StoryMediaImage,
+	".webp": models.StoryMediaImage,
+	".mp4":  models.StoryMediaVideo,
+	".mov":  models.StoryMediaVideo,
+}
+
+const (
+	maxStoryImageSize = 10 * 1024 * 1024
+	maxStoryVideoSize = 50 * 1024 * 1024
+	maxStoryCaption   = 200
+)
+
+// CreateStory 发布快拍（multipart 表单：file 为图片或短视频，caption 为可选的文字）
+func CreateStory(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请选择图片或视频",
+			"data":    nil,
+		})
+		return
+	}
+
+	mediaType, ok := storyMediaTypes[strings.ToLower(filepath.Ext(file.Filename))]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "快拍只支持 JPG、PNG、GIF、WebP 图片和 MP4、MOV 视频",
+			"data":    nil,
+		})
+		return
+	}
+	if (mediaType == models.StoryMediaImage && file.Size > maxStoryImageSize) ||
+		(mediaType == models.StoryMediaVideo && file.Size > maxStoryVideoSize) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "图片不能超过10MB，视频不能超过50MB",
+			"data":    nil,
+		})
+		return
+	}
+
+	caption := strings.TrimSpace(c.PostForm("caption"))
+	if utf8.RuneCountInString(caption) > maxStoryCaption {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "文字不能超过200个字",
+			"data":    nil,
+		})
+		return
+	}
+
+	mediaPath, mediaURL, err := saveUpload(c, file, "stories")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "文件保存失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	story, err := service.CreateStory(c.GetString("userID"), mediaType, mediaURL, mediaPath, caption)
+	if err != nil {
+		os.Remove(filepath.Join("./uploads", mediaPath))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "发布失败: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    200,
+		"message": "发布成功",
+		"data":    story,
+	})
+}
+
+// GetStoryTray 获取快拍栏
+func GetStoryTray(c *gin.Context) {
+	mine, tray, err := service.GetStoryTray(c.GetString("userID"))
+	if err != nil {
+		respondStoryError(c, "获取失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"mine": storyTrayItem(mine),
+			"tray": storyTrayItems(tray),
+		},
+	})
+}
+
+// storyTrayItems 快拍栏列表，用户只返回公开信息
+func storyTrayItems(tray []service.StoryTrayItem) []gin.H {
+	items := make([]gin.H, 0, len(tray))
+	for i := range tray {
+		if item := storyTrayItem(&tray[i]); item != nil {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// storyTrayItem 快拍栏中的一位用户，没有快拍或用户不存在时返回 nil
+func storyTrayItem(item *service.StoryTrayItem) gin.H {
+	if item == nil || item.User == nil {
+		return nil
+	}
+	return gin.H{
+		"user": gin.H{
+			"userId":    item.User.ID,
+			"username":  item.User.Username,
+			"avatarUrl": item.User.AvatarURL,
+		},
+		"storyCount":    item.StoryCount,
+		"hasUnseen":     item.HasUnseen,
+		"latestAt":      item.LatestAt,
+		"firstUnseenId": item.FirstUnseen,
+	}
+}
+
+// GetUserStories 获取某个好友（或自己）的快拍
+func GetUserStories(c *gin.Context) {
+	stories, err := service.GetUserStories(c.GetString("userID"), c.Param("userId"))
+	if err != nil {
+		respondStoryError(c, "获取失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data":    gin.H{"stories": stories},
+	})
+}
+
+// ViewStory 标记快拍已看
+func ViewStory(c *gin.Context) {
+	storyID, ok := parseStoryID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.ViewStory(c.GetString("userID"), storyID); err != nil {
+		respondStoryError(c, "操作失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "已查看",
+		"data":    gin.H{"seen": true},
+	})
+}
+
+// GetStoryViewers 获取我的快拍的浏览者
+func GetStoryViewers(c *gin.Context) {
+	storyID, ok := parseStoryID(c)
+	if !ok {
+		return
+	}
+	opts := parsePageOptions(c, 20)
+
+	views, pageInfo, err := service.GetStoryViewers(c.GetString("userID"), storyID, opts)
+	if err == service.ErrInvalidCursor {
+		respondPageError(c, err)
+		return
+	}
+	if err != nil {
+		respondStoryError(c, "获取失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": setPageInfo(gin.H{
+			"viewers": storyViewers(views),
+		}, opts, pageInfo),
+	})
+}
+
+// storyViewers 快拍的浏览者，只返回公开信息
+func storyViewers(views []models.StoryView) []gin.H {
+	viewers := make([]gin.H, 0, len(views))
+	for _, v := range views {
+		if v.User == nil {
+			continue
+		}
+		viewers = append(viewers, gin.H{
+			"userId":    v.User.ID,
+			"username":  v.User.Username,
+			"avatarUrl": v.User.AvatarURL,
+			"viewedAt":  v.ViewedAt,
+		})
+	}
+	return viewers
+}
+
+// DeleteStory 删除我的快拍
+func DeleteStory(c *gin.Context) {
+	storyID, ok := parseStoryID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteStory(c.GetString("userID"), storyID); err != nil {
+		respondStoryError(c, "删除失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "删除成功",
+		"data":    nil,
+	})
+}
+
+// parseStoryID 解析路径中的快拍ID，无效时直接返回 400
+func parseStoryID(c *gin.Context) (int64, bool) {
+	storyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "无效的快拍ID",
+			"data":    nil,
+		})
+		return 0, false
+	}
+	return storyID, true
+}
+
+// respondStoryError 返回快拍相关的错误
+func respondStoryError(c *gin.Context, prefix string, err error) {
+	status := http.StatusInternalServerError
+	message := prefix + err.Error()
+	switch err {
+	case service.ErrStoryForbidden, service.ErrNotStoryOwner:
+		status, message = http.StatusForbidden, err.Error()
+	case service.ErrStoryNotFound:
+		status, message = http.StatusNotFound, err.Error()
+	}
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": message,
+		"data":    nil,
+	})
+}
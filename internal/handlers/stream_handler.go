@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/realtime"
+	"github.com/Yw332/campus-moments-go/internal/service"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// SSE 心跳间隔，避免代理因连接空闲而断开
+const streamHeartbeat = 25 * time.Second
+
+// Stream 建立 Server-Sent Events 推送流（供无法保持 WebSocket 的客户端使用）
+// 客户端重连时携带 Last-Event-ID 请求头（或 lastEventId 参数），服务端补发期间错过的事件
+func Stream(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	lastEventID, _ := strconv.ParseInt(c.GetHeader("Last-Event-ID"), 10, 64)
+	if lastEventID == 0 {
+		lastEventID, _ = strconv.ParseInt(c.Query("lastEventId"), 10, 64)
+	}
+
+	hub := realtime.GetHub()
+	sub, missed, complete := hub.Subscribe(userID, lastEventID)
+	defer hub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 关闭 Nginx 等代理的缓冲
+	c.Status(http.StatusOK)
+
+	// 建议客户端断线后的重连间隔
+	c.Writer.WriteString("retry: 3000\n\n")
+
+	if !complete {
+		writeStreamEvent(c, &realtime.Event{Type: realtime.EventResync, Time: time.Now()})
+	}
+	for _, event := range missed {
+		writeStreamEvent(c, event)
+	}
+
+	// 首次连接同步一次未读数
+	if lastEventID == 0 {
+		if count, err := service.GetUnreadCount(userID); err == nil {
+			hub.PushToUser(userID, realtime.EventUnreadCount, gin.H{"unreadCount": count})
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				// 订阅已被注销（如发送队列积压），结束响应让客户端重连补发
+				return
+			}
+			writeStreamEvent(c, event)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			c.Writer.WriteString(": ping\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeStreamEvent 按 SSE 格式写入单个事件
+func writeStreamEvent(c *gin.Context, event *realtime.Event) {
+	e := sse.Event{
+		Event: event.Type,
+		Data:  event,
+	}
+	if event.ID > 0 {
+		e.Id = strconv.FormatInt(event.ID, 10)
+	}
+	sse.Encode(c.Writer, e)
+}
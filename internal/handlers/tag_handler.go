@@ -186,11 +186,15 @@ func GetTagPosts(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	opts := parsePageOptions(c, 20)
+	opts.Sort = parsePostSort(c)
 	userID := c.GetString("userID")
 
-	posts, total, err := service.GetTagPosts(tagName, userID, page, pageSize)
+	posts, pageInfo, err := service.GetTagPosts(tagName, userID, opts)
+	if err == service.ErrInvalidCursor {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取失败: " + err.Error()})
 		return
@@ -207,6 +211,18 @@ func GetTagPosts(c *gin.Context) {
 			"video":     post.Video,
 			"tags":      post.Tags,
 			"createdAt": post.CreatedAt,
+			"editedAt":  post.EditedAt,
+			"shareCount": post.ShareCount,
+			"anonymous": post.Anonymous,
+			"repostOf":  post.RepostOf,
+			"poll":      post.Poll,
+			"isBookmarked": post.IsBookmarked,
+			"commentPolicy": post.CommentPolicy,
+			"reactions": post.Reactions,
+			"myReaction": post.MyReaction,
+			"isLiked": post.IsLiked,
+			"likedByFriends": post.LikedByFriends,
+			"friendLikeCount": post.FriendLikeCount,
 			"likeCount": post.LikeCount,
 			"commentCount": post.CommentCount,
 			"viewCount": post.ViewCount,
@@ -228,11 +244,8 @@ func GetTagPosts(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
 		"message":  "获取成功",
-		"data": gin.H{
+		"data": setPageInfo(gin.H{
 			"posts": convertedPosts,
-			"total": total,
-			"page":  page,
-			"pageSize": pageSize,
-		},
+		}, opts, pageInfo),
 	})
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -203,3 +204,29 @@ func UploadAvatar(c *gin.Context) {
 		},
 	})
 }
+
+// saveUpload 把上传的文件保存到 uploads 下的子目录，返回 uploads 下的相对路径和访问URL
+func saveUpload(c *gin.Context, file *multipart.FileHeader, subdir string) (string, string, error) {
+	uploadDir := filepath.Join("./uploads", subdir)
+	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+		return "", "", err
+	}
+
+	// 生成唯一文件名
+	uuid := uuid.New().String()
+	timestamp := time.Now().Format("20060102150405")
+	newFilename := fmt.Sprintf("%s_%s%s", timestamp, uuid[:8], strings.ToLower(filepath.Ext(file.Filename)))
+	if err := c.SaveUploadedFile(file, filepath.Join(uploadDir, newFilename)); err != nil {
+		return "", "", err
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	host := c.Request.Host
+	if host == "" {
+		host = "localhost:8080" // 默认值
+	}
+	return subdir + "/" + newFilename, fmt.Sprintf("%s://%s/static/%s/%s", scheme, host, subdir, newFilename), nil
+}
@@ -22,7 +22,7 @@ func GetUserByID(c *gin.Context) {
 		return
 	}
 
-	user, err := userService.GetPublicUserInfo(userID)
+	user, err := userService.GetPublicUserInfo(c.GetString("userID"), userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"code":    404,
@@ -173,7 +173,8 @@ func SearchUsers(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
 
-	users, total, err := userService.SearchUsers(keyword, page, pageSize)
+	userID := c.GetString("userID")
+	users, total, err := userService.SearchUsers(userID, keyword, page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -195,6 +196,31 @@ func SearchUsers(c *gin.Context) {
 	})
 }
 
+// GetMentionSuggestions @提及自动补全
+func GetMentionSuggestions(c *gin.Context) {
+	keyword := c.Query("keyword")
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	userID := c.GetString("userID")
+	suggestions, err := service.GetMentionSuggestions(userID, keyword, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "获取候选用户失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "获取成功",
+		"data": gin.H{
+			"users": suggestions,
+		},
+	})
+}
+
 // AdminResetUserPassword 管理员重置用户密码
 func AdminResetUserPassword(c *gin.Context) {
 	targetUserID := c.Param("userId")
@@ -372,7 +398,7 @@ func AdminGetUserPosts(c *gin.Context) {
 	// 使用 moment service 获取用户的动态
 	// 这里复用现有的 moment service，因为管理员需要能看到所有动态
 	var momentService *service.MomentService = service.NewMomentService()
-	posts, total, err := momentService.GetUserMoments(targetUserID, page, pageSize)
+	posts, pageInfo, err := momentService.GetUserMoments(targetUserID, service.PageOptions{Page: page, PageSize: pageSize, WithTotal: true})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -393,6 +419,10 @@ func AdminGetUserPosts(c *gin.Context) {
 			"video":     post.Video,
 			"tags":      post.Tags,
 			"createdAt": post.CreatedAt,
+			"editedAt":  post.EditedAt,
+			"shareCount": post.ShareCount,
+			"anonymous": post.Anonymous,
+			"repostOfId": post.RepostOfID,
 		}
 
 		// 添加作者信息
@@ -418,7 +448,7 @@ func AdminGetUserPosts(c *gin.Context) {
 		"message": "获取成功",
 		"data": gin.H{
 			"posts":    convertedPosts,
-			"total":    total,
+			"total":    pageInfo.Total,
 			"page":     page,
 			"pageSize": pageSize,
 		},
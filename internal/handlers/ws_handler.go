@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Yw332/campus-moments-go/internal/realtime"
+	"github.com/Yw332/campus-moments-go/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// 客户端包括移动端和不同域名下的网页，身份由 token 校验
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// ServeWebSocket 建立实时推送连接（新消息、已读回执、未读数变化、通知等）
+func ServeWebSocket(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade 失败时已自动返回错误响应
+		log.Printf("⚠️  WebSocket 握手失败: %v", err)
+		return
+	}
+
+	// 断线重连时可携带最后收到的事件ID，补发期间错过的事件
+	lastEventID, _ := strconv.ParseInt(c.Query("lastEventId"), 10, 64)
+
+	hub := realtime.GetHub()
+	client := realtime.NewClient(hub, conn)
+
+	client.Start(userID, lastEventID)
+
+	// 连接建立后先同步一次未读数
+	if count, err := service.GetUnreadCount(userID); err == nil {
+		hub.PushToUser(userID, realtime.EventUnreadCount, gin.H{"unreadCount": count})
+	}
+
+	client.ReadLoop()
+}
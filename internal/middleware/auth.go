@@ -49,39 +49,93 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 检查Token是否在黑名单中（退出登录的Token）
-		blacklist := token_blacklist.GetInstance()
-		if blacklist.IsBlacklisted(token) {
+		// 检查黑名单并验证token
+		claims, msg := verifyToken(token)
+		if claims == nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code":    401,
-				"message": "token has been revoked",
+				"message": msg,
 				"data":    nil,
 			})
 			c.Abort()
 			return
 		}
 
-		// 验证token
-		claims, err := jwt.ParseToken(token)
-		if err != nil {
+		// 将用户信息设置到上下文（转换为字符串ID）
+		setAuthContext(c, claims)
+
+		c.Next()
+	}
+}
+
+// StreamAuthMiddleware 长连接认证中间件（WebSocket / SSE）
+// 浏览器的 WebSocket 和 EventSource 无法自定义请求头，因此除 Authorization 头外也接受 ?token= 参数，
+// 校验规则与 AuthMiddleware 完全一致
+func StreamAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if auth := c.GetHeader("Authorization"); auth != "" {
+			if !strings.HasPrefix(auth, "Bearer ") {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"code":    401,
+					"message": "invalid authorization header format",
+					"data":    nil,
+				})
+				c.Abort()
+				return
+			}
+			token = strings.TrimPrefix(auth, "Bearer ")
+		}
+
+		if token == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code":    401,
-				"message": "invalid or expired token",
+				"message": "token cannot be empty",
 				"data":    nil,
 			})
 			c.Abort()
 			return
 		}
 
-	// 将用户信息设置到上下文（转换为字符串ID）
-		c.Set("userID", fmt.Sprintf("%010d", claims.UserID))
-		c.Set("username", claims.Username)
-		c.Set("claims", claims)
+		claims, msg := verifyToken(token)
+		if claims == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    401,
+				"message": msg,
+				"data":    nil,
+			})
+			c.Abort()
+			return
+		}
+
+		setAuthContext(c, claims)
 
 		c.Next()
 	}
 }
 
+// verifyToken 检查Token黑名单并解析，失败时返回错误信息
+func verifyToken(token string) (*jwt.Claims, string) {
+	// 检查Token是否在黑名单中（退出登录的Token）
+	if token_blacklist.GetInstance().IsBlacklisted(token) {
+		return nil, "token has been revoked"
+	}
+
+	claims, err := jwt.ParseToken(token)
+	if err != nil {
+		return nil, "invalid or expired token"
+	}
+
+	return claims, ""
+}
+
+// setAuthContext 将用户信息设置到上下文（转换为字符串ID）
+func setAuthContext(c *gin.Context, claims *jwt.Claims) {
+	c.Set("userID", fmt.Sprintf("%010d", claims.UserID))
+	c.Set("username", claims.Username)
+	c.Set("claims", claims)
+}
+
 // OptionalAuthMiddleware 可选认证中间件（用户信息可选）
 func OptionalAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -89,9 +143,7 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 		if auth != "" && strings.HasPrefix(auth, "Bearer ") {
 			token := strings.TrimPrefix(auth, "Bearer ")
 			if claims, err := jwt.ParseToken(token); err == nil {
-				c.Set("userID", claims.UserID)
-				c.Set("username", claims.Username)
-				c.Set("claims", claims)
+				setAuthContext(c, claims)
 			}
 		}
 		c.Next()
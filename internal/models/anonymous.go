@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// AnonymousUserID 树洞帖子及楼主在帖子下的评论使用的占位用户ID，真实作者记录在 anonymous_authors 表
+const AnonymousUserID = "anonymous"
+
+// AnonymousAuthor 树洞帖子的真实作者和帖子内固定的化名，只能通过管理员揭示接口查看真实作者
+type AnonymousAuthor struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	PostID    int64     `json:"postId" gorm:"column:post_id;type:bigint;not null;uniqueIndex"`
+	AuthorID  string    `json:"-" gorm:"column:author_id;type:char(10);not null;index"`
+	Pseudonym string    `json:"pseudonym" gorm:"column:pseudonym;type:varchar(20);not null"`
+	AvatarURL string    `json:"avatarUrl" gorm:"column:avatar;type:varchar(500)"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at;type:datetime"`
+}
+
+// 表名
+func (AnonymousAuthor) TableName() string {
+	return "anonymous_authors"
+}
+
+// AnonymousReveal 管理员揭示树洞帖子真实作者的审计记录，只增不改
+type AnonymousReveal struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	PostID    int64     `json:"postId" gorm:"column:post_id;type:bigint;not null;index"`
+	AdminID   string    `json:"adminId" gorm:"column:admin_id;type:char(10);not null;index"`
+	AuthorID  string    `json:"authorId" gorm:"column:author_id;type:char(10);not null"`
+	Reason    string    `json:"reason" gorm:"column:reason;type:varchar(500);not null"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at;type:datetime"`
+
+	// 关联字段（不设置外键约束）
+	Admin  *User `json:"admin,omitempty" gorm:"-"`
+	Author *User `json:"author,omitempty" gorm:"-"`
+}
+
+// 表名
+func (AnonymousReveal) TableName() string {
+	return "anonymous_reveals"
+}
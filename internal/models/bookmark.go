@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// BookmarkFolder 用户自建的收藏夹，未放入收藏夹的收藏 folder_id 为0
+type BookmarkFolder struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    string    `json:"userId" gorm:"column:user_id;type:char(10);not null;uniqueIndex:idx_bookmark_folder_name,priority:1"`
+	Name      string    `json:"name" gorm:"column:name;type:varchar(30);not null;uniqueIndex:idx_bookmark_folder_name,priority:2"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at;type:datetime"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updated_at;type:datetime"`
+
+	BookmarkCount int64 `json:"bookmarkCount" gorm:"-"`
+}
+
+// 表名
+func (BookmarkFolder) TableName() string {
+	return "bookmark_folders"
+}
+
+// Bookmark 收藏的帖子，每个用户对同一帖子只有一条收藏
+type Bookmark struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    string    `json:"userId" gorm:"column:user_id;type:char(10);not null;uniqueIndex:idx_bookmark_user_post,priority:1;index:idx_bookmarks_user_folder,priority:1"`
+	PostID    int64     `json:"postId" gorm:"column:post_id;type:bigint;not null;uniqueIndex:idx_bookmark_user_post,priority:2"`
+	FolderID  int64     `json:"folderId" gorm:"column:folder_id;type:bigint;default:0;index:idx_bookmarks_user_folder,priority:2;comment:0-未分类"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at;type:datetime;index:idx_bookmarks_user_folder,priority:3"`
+
+	// 关联字段（不设置外键约束）
+	Post      *Post  `json:"post,omitempty" gorm:"-"`
+	Tombstone string `json:"tombstone,omitempty" gorm:"-"` // 帖子已删除或当前用户无权查看时为 deleted / unavailable，此时 post 为空
+}
+
+// 表名
+func (Bookmark) TableName() string {
+	return "bookmarks"
+}
@@ -9,19 +9,28 @@ import (
 // Comment 评论模型
 type Comment struct {
 	ID            int           `json:"id" gorm:"primaryKey;autoIncrement"`
-	PostID        int64         `json:"postId" gorm:"column:post_id;type:bigint;not null;index"`
+	PostID        int64         `json:"postId" gorm:"column:post_id;type:bigint;not null;index;index:idx_comments_post_created,priority:1"`
 	UserID        string        `json:"userId" gorm:"column:user_id;type:char(10);not null;index"`
 	Content       string        `json:"content" gorm:"column:content;type:varchar(1000);not null"`
-	Replies       json.RawMessage `json:"replies" gorm:"column:replies;type:json"`
+	ParentID      int64         `json:"parentId" gorm:"column:parent_id;type:bigint;default:0;comment:被回复的评论，0表示一级评论"`
+	RootID        int64         `json:"rootId" gorm:"column:root_id;type:bigint;default:0;index:idx_comments_root_created,priority:1;comment:所属的一级评论，一级评论为0"`
+	ReplyToUserID string        `json:"replyToUserId,omitempty" gorm:"column:reply_to_user_id;type:char(10);comment:被回复的用户"`
+	ReplyCount    int           `json:"replyCount" gorm:"column:reply_count;type:int;default:0;comment:一级评论下未删除的回复数"`
 	LikeCount     int           `json:"likeCount" gorm:"column:like_count;type:int;default:0"`
 	IsAuthor      bool          `json:"isAuthor" gorm:"column:is_author;type:tinyint(1);default:0"`
-	Status        int           `json:"status" gorm:"column:status;type:tinyint;default:0"`
-	CreatedAt     time.Time     `json:"createdAt" gorm:"column:created_at;type:datetime"`
+	Status        int           `json:"status" gorm:"column:status;type:tinyint;default:0;comment:0-正常 1-删除 2-被帖子作者隐藏"`
+	CreatedAt     time.Time     `json:"createdAt" gorm:"column:created_at;type:datetime;index:idx_comments_post_created,priority:2;index:idx_comments_root_created,priority:2"`
 	UpdatedAt     time.Time     `json:"updatedAt" gorm:"column:updated_at;type:datetime"`
 
 	// 关联字段（不设置外键约束）
 	User          *User         `json:"user,omitempty" gorm:"-"`
+	ReplyToUser   *User         `json:"replyToUser,omitempty" gorm:"-"`
+	Replies       []Comment     `json:"replies,omitempty" gorm:"-"` // 一级评论列表中附带的前几条回复
+	IsPinned      bool          `json:"isPinned" gorm:"-"`          // 被帖子作者置顶
+	Reactions     []ReactionCount `json:"reactions" gorm:"-"`       // 各表情回应的数量
+	MyReaction    string        `json:"myReaction" gorm:"-"`        // 当前用户的表情回应，没有回应为空
 	Post          *Post         `json:"post,omitempty" gorm:"-"`
+	Mentions      []Mention     `json:"mentions,omitempty" gorm:"-"`
 }
 
 // 表名
@@ -29,17 +38,19 @@ func (Comment) TableName() string {
 	return "comments"
 }
 
-// Like 点赞模型
+// Like 点赞（表情回应）模型，每个用户对同一目标只保留一个表情回应
 type Like struct {
 	ID         int       `json:"id" gorm:"primaryKey;autoIncrement"`
-	UserID     string    `json:"userId" gorm:"column:user_id;type:char(10);not null;index"`
-	TargetType int       `json:"targetType" gorm:"column:target_type;type:tinyint;not null;comment:1-帖子 2-评论"`
-	TargetID   int64     `json:"targetId" gorm:"column:target_id;type:bigint;not null;index"`
-	CreatedAt  time.Time `json:"createdAt" gorm:"column:created_at;type:datetime"`
+	UserID     string    `json:"userId" gorm:"column:user_id;type:char(10);not null;uniqueIndex:idx_likes_user_target,priority:1;index:idx_likes_user_created,priority:1"` // 每个用户对每个目标只有一条回应
+	TargetType int       `json:"targetType" gorm:"column:target_type;type:tinyint;not null;uniqueIndex:idx_likes_user_target,priority:2;index:idx_likes_target_created,priority:1;comment:1-帖子 2-评论 3-消息"`
+	TargetID   int64     `json:"targetId" gorm:"column:target_id;type:bigint;not null;index;uniqueIndex:idx_likes_user_target,priority:3;index:idx_likes_target_created,priority:2"`
+	Reaction   string    `json:"reaction" gorm:"column:reaction;type:varchar(16);not null;default:'👍';comment:表情回应，旧的点赞为👍"`
+	CreatedAt  time.Time `json:"createdAt" gorm:"column:created_at;type:datetime;index:idx_likes_user_created,priority:2;index:idx_likes_target_created,priority:3"`
 
 	// 关联字段（不设置外键约束）
-	User *User `json:"user,omitempty" gorm:"-"`
-	Post *Post `json:"post,omitempty" gorm:"-"`
+	User      *User  `json:"user,omitempty" gorm:"-"`
+	Post      *Post  `json:"post,omitempty" gorm:"-"`
+	Tombstone string `json:"tombstone,omitempty" gorm:"-"` // 帖子已删除或当前用户无权查看时为 deleted / unavailable，此时 post 为空
 }
 
 // 表名
@@ -50,21 +61,28 @@ func (Like) TableName() string {
 // Message 消息模型
 type Message struct {
 	ID             int64     `json:"id" gorm:"primaryKey;column:id;autoIncrement"`
-	SenderID       string    `json:"senderId" gorm:"column:sender_id;type:char(10);not null;index"`
-	ReceiverID     string    `json:"receiverId" gorm:"column:receiver_id;type:char(10);not null;index"`
+	SenderID       string    `json:"senderId" gorm:"column:sender_id;type:char(10);not null;index;uniqueIndex:idx_messages_client_msg,priority:1"`
+	ReceiverID     string    `json:"receiverId" gorm:"column:receiver_id;type:char(10);not null;index;comment:群消息为空"`
+	GroupID        int64     `json:"groupId" gorm:"column:group_id;type:bigint;default:0;index;comment:群聊ID，单聊为0"`
 	MsgType        int       `json:"msgType" gorm:"column:msg_type;type:tinyint;not null;comment:1-文本 2-图片 3-视频 4-文件"`
 	ContentPreview string    `json:"contentPreview" gorm:"column:content_preview;type:varchar(255)"`
 	FileURL        string    `json:"fileUrl" gorm:"column:file_url;type:varchar(500)"`
 	FileSize       int       `json:"fileSize" gorm:"column:file_size;type:int"`
 	IsEncrypted    bool      `json:"isEncrypted" gorm:"column:is_encrypted;type:tinyint(1);default:0"`
 	IsRead         bool      `json:"isRead" gorm:"column:is_read;type:tinyint(1);default:0"`
-	DeviceID       string    `json:"deviceId" gorm:"column:device_id;type:varchar(64)"`
+	DeviceID       string    `json:"deviceId" gorm:"column:device_id;type:varchar(64);uniqueIndex:idx_messages_client_msg,priority:2"`
+	ClientMsgID    *string   `json:"clientMsgId" gorm:"column:client_msg_id;type:varchar(64);uniqueIndex:idx_messages_client_msg,priority:3;comment:客户端生成的消息ID，用于重试去重"`
 	ServerMsgID    string    `json:"serverMsgId" gorm:"column:server_msg_id;type:varchar(64)"`
+	Status         int       `json:"status" gorm:"column:status;type:tinyint;default:0;comment:0-正常 1-已撤回"`
+	EditedAt       *time.Time `json:"editedAt" gorm:"column:edited_at;type:datetime"`
+	RecalledAt     *time.Time `json:"recalledAt" gorm:"column:recalled_at;type:datetime"`
 	CreatedAt      time.Time `json:"createdAt" gorm:"column:created_at;type:datetime"`
 
 	// 关联字段（不设置外键约束）
 	Sender         *User     `json:"sender,omitempty" gorm:"-"`
 	Receiver       *User     `json:"receiver,omitempty" gorm:"-"`
+	Reactions      []ReactionCount `json:"reactions,omitempty" gorm:"-"` // 各表情回应的数量
+	MyReaction     string    `json:"myReaction,omitempty" gorm:"-"`        // 当前用户的表情回应
 }
 
 // 表名
@@ -72,11 +90,38 @@ func (Message) TableName() string {
 	return "messages"
 }
 
-// Conversation 会话模型
+// 消息状态
+const (
+	MessageNormal   = 0 // 正常
+	MessageRecalled = 1 // 已撤回
+)
+
+// MessageEdit 消息编辑历史（记录每次编辑前的内容）
+type MessageEdit struct {
+	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	MessageID  int64     `json:"messageId" gorm:"column:message_id;type:bigint;not null;index"`
+	OldContent string    `json:"oldContent" gorm:"column:old_content;type:varchar(255)"`
+	EditedAt   time.Time `json:"editedAt" gorm:"column:edited_at;type:datetime"`
+}
+
+// 表名
+func (MessageEdit) TableName() string {
+	return "message_edits"
+}
+
+// 会话类型
+const (
+	ConversationDirect = 1 // 单聊
+	ConversationGroup  = 2 // 群聊
+)
+
+// Conversation 会话模型（每个参与者一条，未读数、置顶、静音按成员独立记录）
 type Conversation struct {
 	ID              int        `json:"id" gorm:"primaryKey;autoIncrement"`
 	UserID          string     `json:"userId" gorm:"column:user_id;type:char(10);not null;index"`
-	PeerID          string     `json:"peerId" gorm:"column:peer_id;type:char(10);not null;index"`
+	ConvType        int        `json:"convType" gorm:"column:conv_type;type:tinyint;default:1;comment:1-单聊 2-群聊"`
+	PeerID          string     `json:"peerId" gorm:"column:peer_id;type:char(10);not null;index;comment:单聊对方ID，群聊为空"`
+	GroupID         int64      `json:"groupId" gorm:"column:group_id;type:bigint;default:0;index;comment:群聊ID，单聊为0"`
 	LastMsgID       int64      `json:"lastMsgId" gorm:"column:last_msg_id;type:bigint"`
 	LastMsgPreview  string     `json:"lastMsgPreview" gorm:"column:last_msg_preview;type:varchar(255)"`
 	UnreadCount     int        `json:"unreadCount" gorm:"column:unread_count;type:int;default:0"`
@@ -87,6 +132,7 @@ type Conversation struct {
 	// 关联字段（不设置外键约束）
 	User            *User      `json:"user,omitempty" gorm:"-"`
 	Peer            *User      `json:"peer,omitempty" gorm:"-"`
+	Group           *ChatGroup `json:"group,omitempty" gorm:"-"`
 	LastMessage     *Message   `json:"lastMessage,omitempty" gorm:"-"`
 }
 
@@ -157,6 +203,20 @@ func (Tag) TableName() string {
 
 
 
+// 评论状态
+const (
+	CommentStatusNormal  = 0
+	CommentStatusDeleted = 1
+	CommentStatusHidden  = 2 // 被帖子作者隐藏，可以恢复
+)
+
+// 帖子的评论权限
+const (
+	CommentPolicyEveryone = 0 // 所有人可评论
+	CommentPolicyFriends  = 1 // 仅作者的好友可评论
+	CommentPolicyClosed   = 2 // 关闭评论
+)
+
 // Post 帖子模型（完整版本，与现有Moment合并）
 type Post struct {
 	ID              int64           `json:"id" gorm:"primaryKey;autoIncrement"`
@@ -166,25 +226,98 @@ type Post struct {
 	Images          json.RawMessage `json:"images" gorm:"column:images;type:json"`
 	Video           string          `json:"video" gorm:"column:video;type:varchar(200)"`
 	Visibility      int             `json:"visibility" gorm:"column:visibility;type:tinyint;default:0;comment:0-公开 1-好友 2-仅自己"`
-	Status          int             `json:"status" gorm:"column:status;type:tinyint;default:0;comment:0-正常 1-删除"`
+	Status          int             `json:"status" gorm:"column:status;type:tinyint;default:0;comment:0-正常 1-删除 2-草稿 3-定时发布"`
 	Tags            json.RawMessage `json:"tags" gorm:"column:tags;type:json"`
-	LikedUsers      json.RawMessage `json:"likedUsers" gorm:"column:liked_users;type:json"`
 	CommentsSummary json.RawMessage `json:"commentsSummary" gorm:"column:comments_summary;type:json"`
 	LikeCount       int             `json:"likeCount" gorm:"column:like_count;type:int;default:0"`
 	CommentCount    int             `json:"commentCount" gorm:"column:comment_count;type:int;default:0"`
 	ViewCount       int             `json:"viewCount" gorm:"column:view_count;type:int;default:0"`
+	HotScore        float64         `json:"hotScore" gorm:"column:hot_score;type:double;default:0"`
+	ShareCount      int             `json:"shareCount" gorm:"column:share_count;type:int;default:0"`
+	Anonymous       bool            `json:"anonymous" gorm:"column:anonymous;type:tinyint(1);default:0"`
+	CommentPolicy   int             `json:"commentPolicy" gorm:"column:comment_policy;type:tinyint;default:0"`
+	PinnedCommentID int64           `json:"pinnedCommentId" gorm:"column:pinned_comment_id;type:bigint;default:0"`
+	RepostOfID      *int64          `json:"repostOfId,omitempty" gorm:"column:repost_of_id;type:bigint"`
+	PublishAt       *time.Time      `json:"publishAt,omitempty" gorm:"column:publish_at;type:datetime"`
+	EditedAt        *time.Time      `json:"editedAt" gorm:"column:edited_at;type:datetime"`
 	CreatedAt       time.Time       `json:"createdAt" gorm:"column:created_at;type:datetime"`
 	UpdatedAt       time.Time       `json:"updatedAt" gorm:"column:updated_at;type:datetime"`
 	
 	// 关联字段（不设置外键约束）
 	User            *User           `json:"user,omitempty" gorm:"-"`
+	Mentions        []Mention       `json:"mentions,omitempty" gorm:"-"`
+	RepostOf        *RepostOrigin   `json:"repostOf,omitempty" gorm:"-"`
+	Poll            *Poll           `json:"poll,omitempty" gorm:"-"`
+	
+	// 当前用户视角
+	IsBookmarked    bool            `json:"isBookmarked" gorm:"-"`
+	Reactions       []ReactionCount `json:"reactions" gorm:"-"`  // 各表情回应的数量，likeCount 为它们的总和
+	MyReaction      string          `json:"myReaction" gorm:"-"` // 当前用户的表情回应，没有回应为空
+	IsLiked         bool            `json:"isLiked" gorm:"-"`    // 当前用户是否点赞（任意表情回应）
+	LikedByFriends  []*PostAuthor   `json:"likedByFriends" gorm:"-"`  // 最近点赞的几位好友
+	FriendLikeCount int             `json:"friendLikeCount" gorm:"-"` // 点赞的好友总数
 }
 
+// 转发原帖的墓碑状态
+const (
+	RepostTombstoneDeleted     = "deleted"     // 原帖已删除
+	RepostTombstoneUnavailable = "unavailable" // 当前用户无权查看原帖
+)
+
+// RepostOrigin 转发帖子中内嵌展示的原帖，原帖已删除或不可见时只返回 postId 和 tombstone
+type RepostOrigin struct {
+	PostID    int64           `json:"postId"`
+	Tombstone string          `json:"tombstone,omitempty"`
+	Title     string          `json:"title,omitempty"`
+	Content   string          `json:"content,omitempty"`
+	Images    json.RawMessage `json:"images,omitempty"`
+	Video     string          `json:"video,omitempty"`
+	Tags      json.RawMessage `json:"tags,omitempty"`
+	CreatedAt *time.Time      `json:"createdAt,omitempty"`
+	EditedAt  *time.Time      `json:"editedAt,omitempty"`
+	Author    *PostAuthor     `json:"author,omitempty"`
+}
+
+// PostAuthor 内嵌展示的作者信息
+type PostAuthor struct {
+	UserID    string `json:"userId"`
+	Username  string `json:"username"`
+	AvatarURL string `json:"avatarUrl"`
+}
+
+// 帖子状态
+const (
+	PostStatusNormal    = 0 // 已发布
+	PostStatusDeleted   = 1 // 已删除
+	PostStatusDraft     = 2 // 草稿
+	PostStatusScheduled = 3 // 等待定时发布
+)
+
 // 表名
 func (Post) TableName() string {
 	return "posts"
 }
 
+// PostRevision 帖子编辑历史（记录每次编辑前的内容，只增不改）
+type PostRevision struct {
+	ID         int64           `json:"id" gorm:"primaryKey;autoIncrement"`
+	PostID     int64           `json:"postId" gorm:"column:post_id;type:bigint;not null;index"`
+	Title      string          `json:"title" gorm:"column:title;type:varchar(100)"`
+	Content    string          `json:"content" gorm:"column:content;type:text;not null"`
+	Images     json.RawMessage `json:"images" gorm:"column:images;type:json"`
+	Video      string          `json:"video" gorm:"column:video;type:varchar(200)"`
+	Tags       json.RawMessage `json:"tags" gorm:"column:tags;type:json"`
+	Visibility int             `json:"visibility" gorm:"column:visibility;type:tinyint"`
+	EditedAt   time.Time       `json:"editedAt" gorm:"column:edited_at;type:datetime;comment:该版本被替换的时间"`
+
+	Version int `json:"version" gorm:"-"` // 版本号，从1开始，当前版本为最大版本号加1
+}
+
+// 表名
+func (PostRevision) TableName() string {
+	return "post_revisions"
+}
+
 // ImageURLs 图片URL数组类型
 type ImageURLs []string
 
@@ -28,6 +28,25 @@ func AutoMigrate() {
 		&SearchHistory{},  // search_history表
 		&VerificationCode{}, // verification_codes表
 		&ResetPasswordLog{}, // reset_password_logs表
+		&Notification{},      // notifications表
+		&NotificationActor{}, // notification_actors表
+		&ChatGroup{},         // chat_groups表
+		&GroupMember{},       // group_members表
+		&MessageEdit{},       // message_edits表
+		&UserSyncSeq{},       // user_sync_seqs表
+		&SyncEvent{},         // sync_events表
+		&Mention{},           // mentions表
+		&Follow{},            // follows表
+		&PostRevision{},      // post_revisions表
+		&Poll{},              // polls表
+		&PollOption{},        // poll_options表
+		&PollVote{},          // poll_votes表
+		&AnonymousAuthor{},   // anonymous_authors表
+		&AnonymousReveal{},   // anonymous_reveals表
+		&BookmarkFolder{},    // bookmark_folders表
+		&Bookmark{},          // bookmarks表
+		&Story{},             // stories表
+		&StoryView{},         // story_views表
 	}
 
 	for _, table := range tables {
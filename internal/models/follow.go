@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// Follow 单向关注关系，无需对方同意
+type Follow struct {
+	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	FollowerID string    `json:"followerId" gorm:"column:follower_id;type:char(10);not null;uniqueIndex:idx_follow_pair,priority:1;comment:关注者"`
+	FolloweeID string    `json:"followeeId" gorm:"column:followee_id;type:char(10);not null;uniqueIndex:idx_follow_pair,priority:2;index:idx_follows_followee;comment:被关注者"`
+	CreatedAt  time.Time `json:"createdAt" gorm:"column:created_at;type:datetime"`
+
+	// 关联字段（不设置外键约束）
+	User        *User `json:"user,omitempty" gorm:"-"` // 列表中的对方用户
+	IsFollowing bool  `json:"isFollowing" gorm:"-"`    // 当前用户是否已关注对方
+}
+
+// 表名
+func (Follow) TableName() string {
+	return "follows"
+}
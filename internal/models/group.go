@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+)
+
+// 群成员角色
+const (
+	GroupRoleMember = 0 // 普通成员
+	GroupRoleAdmin  = 1 // 管理员
+	GroupRoleOwner  = 2 // 群主
+)
+
+// ChatGroup 群聊模型
+type ChatGroup struct {
+	ID          int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string    `json:"name" gorm:"column:name;type:varchar(50);not null"`
+	Avatar      string    `json:"avatar" gorm:"column:avatar;type:varchar(500)"`
+	OwnerID     string    `json:"ownerId" gorm:"column:owner_id;type:char(10);not null;index"`
+	MemberCount int       `json:"memberCount" gorm:"column:member_count;type:int;default:0"`
+	Status      int       `json:"status" gorm:"column:status;type:tinyint;default:0;comment:0-正常 1-已解散"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:created_at;type:datetime"`
+	UpdatedAt   time.Time `json:"updatedAt" gorm:"column:updated_at;type:datetime"`
+}
+
+// 表名
+func (ChatGroup) TableName() string {
+	return "chat_groups"
+}
+
+// GroupMember 群成员模型
+type GroupMember struct {
+	ID       int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	GroupID  int64     `json:"groupId" gorm:"column:group_id;type:bigint;not null;uniqueIndex:idx_group_member,priority:1"`
+	UserID   string    `json:"userId" gorm:"column:user_id;type:char(10);not null;uniqueIndex:idx_group_member,priority:2;index"`
+	Role     int       `json:"role" gorm:"column:role;type:tinyint;default:0;comment:0-成员 1-管理员 2-群主"`
+	JoinedAt time.Time `json:"joinedAt" gorm:"column:joined_at;type:datetime"`
+
+	// 关联字段（不设置外键约束）
+	User *User `json:"user,omitempty" gorm:"-"`
+}
+
+// 表名
+func (GroupMember) TableName() string {
+	return "group_members"
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// @提及来源类型
+const (
+	MentionSourcePost    = 1 // 帖子
+	MentionSourceComment = 2 // 评论
+)
+
+// Mention 帖子/评论中的@提及，按用户ID和字符偏移记录，用户改名后仍能正确渲染
+type Mention struct {
+	ID         int64     `json:"-" gorm:"primaryKey;autoIncrement"`
+	SourceType int       `json:"-" gorm:"column:source_type;type:tinyint;not null;index:idx_mentions_source,priority:1;comment:1-帖子 2-评论"`
+	SourceID   int64     `json:"-" gorm:"column:source_id;type:bigint;not null;index:idx_mentions_source,priority:2"`
+	PostID     int64     `json:"-" gorm:"column:post_id;type:bigint;not null"`
+	UserID     string    `json:"userId" gorm:"column:user_id;type:char(10);not null;index;comment:被提及的用户"`
+	Offset     int       `json:"offset" gorm:"column:start_pos;type:int;not null;comment:@ 在内容中的字符偏移"`
+	Length     int       `json:"length" gorm:"column:length;type:int;not null;comment:含 @ 的字符长度"`
+	CreatedAt  time.Time `json:"-" gorm:"column:created_at;type:datetime"`
+
+	Username string `json:"username" gorm:"-"` // 被提及用户的当前用户名
+}
+
+// 表名
+func (Mention) TableName() string {
+	return "mentions"
+}
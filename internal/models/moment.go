@@ -16,14 +16,21 @@ type Moment struct {
 	Images          json.RawMessage `json:"images" gorm:"column:images;type:json"`
 	Video           string          `json:"video" gorm:"column:video;type:varchar(200)"`
 	Visibility      int             `json:"visibility" gorm:"column:visibility;type:tinyint;default:0;comment:0-公开 1-好友 2-仅自己"`
-	Status          int             `json:"status" gorm:"column:status;type:tinyint;default:0;comment:0-正常 1-删除"`
+	Status          int             `json:"status" gorm:"column:status;type:tinyint;default:0;index:idx_posts_status_publish_at,priority:1;comment:0-正常 1-删除 2-草稿 3-定时发布"`
 	Tags            json.RawMessage `json:"tags" gorm:"column:tags;type:json"`
-	LikedUsers      json.RawMessage `json:"likedUsers" gorm:"column:liked_users;type:json"`
 	CommentsSummary json.RawMessage `json:"commentsSummary" gorm:"column:comments_summary;type:json"`
 	LikeCount       int             `json:"likeCount" gorm:"column:like_count;type:int;default:0"`
 	CommentCount    int             `json:"commentCount" gorm:"column:comment_count;type:int;default:0"`
 	ViewCount       int             `json:"viewCount" gorm:"column:view_count;type:int;default:0"`
-	CreatedAt       time.Time       `json:"createdAt" gorm:"column:created_at;type:datetime"`
+	HotScore        float64         `json:"hotScore" gorm:"column:hot_score;type:double;default:0;index:idx_posts_hot_score;comment:热度分，由后台任务定期重算"`
+	ShareCount      int             `json:"shareCount" gorm:"column:share_count;type:int;default:0;comment:被转发次数"`
+	Anonymous       bool            `json:"anonymous" gorm:"column:anonymous;type:tinyint(1);default:0;comment:树洞帖子，user_id 为占位ID"`
+	CommentPolicy   int             `json:"commentPolicy" gorm:"column:comment_policy;type:tinyint;default:0;comment:0-所有人 1-仅好友 2-关闭评论"`
+	PinnedCommentID int64           `json:"pinnedCommentId" gorm:"column:pinned_comment_id;type:bigint;default:0;comment:置顶的一级评论，0表示没有"`
+	RepostOfID      *int64          `json:"repostOfId,omitempty" gorm:"column:repost_of_id;type:bigint;index:idx_posts_repost_of;comment:转发的原帖ID"`
+	PublishAt       *time.Time      `json:"publishAt,omitempty" gorm:"column:publish_at;type:datetime;index:idx_posts_status_publish_at,priority:2;comment:定时发布时间"` // 定时任务按 (status, publish_at) 扫描到期帖子
+	EditedAt        *time.Time      `json:"editedAt" gorm:"column:edited_at;type:datetime;comment:最后一次编辑内容的时间"`
+	CreatedAt       time.Time       `json:"createdAt" gorm:"column:created_at;type:datetime;index:idx_posts_created_at"` // 游标分页按 (created_at, id) 排序
 	UpdatedAt       time.Time       `json:"updatedAt" gorm:"column:updated_at;type:datetime"`
 	
 	// 关联字段（不设置外键约束）
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+)
+
+// 通知类型
+const (
+	NotificationPostLiked             = 1 // 帖子被赞
+	NotificationCommentLiked          = 2 // 评论被赞
+	NotificationPostCommented         = 3 // 帖子被评论
+	NotificationCommentReplied        = 4 // 评论被回复
+	NotificationFriendRequestReceived = 5 // 收到好友请求
+	NotificationFriendRequestAccepted = 6 // 好友请求已同意
+	NotificationPostMentioned         = 7 // 在帖子中被@
+	NotificationCommentMentioned      = 8 // 在评论中被@
+	NotificationPostReposted          = 9 // 帖子被转发
+)
+
+// NotificationTypeNames 通知类型对外名称
+var NotificationTypeNames = map[int]string{
+	NotificationPostLiked:             "post_liked",
+	NotificationCommentLiked:          "comment_liked",
+	NotificationPostCommented:         "post_commented",
+	NotificationCommentReplied:        "comment_replied",
+	NotificationFriendRequestReceived: "friend_request_received",
+	NotificationFriendRequestAccepted: "friend_request_accepted",
+	NotificationPostMentioned:         "post_mentioned",
+	NotificationCommentMentioned:      "comment_mentioned",
+	NotificationPostReposted:          "post_reposted",
+}
+
+// Notification 通知模型（同一目标的同类未读通知会聚合为一条）
+type Notification struct {
+	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID     string    `json:"userId" gorm:"column:user_id;type:char(10);not null;index:idx_notifications_user_updated,priority:1"`
+	Type       int       `json:"type" gorm:"column:type;type:tinyint;not null;comment:1-帖子被赞 2-评论被赞 3-帖子被评论 4-评论被回复 5-收到好友请求 6-好友请求已同意 7-帖子中被@ 8-评论中被@ 9-帖子被转发"`
+	ActorID    string    `json:"actorId" gorm:"column:actor_id;type:char(10);not null;comment:最近一次触发者"`
+	ActorCount int       `json:"actorCount" gorm:"column:actor_count;type:int;default:1"`
+	TargetID   int64     `json:"targetId" gorm:"column:target_id;type:bigint;comment:帖子/评论/好友请求ID"`
+	PostID     int64     `json:"postId" gorm:"column:post_id;type:bigint"`
+	Content    string    `json:"content" gorm:"column:content;type:varchar(255)"`
+	GroupKey   string    `json:"-" gorm:"column:group_key;type:varchar(64);index"`
+	IsRead     bool      `json:"isRead" gorm:"column:is_read;type:tinyint(1);default:0"`
+	CreatedAt  time.Time `json:"createdAt" gorm:"column:created_at;type:datetime"`
+	UpdatedAt  time.Time `json:"updatedAt" gorm:"column:updated_at;type:datetime;index:idx_notifications_user_updated,priority:2"`
+
+	// 关联字段（不设置外键约束）
+	Actors []*PostAuthor `json:"actors,omitempty" gorm:"-"` // 最近的几位触发者，只含公开信息
+}
+
+// 表名
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationActor 聚合通知的触发者
+type NotificationActor struct {
+	ID             int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	NotificationID int64     `json:"notificationId" gorm:"column:notification_id;type:bigint;not null;uniqueIndex:idx_notification_actor"`
+	ActorID        string    `json:"actorId" gorm:"column:actor_id;type:char(10);not null;uniqueIndex:idx_notification_actor"`
+	CreatedAt      time.Time `json:"createdAt" gorm:"column:created_at;type:datetime"`
+}
+
+// 表名
+func (NotificationActor) TableName() string {
+	return "notification_actors"
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+)
+
+// Poll 帖子附带的投票，每个帖子最多一个
+type Poll struct {
+	ID                    int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	PostID                int64      `json:"postId" gorm:"column:post_id;type:bigint;not null;uniqueIndex"`
+	MultipleChoice        bool       `json:"multipleChoice" gorm:"column:multiple_choice;type:tinyint(1);default:0;comment:是否多选"`
+	Anonymous             bool       `json:"anonymous" gorm:"column:anonymous;type:tinyint(1);default:0;comment:匿名投票时不公开投票人"`
+	HideResultsUntilVoted bool       `json:"hideResultsUntilVoted" gorm:"column:hide_results_until_voted;type:tinyint(1);default:0;comment:投票前隐藏结果"`
+	Deadline              *time.Time `json:"deadline" gorm:"column:deadline;type:datetime;comment:截止时间，为空表示不截止"`
+	VoterCount            int        `json:"voterCount" gorm:"column:voter_count;type:int;default:0"`
+	CreatedAt             time.Time  `json:"createdAt" gorm:"column:created_at;type:datetime"`
+
+	// 关联字段（不设置外键约束）
+	Options []PollOption `json:"options" gorm:"-"`
+
+	// 当前用户视角
+	HasVoted      bool    `json:"hasVoted" gorm:"-"`
+	MyOptionIDs   []int64 `json:"myOptionIds" gorm:"-"`
+	ResultsHidden bool    `json:"resultsHidden" gorm:"-"` // 为 true 时票数均返回0
+	Closed        bool    `json:"closed" gorm:"-"`
+}
+
+// 表名
+func (Poll) TableName() string {
+	return "polls"
+}
+
+// PollOption 投票选项
+type PollOption struct {
+	ID        int64  `json:"id" gorm:"primaryKey;autoIncrement"`
+	PollID    int64  `json:"pollId" gorm:"column:poll_id;type:bigint;not null;index"`
+	Position  int    `json:"position" gorm:"column:position;type:int;not null"`
+	Text      string `json:"text" gorm:"column:text;type:varchar(100);not null"`
+	VoteCount int    `json:"voteCount" gorm:"column:vote_count;type:int;default:0"`
+}
+
+// 表名
+func (PollOption) TableName() string {
+	return "poll_options"
+}
+
+// PollVote 投票记录，多选时每个选项一条
+type PollVote struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	PollID    int64     `json:"pollId" gorm:"column:poll_id;type:bigint;not null;uniqueIndex:idx_poll_vote,priority:1"`
+	UserID    string    `json:"userId" gorm:"column:user_id;type:char(10);not null;uniqueIndex:idx_poll_vote,priority:2"`
+	OptionID  int64     `json:"optionId" gorm:"column:option_id;type:bigint;not null;uniqueIndex:idx_poll_vote,priority:3;index:idx_poll_votes_option"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at;type:datetime"`
+
+	// 关联字段（不设置外键约束）
+	User *User `json:"user,omitempty" gorm:"-"`
+}
+
+// 表名
+func (PollVote) TableName() string {
+	return "poll_votes"
+}
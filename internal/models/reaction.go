@@ -0,0 +1,17 @@
+package models
+
+// ReactionLike 旧版点赞对应的表情回应，旧的点赞接口读写的都是它
+const ReactionLike = "👍"
+
+// 表情回应的目标类型，与 Like.TargetType 一致
+const (
+	ReactionTargetPost    = 1
+	ReactionTargetComment = 2
+	ReactionTargetMessage = 3
+)
+
+// ReactionCount 某个表情回应的数量
+type ReactionCount struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// 快拍媒体类型
+const (
+	StoryMediaImage = 1
+	StoryMediaVideo = 2
+)
+
+// Story 快拍，仅好友可见，发布后按配置的有效期（默认24小时）过期
+type Story struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    string    `json:"userId" gorm:"column:user_id;type:char(10);not null;index:idx_stories_user_expires,priority:1"`
+	MediaType int       `json:"mediaType" gorm:"column:media_type;type:tinyint;not null;comment:1-图片 2-视频"`
+	MediaURL  string    `json:"mediaUrl" gorm:"column:media_url;type:varchar(500);not null"`
+	MediaPath string    `json:"-" gorm:"column:media_path;type:varchar(255);not null;comment:uploads 目录下的相对路径，过期后删除"`
+	Caption   string    `json:"caption" gorm:"column:caption;type:varchar(200)"`
+	ViewCount int       `json:"viewCount" gorm:"column:view_count;type:int;default:0"`
+	ExpiresAt time.Time `json:"expiresAt" gorm:"column:expires_at;type:datetime;not null;index:idx_stories_user_expires,priority:2;index:idx_stories_expires"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at;type:datetime"`
+
+	// 当前用户视角
+	Seen bool `json:"seen" gorm:"-"`
+}
+
+// 表名
+func (Story) TableName() string {
+	return "stories"
+}
+
+// StoryView 快拍的查看记录，每人每条快拍只记录第一次查看
+type StoryView struct {
+	ID       int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	StoryID  int64     `json:"storyId" gorm:"column:story_id;type:bigint;not null;uniqueIndex:idx_story_viewer,priority:1"`
+	ViewerID string    `json:"viewerId" gorm:"column:viewer_id;type:char(10);not null;uniqueIndex:idx_story_viewer,priority:2"`
+	ViewedAt time.Time `json:"viewedAt" gorm:"column:viewed_at;type:datetime"`
+
+	// 关联字段（不设置外键约束）
+	User *User `json:"user,omitempty" gorm:"-"`
+}
+
+// 表名
+func (StoryView) TableName() string {
+	return "story_views"
+}
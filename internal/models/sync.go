@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 同步事件类型
+const (
+	SyncEventMessage             = "message"              // 新消息
+	SyncEventRead                = "read"                 // 消息已读
+	SyncEventRecall              = "recall"               // 消息撤回
+	SyncEventEdit                = "edit"                 // 消息编辑
+	SyncEventReaction            = "reaction"             // 消息的表情回应变化
+	SyncEventConversationUpdated = "conversation_updated" // 会话置顶/静音变化
+	SyncEventConversationDeleted = "conversation_deleted" // 会话被删除
+)
+
+// UserSyncSeq 用户当前的同步序号
+type UserSyncSeq struct {
+	UserID string `json:"userId" gorm:"primaryKey;column:user_id;type:char(10)"`
+	Seq    int64  `json:"seq" gorm:"column:seq;type:bigint;not null;default:0"`
+}
+
+// 表名
+func (UserSyncSeq) TableName() string {
+	return "user_sync_seqs"
+}
+
+// SyncEvent 多端同步事件，seq 在同一用户内单调递增
+type SyncEvent struct {
+	ID        int64           `json:"-" gorm:"primaryKey;autoIncrement"`
+	UserID    string          `json:"-" gorm:"column:user_id;type:char(10);not null;uniqueIndex:idx_sync_events_user_seq,priority:1"`
+	Seq       int64           `json:"seq" gorm:"column:seq;type:bigint;not null;uniqueIndex:idx_sync_events_user_seq,priority:2"`
+	Type      string          `json:"type" gorm:"column:type;type:varchar(32);not null"`
+	PeerID    string          `json:"peerId,omitempty" gorm:"column:peer_id;type:char(10)"`
+	GroupID   int64           `json:"groupId,omitempty" gorm:"column:group_id;type:bigint;default:0"`
+	MessageID int64           `json:"messageId,omitempty" gorm:"column:message_id;type:bigint;default:0"`
+	Payload   json.RawMessage `json:"payload" gorm:"column:payload;type:json"`
+	CreatedAt time.Time       `json:"createdAt" gorm:"column:created_at;type:datetime"`
+}
+
+// 表名
+func (SyncEvent) TableName() string {
+	return "sync_events"
+}
@@ -15,6 +15,8 @@ type User struct {
 	PostCount       int       `json:"postCount" gorm:"column:post_count;type:int;default:0"`
 	LikeCount       int       `json:"likeCount" gorm:"column:like_count;type:int;default:0"`
 	CommentCount    int       `json:"commentCount" gorm:"column:comment_count;type:int;default:0"`
+	FollowerCount   int       `json:"followerCount" gorm:"column:follower_count;type:int;default:0"`
+	FollowingCount  int       `json:"followingCount" gorm:"column:following_count;type:int;default:0"`
 	Status          int64     `json:"status" gorm:"column:status;type:bigint"`
 	Role            int       `json:"role" gorm:"column:role;type:tinyint;default:0;comment:0-普通用户 1-管理员"`
 	LastLoginAt     *time.Time `json:"lastLoginAt" gorm:"column:last_login_at;type:datetime"`
@@ -0,0 +1,123 @@
+package realtime
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait      = 10 * time.Second    // 单次写超时
+	pongWait       = 60 * time.Second    // 等待心跳响应的最长时间
+	pingPeriod     = (pongWait * 9) / 10 // 发送心跳的间隔，必须小于 pongWait
+	maxMessageSize = 4096                // 客户端上行消息的最大字节数
+)
+
+// Client 单个设备的 WebSocket 连接
+type Client struct {
+	hub  *Hub
+	sub  *Subscription
+	conn *websocket.Conn
+	pong chan struct{}
+}
+
+// NewClient 创建连接
+func NewClient(hub *Hub, conn *websocket.Conn) *Client {
+	return &Client{
+		hub:  hub,
+		conn: conn,
+		pong: make(chan struct{}, 1),
+	}
+}
+
+// Start 订阅用户事件流并启动写协程，先补发 lastEventID 之后错过的事件
+// 历史不完整时会先推送一条 resync 事件，提示客户端通过接口重新同步
+func (c *Client) Start(userID string, lastEventID int64) {
+	sub, missed, complete := c.hub.Subscribe(userID, lastEventID)
+	c.sub = sub
+	go c.writePump(missed, complete)
+}
+
+// ReadLoop 读取客户端消息并负责心跳超时检测，阻塞直到连接断开
+func (c *Client) ReadLoop() {
+	defer func() {
+		c.hub.Unsubscribe(c.sub)
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		// 任何上行消息都视为存活；支持应用层 {"type":"ping"} 心跳
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		var msg struct {
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(data, &msg) == nil && msg.Type == "ping" {
+			select {
+			case c.pong <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// writePump 将订阅的事件写入连接，并定时发送心跳；连接只允许一个写协程
+func (c *Client) writePump(missed []*Event, complete bool) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	if !complete {
+		if c.write(&Event{Type: EventResync, Time: time.Now()}) != nil {
+			return
+		}
+	}
+	for _, event := range missed {
+		if c.write(event) != nil {
+			return
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-c.sub.Events():
+			if !ok {
+				// 订阅已被注销（如发送队列积压）
+				c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if c.write(event) != nil {
+				return
+			}
+		case <-c.pong:
+			if c.write(&Event{Type: "pong", Time: time.Now()}) != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// write 写入单个事件
+func (c *Client) write(event *Event) error {
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.conn.WriteJSON(event)
+}
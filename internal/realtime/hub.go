@@ -0,0 +1,223 @@
+package realtime
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// 事件类型
+const (
+	EventNewMessage      = "new_message"      // 新消息
+	EventMessageRead     = "message_read"     // 已读回执
+	EventMessageRecalled = "message_recalled" // 消息被撤回
+	EventMessageEdited   = "message_edited"   // 消息被编辑
+	EventMessageReaction = "message_reaction" // 消息的表情回应变化
+	EventUnreadCount     = "unread_count"     // 未读数变化
+	EventNotification    = "notification"     // 新通知
+	EventFriendRequest   = "friend_request"   // 好友请求（收到/被处理）
+	EventPostDeleted     = "post_deleted"     // 帖子被删除
+	EventGroupUpdated    = "group_updated"    // 群信息或成员变化
+	EventResync          = "resync"           // 事件历史不完整，客户端需通过接口重新同步
+)
+
+const (
+	historySize = 200              // 每个用户保留的最近事件数，用于断线重连补发
+	historyTTL  = 10 * time.Minute // 用户所有连接断开后，事件历史的保留时间
+	bufferSize  = 64               // 每个订阅的发送队列长度，超出视为慢消费者
+)
+
+// Event 推送给客户端的事件
+type Event struct {
+	ID   int64       `json:"id"`
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+	Time time.Time   `json:"time"`
+}
+
+// Subscription 单个连接（WebSocket 或 SSE）对用户事件流的订阅
+type Subscription struct {
+	userID string
+	events chan *Event
+}
+
+// Events 事件通道，订阅被注销后关闭
+func (s *Subscription) Events() <-chan *Event {
+	return s.events
+}
+
+// userStream 单个用户的订阅集合与最近事件
+type userStream struct {
+	subs      map[*Subscription]struct{}
+	history   []*Event
+	since     int64     // 该ID之后的事件都完整保留在 history 中
+	idleSince time.Time // 最后一个订阅断开的时间
+}
+
+// Hub 进程内连接中心，按用户维护其所有在线设备的订阅
+type Hub struct {
+	mu      sync.Mutex
+	streams map[string]*userStream // userID -> 事件流
+	lastID  int64
+}
+
+var (
+	instance *Hub
+	once     sync.Once
+)
+
+// GetHub 获取单例实例
+func GetHub() *Hub {
+	once.Do(func() {
+		instance = &Hub{
+			streams: make(map[string]*userStream),
+			// 以微秒时间戳作为起点，保证进程重启后事件ID仍然递增
+			lastID: time.Now().UnixNano() / int64(time.Microsecond),
+		}
+	})
+	return instance
+}
+
+// Subscribe 注册订阅，并返回 lastEventID 之后错过的事件
+// complete 为 false 表示历史已不完整（超出保留范围或服务重启），客户端需要通过接口重新同步
+func (h *Hub) Subscribe(userID string, lastEventID int64) (sub *Subscription, missed []*Event, complete bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.cleanup()
+
+	stream, ok := h.streams[userID]
+	if !ok {
+		stream = &userStream{subs: make(map[*Subscription]struct{}), since: h.lastID}
+		h.streams[userID] = stream
+	}
+
+	sub = &Subscription{userID: userID, events: make(chan *Event, bufferSize)}
+	stream.subs[sub] = struct{}{}
+
+	complete = true
+	if lastEventID > 0 {
+		// 只有 since 之后的事件被完整记录
+		complete = lastEventID >= stream.since && lastEventID <= h.lastID
+		for _, e := range stream.history {
+			if e.ID > lastEventID {
+				missed = append(missed, e)
+			}
+		}
+	}
+
+	return sub, missed, complete
+}
+
+// Unsubscribe 注销订阅并关闭其事件通道（可重复调用）
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.unsubscribe(sub)
+}
+
+func (h *Hub) unsubscribe(sub *Subscription) {
+	stream, ok := h.streams[sub.userID]
+	if !ok {
+		return
+	}
+	if _, exists := stream.subs[sub]; exists {
+		delete(stream.subs, sub)
+		close(sub.events)
+		if len(stream.subs) == 0 {
+			stream.idleSince = time.Now()
+		}
+	}
+}
+
+// PushToUser 推送事件给用户的所有在线设备，返回成功投递的订阅数
+// 用户最近有连接时事件会记入历史，以便断线重连后补发
+func (h *Hub) PushToUser(userID, eventType string, data interface{}) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stream, ok := h.streams[userID]
+	if !ok {
+		return 0
+	}
+
+	return h.deliver(stream, h.newEvent(eventType, data))
+}
+
+// PushToUsers 推送事件给多个用户
+func (h *Hub) PushToUsers(userIDs []string, eventType string, data interface{}) {
+	for _, userID := range userIDs {
+		h.PushToUser(userID, eventType, data)
+	}
+}
+
+// Broadcast 推送事件给所有最近在线的用户
+func (h *Hub) Broadcast(eventType string, data interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, stream := range h.streams {
+		h.deliver(stream, h.newEvent(eventType, data))
+	}
+}
+
+// newEvent 分配事件ID（调用方持有锁）
+func (h *Hub) newEvent(eventType string, data interface{}) *Event {
+	h.lastID++
+	return &Event{ID: h.lastID, Type: eventType, Data: data, Time: time.Now()}
+}
+
+// deliver 记录历史并投递给用户的所有订阅（调用方持有锁）
+func (h *Hub) deliver(stream *userStream, event *Event) int {
+	stream.history = append(stream.history, event)
+	if len(stream.history) > historySize {
+		dropped := len(stream.history) - historySize
+		stream.since = stream.history[dropped-1].ID
+		stream.history = stream.history[dropped:]
+	}
+
+	delivered := 0
+	for sub := range stream.subs {
+		select {
+		case sub.events <- event:
+			delivered++
+		default:
+			// 发送队列已满：客户端消费过慢，断开让其携带 Last-Event-ID 重连补发
+			log.Printf("⚠️  用户 %s 的连接发送队列已满，断开连接", sub.userID)
+			h.unsubscribe(sub)
+		}
+	}
+
+	return delivered
+}
+
+// cleanup 清理长时间无连接用户的事件历史（调用方持有锁）
+func (h *Hub) cleanup() {
+	now := time.Now()
+	for userID, stream := range h.streams {
+		if len(stream.subs) == 0 && now.Sub(stream.idleSince) > historyTTL {
+			delete(h.streams, userID)
+		}
+	}
+}
+
+// IsOnline 判断用户是否有在线连接
+func (h *Hub) IsOnline(userID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stream, ok := h.streams[userID]
+	return ok && len(stream.subs) > 0
+}
+
+// GetConnectionCount 获取当前连接总数（用于监控）
+func (h *Hub) GetConnectionCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	count := 0
+	for _, stream := range h.streams {
+		count += len(stream.subs)
+	}
+	return count
+}
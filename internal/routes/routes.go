@@ -27,13 +27,19 @@ func SetupRoutes(router *gin.Engine) {
 	// 主页帖子列表（支持公开和好友帖子）
 	router.GET("/home", handlers.GetHomePage)
 	
-	// 获取公开评论列表
-	router.GET("/public/posts/:id/comments", func(c *gin.Context) {
+	// 获取公开评论列表（登录时返回当前用户的表情回应）
+	router.GET("/public/posts/:id/comments", middleware.OptionalAuthMiddleware(), func(c *gin.Context) {
 		postID := c.Param("id")
 		c.Set("postId", postID)
 		handlers.GetCommentList(c)
 	})
 	
+	// 分页获取一条评论下的全部回复
+	router.GET("/public/comments/:id/replies", middleware.OptionalAuthMiddleware(), handlers.GetCommentReplies)
+	
+	// 可用的表情回应
+	router.GET("/public/reactions", handlers.GetReactionOptions)
+	
 	// 获取标签列表和热门标签
 	router.GET("/public/tags", handlers.GetTagList)
 	router.GET("/public/tags/hot", handlers.GetHotTags)
@@ -46,10 +52,15 @@ func SetupRoutes(router *gin.Engine) {
 	})
 
 	// 公开搜索功能（无需认证）
-	router.GET("/search", handlers.SearchContent)
+	router.GET("/search", middleware.OptionalAuthMiddleware(), handlers.SearchContent)
 	router.GET("/search/hot-words", handlers.GetHotWords)
 	router.GET("/search/suggestions", handlers.GetSearchSuggestions)
 
+	// 实时推送（WebSocket，支持 ?token= 认证）
+	router.GET("/ws", middleware.StreamAuthMiddleware(), handlers.ServeWebSocket)
+	// 实时推送（SSE，EventSource 无法设置请求头，同样支持 ?token= 认证）
+	router.GET("/api/stream", middleware.StreamAuthMiddleware(), handlers.Stream)
+
 		// ========== 需要认证的路由 ==========
 	api := router.Group("/api")
 	api.Use(middleware.AuthMiddleware())
@@ -68,6 +79,12 @@ func SetupRoutes(router *gin.Engine) {
 			admin.DELETE("/users/:userId", handlers.AdminDeleteUser)
 			// 管理员删除帖子
 			admin.DELETE("/posts/:id", handlers.AdminDeleteMoment)
+			// 管理员查看帖子编辑历史
+			admin.GET("/posts/:id/revisions", handlers.AdminGetPostRevisions)
+			admin.GET("/posts/:id/revisions/:version/diff", handlers.AdminGetPostRevisionDiff)
+			// 管理员查看树洞帖子的真实作者（记录审计日志）
+			admin.POST("/posts/:id/reveal-author", handlers.AdminRevealAnonymousAuthor)
+			admin.GET("/anonymous-reveals", handlers.AdminGetAnonymousReveals)
 			// 管理员删除评论
 			admin.DELETE("/comments/:id", handlers.AdminDeleteComment)
 		}
@@ -86,9 +103,30 @@ func SetupRoutes(router *gin.Engine) {
 		posts := api.Group("/posts")
 		{
 			posts.POST("", handlers.CreatePost)
+			posts.POST("/anonymous", handlers.CreateAnonymousPost)
+			posts.GET("/anonymous/my", handlers.GetMyAnonymousPosts)
 			posts.PUT("/:id", handlers.UpdatePost)
 			posts.DELETE("/:id", handlers.DeletePost)
 			posts.GET("/my", handlers.GetUserPosts)
+			posts.GET("/drafts", handlers.GetDraftPosts)
+			posts.GET("/scheduled", handlers.GetScheduledPosts)
+			posts.POST("/:id/publish", handlers.PublishPost)
+			posts.PUT("/:id/schedule", handlers.SchedulePost)
+			posts.POST("/:id/repost", handlers.RepostPost)
+			posts.POST("/:id/poll/vote", handlers.VotePoll)
+			posts.DELETE("/:id/poll/vote", handlers.UnvotePoll)
+			posts.GET("/:id/poll/voters", handlers.GetPollVoters)
+			posts.POST("/:id/bookmark", handlers.AddBookmark)
+			posts.PUT("/:id/bookmark", handlers.MoveBookmark)
+			posts.DELETE("/:id/bookmark", handlers.RemoveBookmark)
+			posts.POST("/:id/reaction", handlers.SetPostReaction)
+			posts.DELETE("/:id/reaction", handlers.RemovePostReaction)
+			posts.PUT("/:id/comment-policy", handlers.SetCommentPolicy)
+			posts.PUT("/:id/pinned-comment", handlers.PinComment)
+			posts.DELETE("/:id/pinned-comment", handlers.UnpinComment)
+			posts.GET("/:id/comments/hidden", handlers.GetHiddenComments)
+			posts.GET("/:id/revisions", handlers.GetPostRevisions)
+			posts.GET("/:id/revisions/:version/diff", handlers.GetPostRevisionDiff)
 			posts.GET("/user/:userId", handlers.GetUserPosts)
 			posts.GET("/:id/likes", handlers.GetPostLikes)
 		}
@@ -100,7 +138,11 @@ func SetupRoutes(router *gin.Engine) {
 			comments.PUT("/:id", handlers.UpdateComment)
 			comments.DELETE("/:id", handlers.DeleteComment)
 			comments.POST("/:id/like", handlers.LikeComment)
+			comments.POST("/:id/reaction", handlers.SetCommentReaction)
+			comments.DELETE("/:id/reaction", handlers.RemoveCommentReaction)
 			comments.POST("/:id/reply", handlers.ReplyComment)
+			comments.POST("/:id/hide", handlers.HideComment)
+			comments.DELETE("/:id/hide", handlers.UnhideComment)
 			comments.GET("/:id/likes", handlers.GetCommentLikes)
 		}
 
@@ -108,11 +150,33 @@ func SetupRoutes(router *gin.Engine) {
 		likes := api.Group("/likes")
 		{
 			likes.POST("/post/:postId", handlers.LikePost)
+			likes.GET("/status", handlers.GetLikeStatus)
 			likes.GET("/posts/:postId", handlers.GetPostLikes)
 			likes.GET("/comments/:commentId", handlers.GetCommentLikes)
 			likes.GET("/users/:userId", handlers.GetUserLikes)
 		}
 
+		// ========== 收藏相关 ==========
+		bookmarks := api.Group("/bookmarks")
+		{
+			bookmarks.GET("", handlers.GetBookmarks)
+			bookmarks.GET("/folders", handlers.GetBookmarkFolders)
+			bookmarks.POST("/folders", handlers.CreateBookmarkFolder)
+			bookmarks.PUT("/folders/:id", handlers.RenameBookmarkFolder)
+			bookmarks.DELETE("/folders/:id", handlers.DeleteBookmarkFolder)
+		}
+
+		// ========== 快拍相关 ==========
+		stories := api.Group("/stories")
+		{
+			stories.POST("", handlers.CreateStory)
+			stories.GET("/tray", handlers.GetStoryTray)
+			stories.GET("/user/:userId", handlers.GetUserStories)
+			stories.POST("/:id/view", handlers.ViewStory)
+			stories.GET("/:id/viewers", handlers.GetStoryViewers)
+			stories.DELETE("/:id", handlers.DeleteStory)
+		}
+
 		// ========== 好友相关 ==========
 		friends := api.Group("/friends")
 		{
@@ -125,12 +189,26 @@ func SetupRoutes(router *gin.Engine) {
 			friends.GET("/search", handlers.SearchFriends)
 		}
 
+		// ========== 黑名单相关 ==========
+		blocks := api.Group("/blocks")
+		{
+			blocks.GET("", handlers.GetBlockList)
+			blocks.POST("/:userId", handlers.BlockUser)
+			blocks.DELETE("/:userId", handlers.UnblockUser)
+		}
+
 		// ========== 消息相关 ==========
 		messages := api.Group("/messages")
 		{
 			messages.POST("", handlers.SendMessage)
+			messages.GET("/sync", handlers.SyncMessages)
 			messages.GET("/:peerId", handlers.GetMessageList)
 			messages.PUT("/:peerId/read", handlers.MarkMessagesAsRead)
+			messages.POST("/:msgId/recall", handlers.RecallMessage)
+			messages.PATCH("/:msgId", handlers.EditMessage)
+			messages.POST("/:msgId/reaction", handlers.SetMessageReaction)
+			messages.DELETE("/:msgId/reaction", handlers.RemoveMessageReaction)
+			messages.GET("/edits/:msgId", handlers.GetMessageEditHistory)
 		}
 
 		// ========== 会话相关 ==========
@@ -145,6 +223,35 @@ func SetupRoutes(router *gin.Engine) {
 			conversations.GET("/unread", handlers.GetUnreadCount)
 		}
 
+		// ========== 群聊相关 ==========
+		groups := api.Group("/groups")
+		{
+			groups.POST("", handlers.CreateGroup)
+			groups.GET("", handlers.GetMyGroups)
+			groups.GET("/:id", handlers.GetGroupDetail)
+			groups.PUT("/:id", handlers.UpdateGroup)
+			groups.POST("/:id/members", handlers.InviteGroupMembers)
+			groups.DELETE("/:id/members/:userId", handlers.RemoveGroupMember)
+			groups.PUT("/:id/admins/:userId", handlers.SetGroupAdmin)
+			groups.DELETE("/:id/admins/:userId", handlers.UnsetGroupAdmin)
+			groups.POST("/:id/leave", handlers.LeaveGroup)
+			groups.GET("/:id/messages", handlers.GetGroupMessages)
+			groups.PUT("/:id/read", handlers.MarkGroupMessagesAsRead)
+			groups.PUT("/:id/pin", handlers.PinGroupConversation)
+			groups.DELETE("/:id/pin", handlers.UnpinGroupConversation)
+			groups.PUT("/:id/mute", handlers.MuteGroupConversation)
+			groups.DELETE("/:id/mute", handlers.UnmuteGroupConversation)
+		}
+
+		// ========== 通知相关 ==========
+		notifications := api.Group("/notifications")
+		{
+			notifications.GET("", handlers.GetNotifications)
+			notifications.GET("/unread-count", handlers.GetNotificationUnreadCount)
+			notifications.PUT("/read-all", handlers.MarkAllNotificationsRead)
+			notifications.PUT("/:id/read", handlers.MarkNotificationRead)
+		}
+
 		// ========== 标签相关（管理功能） ==========
 		tags := api.Group("/tags")
 		{
@@ -164,6 +271,17 @@ func SetupRoutes(router *gin.Engine) {
 			users.POST("/active", handlers.UpdateLastActive)
 			users.GET("/:userId", handlers.GetUserByID)
 			users.GET("/search", handlers.SearchUsers)
+			users.GET("/mention-suggestions", handlers.GetMentionSuggestions)
+			users.POST("/:userId/follow", handlers.FollowUser)
+			users.DELETE("/:userId/follow", handlers.UnfollowUser)
+			users.GET("/:userId/followers", handlers.GetFollowers)
+			users.GET("/:userId/following", handlers.GetFollowing)
+		}
+
+		// ========== 信息流相关 ==========
+		feed := api.Group("/feed")
+		{
+			feed.GET("/following", handlers.GetFollowingFeed)
 		}
 
 		// ========== 搜索相关 ==========
@@ -0,0 +1,109 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// 高亮标记，标记之外的文本已做 HTML 转义，客户端可以直接按 HTML 渲染
+const (
+	HighlightPre  = "<em>"
+	HighlightPost = "</em>"
+	ellipsis      = "…"
+)
+
+// span 一段命中的文本，字符下标，左闭右开
+type span struct {
+	start, end int
+}
+
+// Highlight 截取 text 中命中 query 最集中的最多 maxRunes 个字符，并标出命中的词
+// 没有命中时返回开头的片段，maxRunes <= 0 时不截取
+func Highlight(text, query string, maxRunes int) string {
+	runes := []rune(text)
+	spans := matchSpans(text, query)
+
+	start, end := 0, len(runes)
+	if maxRunes > 0 && len(runes) > maxRunes {
+		start = windowStart(spans, len(runes), maxRunes)
+		end = start + maxRunes
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(ellipsis)
+	}
+	pos := start
+	for _, s := range spans {
+		if s.end <= start || s.start >= end {
+			continue
+		}
+		from, to := max(s.start, pos), min(s.end, end)
+		b.WriteString(html.EscapeString(string(runes[pos:from])))
+		b.WriteString(HighlightPre)
+		b.WriteString(html.EscapeString(string(runes[from:to])))
+		b.WriteString(HighlightPost)
+		pos = to
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		b.WriteString(ellipsis)
+	}
+	return b.String()
+}
+
+// Matches 文本是否命中查询中的任意一个词
+func Matches(text, query string) bool {
+	return len(matchSpans(text, query)) > 0
+}
+
+// matchSpans 找出文本中命中查询词的位置，重叠或相邻的位置合并为一段
+func matchSpans(text, query string) []span {
+	terms := make(map[string]bool)
+	for _, t := range QueryTerms(query) {
+		terms[t] = true
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var spans []span
+	for _, tok := range Tokenize(text) {
+		if !terms[tok.Term] {
+			continue
+		}
+		if n := len(spans); n > 0 && tok.Start <= spans[n-1].end {
+			spans[n-1].end = max(spans[n-1].end, tok.End)
+			continue
+		}
+		spans = append(spans, span{tok.Start, tok.End})
+	}
+	return spans
+}
+
+// windowStart 选出包含命中段最多的窗口起点，窗口前保留少量上下文
+func windowStart(spans []span, total, size int) int {
+	if len(spans) == 0 {
+		return 0
+	}
+
+	context := size / 5
+	best, bestCount := 0, -1
+	for i, s := range spans {
+		start := max(s.start-context, 0)
+		if start+size > total {
+			start = total - size
+		}
+		count := 0
+		for _, t := range spans[i:] {
+			if t.end > start+size {
+				break
+			}
+			count++
+		}
+		if count > bestCount {
+			best, bestCount = start, count
+		}
+	}
+	return best
+}
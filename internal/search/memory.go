@@ -0,0 +1,178 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// BM25 参数：k1 控制词频饱和速度，b 控制文档长度归一化的程度
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// memoryDoc 一个文档的加权词频和加权长度
+type memoryDoc struct {
+	terms  map[string]float64
+	length float64
+}
+
+// memoryKind 一类文档的倒排索引
+type memoryKind struct {
+	docs     map[string]*memoryDoc
+	postings map[string]map[string]float64 // 词 -> 文档ID -> 加权词频
+	totalLen float64
+}
+
+// MemoryEngine 进程内倒排索引，按 BM25 打分，多实例部署时各实例独立维护，重启后由调用方重建
+type MemoryEngine struct {
+	mu    sync.RWMutex
+	kinds map[string]*memoryKind
+}
+
+// NewMemoryEngine 创建进程内检索引擎
+func NewMemoryEngine() *MemoryEngine {
+	return &MemoryEngine{kinds: make(map[string]*memoryKind)}
+}
+
+// Index 写入文档，各字段的词频按字段权重累加
+func (e *MemoryEngine) Index(docs ...Document) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, doc := range docs {
+		k := e.kind(doc.Kind)
+		k.remove(doc.ID)
+
+		d := &memoryDoc{terms: make(map[string]float64)}
+		for _, f := range doc.Fields {
+			for _, tok := range Tokenize(f.Text) {
+				d.terms[tok.Term] += f.Weight
+				d.length += f.Weight
+			}
+		}
+		if len(d.terms) == 0 {
+			continue
+		}
+
+		k.docs[doc.ID] = d
+		k.totalLen += d.length
+		for term, tf := range d.terms {
+			if k.postings[term] == nil {
+				k.postings[term] = make(map[string]float64)
+			}
+			k.postings[term][doc.ID] = tf
+		}
+	}
+	return nil
+}
+
+// Delete 删除文档
+func (e *MemoryEngine) Delete(kind string, ids ...string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	k, ok := e.kinds[kind]
+	if !ok {
+		return nil
+	}
+	for _, id := range ids {
+		k.remove(id)
+	}
+	return nil
+}
+
+// Search 按 BM25 打分，文档至少要命中四分之三的查询词（向下取整，至少一个），避免中文长查询命中大量只含其中一两个字的文档
+func (e *MemoryEngine) Search(kind, query string, limit int) ([]Hit, error) {
+	terms := QueryTerms(query)
+	if len(terms) == 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	k, ok := e.kinds[kind]
+	if !ok || len(k.docs) == 0 {
+		return nil, nil
+	}
+
+	n := float64(len(k.docs))
+	avgLen := k.totalLen / n
+	scores := make(map[string]float64)
+	matched := make(map[string]int)
+	for _, term := range terms {
+		posting := k.postings[term]
+		if len(posting) == 0 {
+			continue
+		}
+		df := float64(len(posting))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range posting {
+			norm := 1 - bm25B + bm25B*k.docs[id].length/avgLen
+			scores[id] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+			matched[id]++
+		}
+	}
+
+	minMatch := len(terms) * 3 / 4
+	if minMatch < 1 {
+		minMatch = 1
+	}
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		if matched[id] >= minMatch {
+			hits = append(hits, Hit{ID: id, Score: score})
+		}
+	}
+
+	// 分数相同时较新的文档（ID 较大）排在前面，保证翻页时顺序稳定
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return idLess(hits[j].ID, hits[i].ID)
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// kind 获取一类文档的索引，不存在时创建
+func (e *MemoryEngine) kind(kind string) *memoryKind {
+	k, ok := e.kinds[kind]
+	if !ok {
+		k = &memoryKind{
+			docs:     make(map[string]*memoryDoc),
+			postings: make(map[string]map[string]float64),
+		}
+		e.kinds[kind] = k
+	}
+	return k
+}
+
+// remove 从倒排索引中移除文档
+func (k *memoryKind) remove(id string) {
+	d, ok := k.docs[id]
+	if !ok {
+		return
+	}
+	for term := range d.terms {
+		delete(k.postings[term], id)
+		if len(k.postings[term]) == 0 {
+			delete(k.postings, term)
+		}
+	}
+	k.totalLen -= d.length
+	delete(k.docs, id)
+}
+
+// idLess 比较文档ID，数字ID按数值比较
+func idLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
@@ -0,0 +1,60 @@
+package search
+
+import "sync"
+
+// 索引中的文档类型
+const (
+	KindPost = "post"
+	KindUser = "user"
+	KindTag  = "tag"
+)
+
+// Field 文档中的一个字段，Weight 为字段在相关度中的权重（如标题高于正文）
+type Field struct {
+	Name   string
+	Text   string
+	Weight float64
+}
+
+// Document 一个待索引的文档，同一 Kind 下按 ID 唯一
+type Document struct {
+	Kind   string
+	ID     string
+	Fields []Field
+}
+
+// Hit 一条命中的文档，Score 越大越相关
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// Engine 全文检索引擎，索引只保存可检索的文本，可见性等过滤由调用方按数据库中的最新状态处理
+type Engine interface {
+	// Index 写入文档，已存在的同 ID 文档会被替换
+	Index(docs ...Document) error
+	// Delete 删除文档，不存在的 ID 会被忽略
+	Delete(kind string, ids ...string) error
+	// Search 按相关度倒序返回最多 limit 条命中
+	Search(kind, query string, limit int) ([]Hit, error)
+}
+
+var (
+	instance Engine
+	once     sync.Once
+)
+
+// GetEngine 获取检索引擎，默认使用进程内实现
+func GetEngine() Engine {
+	once.Do(func() {
+		if instance == nil {
+			instance = NewMemoryEngine()
+		}
+	})
+	return instance
+}
+
+// SetEngine 替换检索引擎（如 Elasticsearch 实现），需在首次调用 GetEngine 前设置
+func SetEngine(engine Engine) {
+	instance = engine
+}
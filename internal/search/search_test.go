@@ -0,0 +1,76 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/Yw332/campus-moments-go/internal/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTokenize(t *testing.T) {
+	var terms []string
+	for _, tok := range search.Tokenize("期末考试 Go语言") {
+		terms = append(terms, tok.Term)
+	}
+	// 中文同时切出单字和相邻两字，字母数字按词切分并转为小写
+	assert.Equal(t, []string{"期", "期末", "末", "末考", "考", "考试", "试", "go", "语", "语言", "言"}, terms)
+
+	assert.Equal(t, []string{"期末", "末考", "考试"}, search.QueryTerms("期末考试"))
+	assert.Equal(t, []string{"考"}, search.QueryTerms("考"))
+	assert.Equal(t, []string{"golang", "考研"}, search.QueryTerms("Golang, 考研 golang"))
+}
+
+func TestMemoryEngineRanking(t *testing.T) {
+	engine := search.NewMemoryEngine()
+	doc := func(id, title, content string) search.Document {
+		return search.Document{Kind: search.KindPost, ID: id, Fields: []search.Field{
+			{Name: "title", Text: title, Weight: 3},
+			{Name: "content", Text: content, Weight: 1},
+		}}
+	}
+	assert.NoError(t, engine.Index(
+		doc("1", "食堂新菜", "今天二食堂出了新菜，味道不错"),
+		doc("2", "期末考试安排", "期末考试将在下周开始，请同学们做好准备"),
+		doc("3", "图书馆", "期末复习去图书馆占座"),
+		doc("4", "社团招新", "考试周之后社团开始招新"),
+	))
+
+	hits, err := engine.Search(search.KindPost, "期末考试", 10)
+	assert.NoError(t, err)
+	// 标题和正文都命中的排在前面，只命中一个查询词的文档不返回
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, "2", hits[0].ID)
+	}
+
+	hits, err = engine.Search(search.KindPost, "期末", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 2) {
+		assert.Equal(t, "2", hits[0].ID)
+		assert.Equal(t, "3", hits[1].ID)
+	}
+
+	// 替换和删除文档后索引随之更新
+	assert.NoError(t, engine.Index(doc("3", "图书馆", "周末去图书馆")))
+	assert.NoError(t, engine.Delete(search.KindPost, "2"))
+	hits, err = engine.Search(search.KindPost, "期末", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+
+	hits, err = engine.Search(search.KindUser, "期末", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+}
+
+func TestSearchHighlight(t *testing.T) {
+	assert.Equal(t, "<em>期末考试</em>安排", search.Highlight("期末考试安排", "期末考试", 0))
+	assert.Equal(t, "学习 <em>Go</em> &amp; <em>go</em>", search.Highlight("学习 Go & go", "GO", 0))
+
+	text := "开头的一大段内容与查询无关，这里才提到期末考试的时间安排，后面还有很多别的内容"
+	snippet := search.Highlight(text, "期末考试", 16)
+	assert.Contains(t, snippet, "<em>期末考试</em>")
+	assert.True(t, len([]rune(snippet)) <= 16+2+len(search.HighlightPre)+len(search.HighlightPost))
+
+	// 没有命中时返回开头的片段
+	assert.Equal(t, "开头的一大段…", search.Highlight(text, "食堂", 6))
+	assert.False(t, search.Matches(text, "食堂"))
+}
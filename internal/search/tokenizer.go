@@ -0,0 +1,97 @@
+package search
+
+import "unicode"
+
+// Token 分词结果，Start、End 为词在原文中的字符（rune）下标，左闭右开
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// isCJK 中日韩文字，这些文字没有空格分词，按单字和相邻两字切分
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+// isWordRune 非中日韩的字母和数字，连续的字母数字组成一个词
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isCJK(r)
+}
+
+// Tokenize 对索引文本分词：字母数字按词切分并转为小写，中日韩文字同时输出单字和相邻两字（bigram）
+// 单字保证一个字的查询也能命中，两字保证多字查询的精确度
+func Tokenize(text string) []Token {
+	var tokens []Token
+	scan(text, func(runes []rune, start, end int, cjk bool) {
+		if !cjk {
+			tokens = append(tokens, Token{Term: lowerTerm(runes[start:end]), Start: start, End: end})
+			return
+		}
+		for i := start; i < end; i++ {
+			tokens = append(tokens, Token{Term: string(runes[i]), Start: i, End: i + 1})
+			if i+1 < end {
+				tokens = append(tokens, Token{Term: string(runes[i : i+2]), Start: i, End: i + 2})
+			}
+		}
+	})
+	return tokens
+}
+
+// QueryTerms 对查询分词并去重：中日韩文字只有一个字时用单字，否则只用相邻两字
+func QueryTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	add := func(term string) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	scan(query, func(runes []rune, start, end int, cjk bool) {
+		switch {
+		case !cjk:
+			add(lowerTerm(runes[start:end]))
+		case end-start == 1:
+			add(string(runes[start]))
+		default:
+			for i := start; i+1 < end; i++ {
+				add(string(runes[i : i+2]))
+			}
+		}
+	})
+	return terms
+}
+
+// scan 把文本切分为连续的字母数字段和中日韩文字段，其余字符作为分隔符
+func scan(text string, emit func(runes []rune, start, end int, cjk bool)) {
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if !isCJK(r) && !isWordRune(r) {
+			i++
+			continue
+		}
+
+		cjk := isCJK(r)
+		j := i + 1
+		for j < len(runes) && isCJK(runes[j]) == cjk && (cjk || isWordRune(runes[j])) {
+			j++
+		}
+		emit(runes, i, j, cjk)
+		i = j
+	}
+}
+
+// lowerTerm 字母统一转为小写
+func lowerTerm(runes []rune) string {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	return string(lower)
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotAnonymousPost = errors.New("该帖子不是树洞帖子")
+)
+
+// 化名由形容词和动物组合，头像与动物对应，存放在 uploads/anonymous 目录
+var (
+	pseudonymAdjectives = []string{"安静的", "迷路的", "勇敢的", "害羞的", "好奇的", "失眠的", "快乐的", "认真的", "路过的", "发呆的", "温柔的", "倔强的"}
+	pseudonymAnimals    = []string{"小熊", "狐狸", "松鼠", "海豚", "企鹅", "兔子", "猫头鹰", "刺猬", "鲸鱼", "小鹿", "水獭", "熊猫"}
+)
+
+// newPseudonym 随机生成树洞帖子的化名和头像
+func newPseudonym() (string, string) {
+	animal := rand.Intn(len(pseudonymAnimals))
+	name := pseudonymAdjectives[rand.Intn(len(pseudonymAdjectives))] + pseudonymAnimals[animal]
+	return name, fmt.Sprintf("/static/anonymous/%02d.png", animal+1)
+}
+
+// CreateAnonymousPost 发布树洞帖子：帖子作者记为占位ID，真实作者单独记录
+// 树洞帖子始终公开，不计入发帖数，不出现在个人主页，也不受拉黑关系限制（否则会暴露作者）
+func CreateAnonymousPost(userID, title, content string, images []string, video string, tags []string) (*models.Post, error) {
+	now := time.Now()
+	post := &models.Post{
+		UserID:     models.AnonymousUserID,
+		Title:      title,
+		Content:    content,
+		Video:      video,
+		Visibility: 0,
+		Status:     models.PostStatusNormal,
+		Anonymous:  true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if len(images) > 0 {
+		post.Images, _ = json.Marshal(images)
+	}
+	if len(tags) > 0 {
+		post.Tags, _ = json.Marshal(tags)
+	}
+
+	pseudonym, avatar := newPseudonym()
+	err := getDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AnonymousAuthor{
+			PostID:    post.ID,
+			AuthorID:  userID,
+			Pseudonym: pseudonym,
+			AvatarURL: avatar,
+			CreatedAt: now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	post.User = pseudonymUser(pseudonym, avatar)
+	onPostPublished(post)
+
+	return post, nil
+}
+
+// GetMyAnonymousPosts 获取当前用户发布的树洞帖子，只有作者本人能看到这个列表
+func GetMyAnonymousPosts(userID string, opts PageOptions) ([]models.Post, PageInfo, error) {
+	opts = normalizePage(opts)
+	ownPosts := getDB().Model(&models.AnonymousAuthor{}).Select("post_id").Where("author_id = ?", userID)
+	query := getDB().Model(&models.Post{}).Where("id IN (?) AND status = ?", ownPosts, models.PostStatusNormal)
+	return findPosts(query, opts, userID)
+}
+
+// pseudonymUser 生成展示用的化名用户
+func pseudonymUser(pseudonym, avatar string) *models.User {
+	return &models.User{ID: models.AnonymousUserID, Username: pseudonym, AvatarURL: avatar}
+}
+
+// anonymousUsers 批量获取树洞帖子的化名用户，key 为帖子ID
+func anonymousUsers(postIDs []int64) map[int64]*models.User {
+	result := make(map[int64]*models.User, len(postIDs))
+	if len(postIDs) == 0 {
+		return result
+	}
+
+	var authors []models.AnonymousAuthor
+	getDB().Where("post_id IN ?", postIDs).Find(&authors)
+	for _, a := range authors {
+		result[a.PostID] = pseudonymUser(a.Pseudonym, a.AvatarURL)
+	}
+	return result
+}
+
+// anonymousAuthorID 获取树洞帖子的真实作者，仅供服务内部路由通知和校验权限，不能返回给客户端
+func anonymousAuthorID(postID int64) string {
+	var author models.AnonymousAuthor
+	if err := getDB().Select("author_id").First(&author, "post_id = ?", postID).Error; err != nil {
+		return ""
+	}
+	return author.AuthorID
+}
+
+// commentAuthorID 楼主在自己的树洞帖子下评论时使用占位ID，使评论与帖子显示同一个化名
+func commentAuthorID(post *models.Moment, userID string) string {
+	if post.Anonymous && anonymousAuthorID(int64(post.ID)) == userID {
+		return models.AnonymousUserID
+	}
+	return userID
+}
+
+// whereOwnComment 限定为当前用户的评论，包括以化名发表在自己树洞帖子下的评论
+func whereOwnComment(query *gorm.DB, userID string) *gorm.DB {
+	ownPosts := getDB().Model(&models.AnonymousAuthor{}).Select("post_id").Where("author_id = ?", userID)
+	return query.Where("(user_id = ? OR (user_id = ? AND post_id IN (?)))", userID, models.AnonymousUserID, ownPosts)
+}
+
+// whereOwnPost 限定为当前用户的帖子，包括自己发布的树洞帖子
+func whereOwnPost(query *gorm.DB, userID string) *gorm.DB {
+	ownPosts := getDB().Model(&models.AnonymousAuthor{}).Select("post_id").Where("author_id = ?", userID)
+	return query.Where("(user_id = ? OR id IN (?))", userID, ownPosts)
+}
+
+// attachCommentUsers 为评论批量填充作者和被回复的用户，楼主在树洞帖子下的评论显示帖子的化名
+func attachCommentUsers(comments []models.Comment) {
+	userIDSet := make(map[string]bool, len(comments))
+	var anonymousPostIDs []int64
+	for _, c := range comments {
+		for _, id := range []string{c.UserID, c.ReplyToUserID} {
+			if id == models.AnonymousUserID {
+				anonymousPostIDs = append(anonymousPostIDs, c.PostID)
+			} else if id != "" {
+				userIDSet[id] = true
+			}
+		}
+	}
+	users := loadUsers(userIDSet)
+	pseudonyms := anonymousUsers(anonymousPostIDs)
+	lookup := func(id string, postID int64) *models.User {
+		if id == models.AnonymousUserID {
+			return pseudonyms[postID]
+		}
+		return users[id]
+	}
+	for i := range comments {
+		comments[i].User = lookup(comments[i].UserID, comments[i].PostID)
+		if comments[i].ReplyToUserID != "" {
+			comments[i].ReplyToUser = lookup(comments[i].ReplyToUserID, comments[i].PostID)
+		}
+	}
+}
+
+// RevealAnonymousAuthor 管理员查看树洞帖子的真实作者，每次查看都会记录审计日志
+func RevealAnonymousAuthor(adminID string, postID int64, reason string) (*models.AnonymousReveal, error) {
+	var reveal models.AnonymousReveal
+	err := getDB().Transaction(func(tx *gorm.DB) error {
+		var author models.AnonymousAuthor
+		err := tx.First(&author, "post_id = ?", postID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			var count int64
+			tx.Model(&models.Post{}).Where("id = ?", postID).Count(&count)
+			if count > 0 {
+				return ErrNotAnonymousPost
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		reveal = models.AnonymousReveal{
+			PostID:    postID,
+			AdminID:   adminID,
+			AuthorID:  author.AuthorID,
+			Reason:    reason,
+			CreatedAt: time.Now(),
+		}
+		return tx.Create(&reveal).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reveals := []models.AnonymousReveal{reveal}
+	attachRevealUsers(reveals)
+	return &reveals[0], nil
+}
+
+// GetAnonymousReveals 获取揭示真实作者的审计日志，postID 为0时返回全部，按时间倒序
+func GetAnonymousReveals(postID int64, opts PageOptions) ([]models.AnonymousReveal, PageInfo, error) {
+	opts = normalizePage(opts)
+	query := getDB().Model(&models.AnonymousReveal{})
+	if postID > 0 {
+		query = query.Where("post_id = ?", postID)
+	}
+
+	query, info, err := paginate(query, opts, true)
+	if err != nil {
+		return nil, info, err
+	}
+
+	var reveals []models.AnonymousReveal
+	if err := query.Find(&reveals).Error; err != nil {
+		return nil, info, err
+	}
+	reveals = finishPage(reveals, opts, &info, func(r models.AnonymousReveal) string {
+		return encodeCursor(r.CreatedAt, r.ID)
+	})
+	attachRevealUsers(reveals)
+
+	return reveals, info, nil
+}
+
+// attachRevealUsers 为审计日志填充管理员和真实作者
+func attachRevealUsers(reveals []models.AnonymousReveal) {
+	userIDSet := make(map[string]bool, len(reveals)*2)
+	for _, r := range reveals {
+		userIDSet[r.AdminID] = true
+		userIDSet[r.AuthorID] = true
+	}
+	users := loadUsers(userIDSet)
+	for i := range reveals {
+		reveals[i].Admin = users[reveals[i].AdminID]
+		reveals[i].Author = users[reveals[i].AuthorID]
+	}
+}
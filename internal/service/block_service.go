@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"gorm.io/gorm"
+)
+
+// 好友关系类型
+const (
+	relationFriend = 1 // 好友
+	relationBlock  = 2 // 黑名单
+)
+
+var (
+	ErrUserBlocked     = errors.New("由于对方的隐私设置，无法进行此操作")
+	ErrCannotBlockSelf = errors.New("不能拉黑自己")
+)
+
+// BlockUser 拉黑用户：同时解除双方好友和关注关系，并拒绝双方之间待处理的好友请求
+func BlockUser(userID, targetID string) error {
+	if userID == targetID {
+		return ErrCannotBlockSelf
+	}
+
+	var target models.User
+	if err := getDB().Select("id").First(&target, "id = ?", targetID).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err := getDB().Transaction(func(tx *gorm.DB) error {
+		var relation models.FriendRelation
+		err := tx.Where("user_id = ? AND friend_id = ? AND relation_type = ?", userID, targetID, relationBlock).
+			First(&relation).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			relation = models.FriendRelation{
+				UserID:       userID,
+				FriendID:     targetID,
+				RelationType: relationBlock,
+				Status:       0,
+				CreatedAt:    now,
+				UpdatedAt:    now,
+			}
+			if err := tx.Create(&relation).Error; err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		} else if err := tx.Model(&relation).Updates(map[string]interface{}{
+			"status":     0,
+			"updated_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		// 解除双方关注关系
+		if err := removeFollow(tx, userID, targetID); err != nil {
+			return err
+		}
+		if err := removeFollow(tx, targetID, userID); err != nil {
+			return err
+		}
+
+		// 解除双方好友关系
+		if err := tx.Model(&models.FriendRelation{}).
+			Where("((user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)) AND relation_type = ? AND status = 0",
+				userID, targetID, targetID, userID, relationFriend).
+			Updates(map[string]interface{}{"status": 1, "updated_at": now}).Error; err != nil {
+			return err
+		}
+
+		// 拒绝双方之间待处理的好友请求
+		return tx.Model(&models.FriendRequest{}).
+			Where("((from_user_id = ? AND to_user_id = ?) OR (from_user_id = ? AND to_user_id = ?)) AND status = 0",
+				userID, targetID, targetID, userID).
+			Updates(map[string]interface{}{"status": 2, "updated_at": now}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// 好友关系可能被解除，双方的首页时间线需要重新回填
+	evictTimelines(userID, targetID)
+	return nil
+}
+
+// UnblockUser 取消拉黑（不会恢复好友关系）
+func UnblockUser(userID, targetID string) error {
+	result := getDB().Model(&models.FriendRelation{}).
+		Where("user_id = ? AND friend_id = ? AND relation_type = ? AND status = 0", userID, targetID, relationBlock).
+		Updates(map[string]interface{}{"status": 1, "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetBlockList 获取我拉黑的用户列表
+func GetBlockList(userID string, page, pageSize int) ([]models.FriendRelation, int64, error) {
+	var relations []models.FriendRelation
+	var total int64
+
+	query := getDB().Model(&models.FriendRelation{}).
+		Where("user_id = ? AND relation_type = ? AND status = 0", userID, relationBlock)
+
+	query.Count(&total)
+
+	if err := query.Order("updated_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&relations).Error; err != nil {
+		return nil, 0, err
+	}
+
+	userIDSet := make(map[string]bool, len(relations))
+	for _, r := range relations {
+		userIDSet[r.FriendID] = true
+	}
+	users := loadUsers(userIDSet)
+	for i := range relations {
+		relations[i].Friend = users[relations[i].FriendID]
+	}
+
+	return relations, total, nil
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBookmarkFolderName     = errors.New("收藏夹名称不能为空，且不超过30个字")
+	ErrBookmarkFolderExists   = errors.New("已有同名收藏夹")
+	ErrBookmarkFolderLimit    = errors.New("收藏夹数量已达上限")
+	ErrBookmarkFolderNotFound = errors.New("收藏夹不存在")
+	ErrBookmarkNotFound       = errors.New("没有收藏该帖子")
+)
+
+const (
+	maxBookmarkFolders       = 50
+	maxBookmarkFolderNameLen = 30
+)
+
+// AddBookmark 收藏帖子，folderID 为0时不放入收藏夹；重复收藏直接返回已有的收藏
+func AddBookmark(userID string, postID, folderID int64) (*models.Bookmark, error) {
+	if _, err := findVisiblePost(userID, postID); err != nil {
+		return nil, err
+	}
+	if err := checkBookmarkFolder(userID, folderID); err != nil {
+		return nil, err
+	}
+
+	bookmark := &models.Bookmark{
+		UserID:    userID,
+		PostID:    postID,
+		FolderID:  folderID,
+		CreatedAt: time.Now(),
+	}
+	err := getDB().Create(bookmark).Error
+	if isDuplicateKeyError(err) {
+		err = getDB().First(bookmark, "user_id = ? AND post_id = ?", userID, postID).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bookmark, nil
+}
+
+// RemoveBookmark 取消收藏，帖子已删除或不可见时也可以取消
+func RemoveBookmark(userID string, postID int64) error {
+	return getDB().Where("user_id = ? AND post_id = ?", userID, postID).Delete(&models.Bookmark{}).Error
+}
+
+// MoveBookmark 把收藏移动到另一个收藏夹，folderID 为0时移出收藏夹
+func MoveBookmark(userID string, postID, folderID int64) (*models.Bookmark, error) {
+	if err := checkBookmarkFolder(userID, folderID); err != nil {
+		return nil, err
+	}
+
+	var bookmark models.Bookmark
+	err := getDB().First(&bookmark, "user_id = ? AND post_id = ?", userID, postID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBookmarkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := getDB().Model(&bookmark).Update("folder_id", folderID).Error; err != nil {
+		return nil, err
+	}
+	return &bookmark, nil
+}
+
+// checkBookmarkFolder 检查收藏夹属于当前用户，0 表示未分类
+func checkBookmarkFolder(userID string, folderID int64) error {
+	if folderID == 0 {
+		return nil
+	}
+	var count int64
+	if err := getDB().Model(&models.BookmarkFolder{}).
+		Where("id = ? AND user_id = ?", folderID, userID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrBookmarkFolderNotFound
+	}
+	return nil
+}
+
+// GetBookmarks 获取收藏列表，按收藏时间倒序；folderID 为 nil 时返回全部收藏，为0时只返回未分类的收藏
+// 帖子已删除或当前用户已无权查看时仍保留该收藏，只返回墓碑，方便用户自行清理
+func GetBookmarks(userID string, folderID *int64, opts PageOptions) ([]models.Bookmark, PageInfo, error) {
+	opts = normalizePage(opts)
+	query := getDB().Model(&models.Bookmark{}).Where("user_id = ?", userID)
+	if folderID != nil {
+		query = query.Where("folder_id = ?", *folderID)
+	}
+
+	query, info, err := paginate(query, opts, true)
+	if err != nil {
+		return nil, info, err
+	}
+
+	var bookmarks []models.Bookmark
+	if err := query.Find(&bookmarks).Error; err != nil {
+		return nil, info, err
+	}
+	bookmarks = finishPage(bookmarks, opts, &info, func(b models.Bookmark) string {
+		return encodeCursor(b.CreatedAt, b.ID)
+	})
+	attachBookmarkPosts(bookmarks, userID)
+
+	return bookmarks, info, nil
+}
+
+// attachBookmarkPosts 为收藏填充帖子，帖子已删除或不可见时填充墓碑
+func attachBookmarkPosts(bookmarks []models.Bookmark, userID string) {
+	if len(bookmarks) == 0 {
+		return
+	}
+
+	ids := make([]int64, len(bookmarks))
+	for i, b := range bookmarks {
+		ids[i] = b.PostID
+	}
+	var found []models.Post
+	getDB().Where("id IN ?", ids).Find(&found)
+
+	rel := LoadViewerRelations(userID)
+
+	var visible []models.Post
+	tombstones := make(map[int64]string)
+	for i := range found {
+		switch {
+		case found[i].Status != models.PostStatusNormal:
+			tombstones[found[i].ID] = models.RepostTombstoneDeleted
+		case !CanViewPost(userID, found[i].UserID, found[i].Visibility, rel):
+			tombstones[found[i].ID] = models.RepostTombstoneUnavailable
+		default:
+			visible = append(visible, found[i])
+		}
+	}
+
+	attachPostUsers(visible)
+	attachPostMentions(visible)
+	attachReposts(visible, userID)
+	attachPolls(visible, userID)
+	attachPostReactions(visible, userID)
+	attachPostFriendLikes(visible, userID)
+
+	postMap := make(map[int64]*models.Post, len(visible))
+	for i := range visible {
+		visible[i].IsBookmarked = true
+		postMap[visible[i].ID] = &visible[i]
+	}
+
+	for i := range bookmarks {
+		if post, ok := postMap[bookmarks[i].PostID]; ok {
+			bookmarks[i].Post = post
+		} else if tombstone, ok := tombstones[bookmarks[i].PostID]; ok {
+			bookmarks[i].Tombstone = tombstone
+		} else {
+			bookmarks[i].Tombstone = models.RepostTombstoneDeleted
+		}
+	}
+}
+
+// attachBookmarks 为帖子填充当前用户是否已收藏
+func attachBookmarks(posts []models.Post, userID string) {
+	if userID == "" || len(posts) == 0 {
+		return
+	}
+
+	ids := make([]int64, len(posts))
+	for i, p := range posts {
+		ids[i] = p.ID
+	}
+
+	var bookmarked []int64
+	getDB().Model(&models.Bookmark{}).
+		Where("user_id = ? AND post_id IN ?", userID, ids).
+		Pluck("post_id", &bookmarked)
+
+	set := make(map[int64]bool, len(bookmarked))
+	for _, id := range bookmarked {
+		set[id] = true
+	}
+	for i := range posts {
+		posts[i].IsBookmarked = set[posts[i].ID]
+	}
+}
+
+// GetBookmarkFolders 获取当前用户的收藏夹及每个收藏夹的收藏数，按创建时间正序
+func GetBookmarkFolders(userID string) ([]models.BookmarkFolder, error) {
+	var folders []models.BookmarkFolder
+	if err := getDB().Where("user_id = ?", userID).Order("created_at ASC, id ASC").Find(&folders).Error; err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		FolderID int64
+		Count    int64
+	}
+	getDB().Model(&models.Bookmark{}).
+		Select("folder_id, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("folder_id").
+		Scan(&counts)
+
+	countMap := make(map[int64]int64, len(counts))
+	for _, c := range counts {
+		countMap[c.FolderID] = c.Count
+	}
+	for i := range folders {
+		folders[i].BookmarkCount = countMap[folders[i].ID]
+	}
+
+	return folders, nil
+}
+
+// CreateBookmarkFolder 创建收藏夹
+func CreateBookmarkFolder(userID, name string) (*models.BookmarkFolder, error) {
+	name, err := normalizeFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	getDB().Model(&models.BookmarkFolder{}).Where("user_id = ?", userID).Count(&count)
+	if count >= maxBookmarkFolders {
+		return nil, ErrBookmarkFolderLimit
+	}
+
+	now := time.Now()
+	folder := &models.BookmarkFolder{UserID: userID, Name: name, CreatedAt: now, UpdatedAt: now}
+	if err := getDB().Create(folder).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, ErrBookmarkFolderExists
+		}
+		return nil, err
+	}
+	return folder, nil
+}
+
+// RenameBookmarkFolder 重命名收藏夹
+func RenameBookmarkFolder(userID string, folderID int64, name string) (*models.BookmarkFolder, error) {
+	name, err := normalizeFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var folder models.BookmarkFolder
+	err = getDB().First(&folder, "id = ? AND user_id = ?", folderID, userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBookmarkFolderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	folder.Name = name
+	folder.UpdatedAt = time.Now()
+	if err := getDB().Model(&folder).Select("name", "updated_at").Updates(&folder).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, ErrBookmarkFolderExists
+		}
+		return nil, err
+	}
+	return &folder, nil
+}
+
+// DeleteBookmarkFolder 删除收藏夹，其中的收藏移回未分类，不会被取消
+func DeleteBookmarkFolder(userID string, folderID int64) error {
+	return getDB().Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", folderID, userID).Delete(&models.BookmarkFolder{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrBookmarkFolderNotFound
+		}
+		return tx.Model(&models.Bookmark{}).
+			Where("user_id = ? AND folder_id = ?", userID, folderID).
+			Update("folder_id", 0).Error
+	})
+}
+
+// normalizeFolderName 去掉首尾空白并校验收藏夹名称
+func normalizeFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxBookmarkFolderNameLen {
+		return "", ErrBookmarkFolderName
+	}
+	return name, nil
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCommentsClosed         = errors.New("作者已关闭评论")
+	ErrCommentsFriendsOnly    = errors.New("作者设置了仅好友可评论")
+	ErrInvalidCommentPolicy   = errors.New("无效的评论权限")
+	ErrAnonymousFriendsPolicy = errors.New("树洞帖子不能设置仅好友评论")
+	ErrNotPostAuthor          = errors.New("只有帖子作者可以管理该评论")
+	ErrCommentNotPinnable     = errors.New("只能置顶该帖子下未删除的一级评论")
+)
+
+// checkCommentPolicy 检查当前用户能否在帖子下评论，帖子作者不受限制
+func checkCommentPolicy(post *models.Moment, userID string) error {
+	authorID := post.UserID
+	if post.Anonymous {
+		authorID = anonymousAuthorID(int64(post.ID))
+	}
+	if userID == authorID {
+		return nil
+	}
+
+	switch post.CommentPolicy {
+	case models.CommentPolicyClosed:
+		return ErrCommentsClosed
+	case models.CommentPolicyFriends:
+		if !IsFriend(userID, authorID) {
+			return ErrCommentsFriendsOnly
+		}
+	}
+	return nil
+}
+
+// findOwnMoment 获取当前用户自己的正常状态帖子，包括树洞帖子
+func findOwnMoment(postID int64, userID string) (*models.Moment, error) {
+	var post models.Moment
+	if err := whereOwnPost(getDB(), userID).
+		First(&post, "id = ? AND status = ?", postID, models.PostStatusNormal).Error; err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// isPostAuthor 判断当前用户是否为帖子作者，包括树洞帖子
+func isPostAuthor(postID int64, userID string) bool {
+	var count int64
+	whereOwnPost(getDB().Model(&models.Moment{}), userID).Where("id = ?", postID).Count(&count)
+	return count > 0
+}
+
+// SetCommentPolicy 帖子作者设置评论权限
+func SetCommentPolicy(postID int64, userID string, policy int) (*models.Moment, error) {
+	if policy < models.CommentPolicyEveryone || policy > models.CommentPolicyClosed {
+		return nil, ErrInvalidCommentPolicy
+	}
+
+	post, err := findOwnMoment(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+	// 仅好友可评论会暴露树洞帖子作者的好友关系
+	if post.Anonymous && policy == models.CommentPolicyFriends {
+		return nil, ErrAnonymousFriendsPolicy
+	}
+
+	if err := getDB().Model(&models.Moment{}).Where("id = ?", postID).
+		UpdateColumn("comment_policy", policy).Error; err != nil {
+		return nil, err
+	}
+	post.CommentPolicy = policy
+	return post, nil
+}
+
+// PinComment 帖子作者置顶一条一级评论，新的置顶会替换旧的
+func PinComment(postID int64, userID string, commentID int64) error {
+	if _, err := findOwnMoment(postID, userID); err != nil {
+		return err
+	}
+
+	var count int64
+	getDB().Model(&models.Comment{}).
+		Where("id = ? AND post_id = ? AND parent_id = 0 AND status = ?", commentID, postID, models.CommentStatusNormal).
+		Count(&count)
+	if count == 0 {
+		return ErrCommentNotPinnable
+	}
+
+	return getDB().Model(&models.Moment{}).Where("id = ?", postID).
+		UpdateColumn("pinned_comment_id", commentID).Error
+}
+
+// UnpinComment 帖子作者取消置顶
+func UnpinComment(postID int64, userID string) error {
+	if _, err := findOwnMoment(postID, userID); err != nil {
+		return err
+	}
+	return getDB().Model(&models.Moment{}).Where("id = ?", postID).
+		UpdateColumn("pinned_comment_id", 0).Error
+}
+
+// clearPinnedComment 评论被删除或隐藏后取消它的置顶
+func clearPinnedComment(comment *models.Comment) {
+	if comment.ParentID != 0 {
+		return
+	}
+	getDB().Model(&models.Moment{}).
+		Where("id = ? AND pinned_comment_id = ?", comment.PostID, comment.ID).
+		UpdateColumn("pinned_comment_id", 0)
+}
+
+// loadPinnedComment 获取帖子的置顶评论，没有置顶或置顶的评论已不可见时返回 nil
+func loadPinnedComment(postID int64) *models.Comment {
+	var post models.Moment
+	if err := getDB().Select("id", "pinned_comment_id").First(&post, "id = ?", postID).Error; err != nil || post.PinnedCommentID == 0 {
+		return nil
+	}
+
+	var comment models.Comment
+	if err := getDB().First(&comment, "id = ? AND post_id = ? AND parent_id = 0 AND status = ?",
+		post.PinnedCommentID, postID, models.CommentStatusNormal).Error; err != nil {
+		return nil
+	}
+	comment.IsPinned = true
+	return &comment
+}
+
+// adjustCommentCounts 评论变为可见或不可见时同步帖子评论数、用户评论数和楼层回复数
+func adjustCommentCounts(comment *models.Comment, delta int) {
+	expr := func(column string) interface{} {
+		if delta > 0 {
+			return gorm.Expr(column+" + ?", delta)
+		}
+		return gorm.Expr("GREATEST("+column+" - ?, 0)", -delta)
+	}
+
+	getDB().Model(&models.Moment{}).Where("id = ?", comment.PostID).Update("comment_count", expr("comment_count"))
+	getDB().Model(&models.User{}).Where("id = ?", comment.UserID).Update("comment_count", expr("comment_count"))
+	if comment.RootID != 0 {
+		getDB().Model(&models.Comment{}).Where("id = ?", comment.RootID).Update("reply_count", expr("reply_count"))
+	}
+}
+
+// findModeratableComment 获取当前用户作为帖子作者可以管理的评论
+func findModeratableComment(commentID int64, userID string) (*models.Comment, error) {
+	var comment models.Comment
+	if err := getDB().First(&comment, "id = ? AND status <> ?", commentID, models.CommentStatusDeleted).Error; err != nil {
+		return nil, err
+	}
+	if !isPostAuthor(comment.PostID, userID) {
+		return nil, ErrNotPostAuthor
+	}
+	return &comment, nil
+}
+
+// HideComment 帖子作者隐藏自己帖子下的评论，隐藏后其他人看不到，可以恢复
+func HideComment(commentID int64, userID string) (*models.Comment, error) {
+	comment, err := findModeratableComment(commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Status == models.CommentStatusHidden {
+		return comment, nil
+	}
+
+	result := getDB().Model(&models.Comment{}).
+		Where("id = ? AND status = ?", commentID, models.CommentStatusNormal).
+		Update("status", models.CommentStatusHidden)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		adjustCommentCounts(comment, -1)
+		clearPinnedComment(comment)
+	}
+
+	comment.Status = models.CommentStatusHidden
+	return comment, nil
+}
+
+// UnhideComment 帖子作者恢复被隐藏的评论
+func UnhideComment(commentID int64, userID string) (*models.Comment, error) {
+	comment, err := findModeratableComment(commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Status == models.CommentStatusNormal {
+		return comment, nil
+	}
+
+	result := getDB().Model(&models.Comment{}).
+		Where("id = ? AND status = ?", commentID, models.CommentStatusHidden).
+		Update("status", models.CommentStatusNormal)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		adjustCommentCounts(comment, 1)
+	}
+
+	comment.Status = models.CommentStatusNormal
+	return comment, nil
+}
+
+// GetHiddenComments 帖子作者查看自己帖子下被隐藏的评论，按隐藏前的发布时间倒序
+func GetHiddenComments(postID int64, userID string, opts PageOptions) ([]models.Comment, PageInfo, error) {
+	var info PageInfo
+	if !isPostAuthor(postID, userID) {
+		return nil, info, gorm.ErrRecordNotFound
+	}
+
+	opts = normalizePage(opts)
+	query, info, err := paginate(getDB().Model(&models.Comment{}).
+		Where("post_id = ? AND status = ?", postID, models.CommentStatusHidden), opts, true)
+	if err != nil {
+		return nil, info, err
+	}
+
+	var comments []models.Comment
+	if err := query.Find(&comments).Error; err != nil {
+		return nil, info, err
+	}
+	comments = finishPage(comments, opts, &info, func(c models.Comment) string {
+		return encodeCursor(c.CreatedAt, int64(c.ID))
+	})
+
+	attachCommentUsers(comments)
+	attachCommentMentions(comments)
+	return comments, info, nil
+}
@@ -1,88 +1,223 @@
 package service
 
 import (
-	"encoding/json"
 	"github.com/Yw332/campus-moments-go/internal/models"
 	"gorm.io/gorm"
 	"time"
 )
 
-// CreateComment 创建评论
-func CreateComment(postID int64, userID, content string, replies []map[string]interface{}) (*models.Comment, error) {
+const (
+	defaultReplyPreview = 3  // 一级评论列表中每条评论默认附带的回复数
+	maxReplyPreview     = 10
+)
+
+// CreateComment 创建一级评论，回复评论请使用 ReplyComment
+func CreateComment(postID int64, userID, content string) (*models.Comment, error) {
 	// 检查帖子是否存在（使用Moment模型，因为它映射到posts表）
 	var moment models.Moment
 	if err := getDB().First(&moment, "id = ? AND status = ?", postID, 0).Error; err != nil {
 		return nil, err
 	}
 	
+	// 被帖子作者拉黑（或拉黑了作者）时不能评论
+	if IsBlocked(userID, moment.UserID) {
+		return nil, ErrUserBlocked
+	}
+	
+	// 作者关闭评论或设置仅好友可评论
+	if err := checkCommentPolicy(&moment, userID); err != nil {
+		return nil, err
+	}
+	
+	// 楼主在树洞帖子下评论时沿用帖子的化名
+	authorID := commentAuthorID(&moment, userID)
+	
 	comment := &models.Comment{
 		PostID:    postID,
-		UserID:    userID,
+		UserID:    authorID,
 		Content:   content,
 		Status:    0, // 正常状态
-		IsAuthor:  userID == moment.UserID, // 检查是否为作者
+		IsAuthor:  authorID == moment.UserID, // 检查是否为作者
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 	
-	// 处理回复
-	if len(replies) > 0 {
-		repliesJSON, _ := json.Marshal(replies)
-		comment.Replies = repliesJSON
-	}
-	
 	// 保存评论
 	if err := getDB().Create(comment).Error; err != nil {
 		return nil, err
 	}
 	
 	// 关联用户信息
-	var user models.User
-	if err := getDB().First(&user, "id = ?", userID).Error; err == nil {
-		comment.User = &user
-	}
+	comments := []models.Comment{*comment}
+	attachCommentUsers(comments)
+	comment.User = comments[0].User
 	
 	// 更新帖子评论数（使用Moment模型）
 	getDB().Model(&models.Moment{}).Where("id = ?", postID).Update("comment_count", gorm.Expr("comment_count + ?", 1))
 	
 	// 更新用户评论数
-	getDB().Model(&models.User{}).Where("id = ?", userID).Update("comment_count", gorm.Expr("comment_count + ?", 1))
+	getDB().Model(&models.User{}).Where("id = ?", authorID).Update("comment_count", gorm.Expr("comment_count + ?", 1))
+	
+	// 通知帖子作者
+	Notify(moment.UserID, authorID, models.NotificationPostCommented, postID, postID, content)
+	
+	// 解析@提及并通知
+	comment.Mentions = processMentions(models.MentionSourceComment, int64(comment.ID), postID, moment.UserID, moment.Visibility, authorID, content)
 	
 	return comment, nil
 }
 
-// GetCommentList 获取评论列表
-func GetCommentList(postID int64, page, pageSize int) ([]models.Comment, int64, error) {
+// GetCommentList 获取一级评论列表，支持游标分页
+// 默认按 (created_at, id) 正序，opts.Sort 为 SortLatest 时按时间倒序，为 SortHot 时按点赞数倒序
+// 置顶评论只出现在第一页最前面；每条一级评论附带最早的 previewN 条回复；已删除或被隐藏但仍有回复的一级评论保留为占位，内容置空
+func GetCommentList(postID int64, userID string, opts PageOptions, previewN int) ([]models.Comment, PageInfo, error) {
 	var comments []models.Comment
-	var total int64
+	opts = normalizePage(opts)
 	
-	offset := (page - 1) * pageSize
+	query := getDB().Model(&models.Comment{}).
+		Where("post_id = ? AND parent_id = 0 AND (status = ? OR reply_count > 0)", postID, 0)
 	
-	query := getDB().Model(&models.Comment{}).Where("post_id = ? AND status = ?", postID, 0)
+	pinned := loadPinnedComment(postID)
+	if pinned != nil {
+		query = query.Where("id <> ?", pinned.ID)
+	}
 	
-	// 获取总数
-	query.Count(&total)
+	var info PageInfo
+	var err error
+	switch opts.Sort {
+	case SortHot:
+		query, info, err = paginateByScore(query, opts, "like_count")
+	case SortLatest:
+		query, info, err = paginate(query, opts, true)
+	default:
+		query, info, err = paginate(query, opts, false)
+	}
+	if err != nil {
+		return nil, info, err
+	}
 	
 	// 获取评论列表（不使用Preload，因为User字段标记为gorm:"-"，需要手动加载）
-	err := query.
-		Order("created_at ASC").
-		Offset(offset).
-		Limit(pageSize).
-		Find(&comments).Error
+	if err := query.Find(&comments).Error; err != nil {
+		return comments, info, err
+	}
 	
-	if err != nil {
-		return comments, total, err
+	comments = finishPage(comments, opts, &info, func(c models.Comment) string {
+		if opts.Sort == SortHot {
+			return encodeScoreCursor(float64(c.LikeCount), int64(c.ID))
+		}
+		return encodeCursor(c.CreatedAt, int64(c.ID))
+	})
+	
+	if pinned != nil && opts.Cursor == "" && opts.Page <= 1 {
+		comments = append([]models.Comment{*pinned}, comments...)
+	}
+	
+	if err := attachReplyPreviews(comments, userID, previewN); err != nil {
+		return comments, info, err
 	}
 	
 	// 手动加载用户信息
+	attachCommentUsers(comments)
+	attachCommentMentions(comments)
+	attachCommentReactions(comments, userID)
 	for i := range comments {
-		var user models.User
-		if err := getDB().First(&user, "id = ?", comments[i].UserID).Error; err == nil {
-			comments[i].User = &user
+		if comments[i].Status != 0 {
+			blankDeletedComment(&comments[i])
 		}
 	}
 	
-	return comments, total, nil
+	return comments, info, nil
+}
+
+// attachReplyPreviews 为一级评论批量加载最早的 n 条回复
+func attachReplyPreviews(comments []models.Comment, userID string, n int) error {
+	if n <= 0 {
+		n = defaultReplyPreview
+	}
+	if n > maxReplyPreview {
+		n = maxReplyPreview
+	}
+	
+	var rootIDs []int64
+	for _, c := range comments {
+		if c.ReplyCount > 0 {
+			rootIDs = append(rootIDs, int64(c.ID))
+		}
+	}
+	if len(rootIDs) == 0 {
+		return nil
+	}
+	
+	// 用窗口函数一次取出每个楼层的前 n 条回复
+	ranked := getDB().Model(&models.Comment{}).
+		Select("*, ROW_NUMBER() OVER (PARTITION BY root_id ORDER BY created_at ASC, id ASC) AS rn").
+		Where("root_id IN ? AND status = ?", rootIDs, 0)
+	var replies []models.Comment
+	if err := getDB().Table("(?) AS ranked", ranked).
+		Where("rn <= ?", n).
+		Order("created_at ASC, id ASC").
+		Find(&replies).Error; err != nil {
+		return err
+	}
+	
+	attachCommentUsers(replies)
+	attachCommentMentions(replies)
+	attachCommentReactions(replies, userID)
+	
+	byRoot := make(map[int64][]models.Comment, len(rootIDs))
+	for _, r := range replies {
+		byRoot[r.RootID] = append(byRoot[r.RootID], r)
+	}
+	for i := range comments {
+		comments[i].Replies = byRoot[int64(comments[i].ID)]
+	}
+	return nil
+}
+
+// GetCommentReplies 分页获取一条一级评论下的全部回复，按 (created_at, id) 正序
+func GetCommentReplies(rootID int64, userID string, opts PageOptions) (*models.Comment, []models.Comment, PageInfo, error) {
+	var info PageInfo
+	
+	var root models.Comment
+	if err := getDB().First(&root, "id = ? AND parent_id = 0 AND (status = ? OR reply_count > 0)", rootID, 0).Error; err != nil {
+		return nil, nil, info, err
+	}
+	
+	opts = normalizePage(opts)
+	query, info, err := paginate(getDB().Model(&models.Comment{}).Where("root_id = ? AND status = ?", rootID, 0), opts, false)
+	if err != nil {
+		return nil, nil, info, err
+	}
+	
+	var replies []models.Comment
+	if err := query.Find(&replies).Error; err != nil {
+		return nil, nil, info, err
+	}
+	replies = finishPage(replies, opts, &info, func(c models.Comment) string {
+		return encodeCursor(c.CreatedAt, int64(c.ID))
+	})
+	
+	all := append([]models.Comment{root}, replies...)
+	attachCommentUsers(all)
+	attachCommentMentions(all)
+	attachCommentReactions(all, userID)
+	root, replies = all[0], all[1:]
+	if root.Status != 0 {
+		blankDeletedComment(&root)
+	}
+	
+	return &root, replies, info, nil
+}
+
+// blankDeletedComment 已删除或被隐藏的一级评论只保留楼层结构，不再返回内容和作者
+func blankDeletedComment(comment *models.Comment) {
+	comment.UserID = ""
+	comment.User = nil
+	comment.Content = ""
+	comment.Mentions = nil
+	comment.LikeCount = 0
+	comment.Reactions = nil
+	comment.MyReaction = ""
 }
 
 // UpdateComment 更新评论
@@ -90,7 +225,7 @@ func UpdateComment(commentID int64, userID, content string) (*models.Comment, er
 	var comment models.Comment
 	
 	// 检查评论是否存在且属于当前用户
-	if err := getDB().First(&comment, "id = ? AND user_id = ? AND status = ?", commentID, userID, 0).Error; err != nil {
+	if err := whereOwnComment(getDB(), userID).First(&comment, "id = ? AND status = ?", commentID, 0).Error; err != nil {
 		return nil, err
 	}
 	
@@ -104,20 +239,37 @@ func UpdateComment(commentID int64, userID, content string) (*models.Comment, er
 	}
 	
 	// 手动加载用户信息
-	var user models.User
-	if err := getDB().First(&user, "id = ?", comment.UserID).Error; err == nil {
-		comment.User = &user
+	comments := []models.Comment{comment}
+	attachCommentUsers(comments)
+	comment.User = comments[0].User
+	
+	// 重新解析@提及，只通知新增的被提及用户
+	var moment models.Moment
+	if err := getDB().Select("id", "user_id", "visibility").First(&moment, "id = ?", comment.PostID).Error; err == nil {
+		comment.Mentions = processMentions(models.MentionSourceComment, int64(comment.ID), comment.PostID, moment.UserID, moment.Visibility, comment.UserID, content)
 	}
 	
 	return &comment, nil
 }
 
-// DeleteComment 删除评论（仅评论作者）
+// DeleteComment 删除评论，评论作者和帖子作者都可以删除
 func DeleteComment(commentID int64, userID string) error {
+	var comment models.Comment
+	if err := getDB().First(&comment, "id = ? AND status <> ?", commentID, models.CommentStatusDeleted).Error; err != nil {
+		return err
+	}
+
+	// 检查是否为评论作者或帖子作者
+	var own int64
+	whereOwnComment(getDB().Model(&models.Comment{}), userID).Where("id = ?", commentID).Count(&own)
+	if own == 0 && !isPostAuthor(comment.PostID, userID) {
+		return gorm.ErrRecordNotFound
+	}
+
 	// 软删除：更新状态
 	result := getDB().Model(&models.Comment{}).
-		Where("id = ? AND user_id = ?", commentID, userID).
-		Update("status", 1)
+		Where("id = ? AND status = ?", commentID, comment.Status).
+		Update("status", models.CommentStatusDeleted)
 
 	if result.Error != nil {
 		return result.Error
@@ -127,15 +279,11 @@ func DeleteComment(commentID int64, userID string) error {
 		return gorm.ErrRecordNotFound
 	}
 
-	// 获取评论信息用于更新计数
-	var comment models.Comment
-	if err := getDB().First(&comment, "id = ?", commentID).Error; err == nil {
-		// 更新帖子评论数（使用Moment模型）
-		getDB().Model(&models.Moment{}).Where("id = ?", comment.PostID).Update("comment_count", gorm.Expr("GREATEST(comment_count - ?, 0)", 1))
-
-		// 更新用户评论数
-		getDB().Model(&models.User{}).Where("id = ?", userID).Update("comment_count", gorm.Expr("GREATEST(comment_count - ?, 0)", 1))
+	// 被隐藏的评论在隐藏时已经扣减过计数
+	if comment.Status == models.CommentStatusNormal {
+		adjustCommentCounts(&comment, -1)
 	}
+	clearPinnedComment(&comment)
 
 	return nil
 }
@@ -144,72 +292,33 @@ func DeleteComment(commentID int64, userID string) error {
 func AdminDeleteComment(commentID int64) (*models.Comment, error) {
 	var comment models.Comment
 
-	// 查找评论
-	if err := getDB().First(&comment, "id = ? AND status = ?", commentID, 0).Error; err != nil {
+	// 查找评论（包括被帖子作者隐藏的评论）
+	if err := getDB().First(&comment, "id = ? AND status <> ?", commentID, models.CommentStatusDeleted).Error; err != nil {
 		return nil, err
 	}
 
 	// 软删除：更新状态
 	if err := getDB().Model(&models.Comment{}).
 		Where("id = ?", commentID).
-		Update("status", 1).Error; err != nil {
+		Update("status", models.CommentStatusDeleted).Error; err != nil {
 		return nil, err
 	}
 
-	// 更新帖子评论数（使用Moment模型）
-	getDB().Model(&models.Moment{}).Where("id = ?", comment.PostID).Update("comment_count", gorm.Expr("GREATEST(comment_count - ?, 0)", 1))
-
-	// 更新用户评论数
-	getDB().Model(&models.User{}).Where("id = ?", comment.UserID).Update("comment_count", gorm.Expr("GREATEST(comment_count - ?, 0)", 1))
+	// 更新帖子评论数、用户评论数和楼层回复数，被隐藏的评论在隐藏时已经扣减过
+	if comment.Status == models.CommentStatusNormal {
+		adjustCommentCounts(&comment, -1)
+	}
+	clearPinnedComment(&comment)
 
 	return &comment, nil
 }
 
-// ToggleLikeComment 点赞/取消点赞评论
+// ToggleLikeComment 点赞/取消点赞评论，点赞即👍表情回应，已有其他表情回应时取消回应
 func ToggleLikeComment(commentID int64, userID string) (bool, error) {
-	// 检查评论是否存在
-	var comment models.Comment
-	if err := getDB().First(&comment, "id = ? AND status = ?", commentID, 0).Error; err != nil {
-		return false, err
-	}
-	
-	// 查找是否已点赞
-	var like models.Like
-	err := getDB().Where("user_id = ? AND target_type = 2 AND target_id = ?", userID, commentID).First(&like).Error
-	
-	if err == gorm.ErrRecordNotFound {
-		// 没有点赞记录，添加点赞
-		newLike := models.Like{
-			UserID:     userID,
-			TargetType: 2, // 评论
-			TargetID:   commentID,
-			CreatedAt:  time.Now(),
-		}
-		
-		if err := getDB().Create(&newLike).Error; err != nil {
-			return false, err
-		}
-		
-		// 更新评论点赞数
-		getDB().Model(&comment).Update("like_count", gorm.Expr("like_count + ?", 1))
-		
-		return true, nil
-	} else if err == nil {
-		// 已有点赞记录，删除点赞
-		if err := getDB().Delete(&like).Error; err != nil {
-			return false, err
-		}
-		
-		// 更新评论点赞数
-		getDB().Model(&comment).Update("like_count", gorm.Expr("GREATEST(like_count - ?, 0)", 1))
-		
-		return false, nil
-	}
-	
-	return false, err
+	return toggleLike(userID, models.ReactionTargetComment, commentID)
 }
 
-// ReplyComment 回复评论
+// ReplyComment 回复评论，可以回复一级评论，也可以回复楼层中的某条回复
 func ReplyComment(commentID int64, userID, content string) (*models.Comment, error) {
 	// 获取原评论
 	var parentComment models.Comment
@@ -217,56 +326,69 @@ func ReplyComment(commentID int64, userID, content string) (*models.Comment, err
 		return nil, err
 	}
 	
-	// 创建回复评论
-	reply := &models.Comment{
-		PostID:    parentComment.PostID,
-		UserID:    userID,
-		Content:   content,
-		Status:    0,
-		IsAuthor:  userID == parentComment.UserID,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	// 与评论作者或帖子作者存在拉黑关系时不能回复
+	var moment models.Moment
+	if err := getDB().Select("id", "user_id", "visibility", "anonymous", "comment_policy").First(&moment, "id = ?", parentComment.PostID).Error; err != nil {
+		return nil, err
+	}
+	if IsBlocked(userID, parentComment.UserID) || IsBlocked(userID, moment.UserID) {
+		return nil, ErrUserBlocked
 	}
 	
-	// 保存回复
-	if err := getDB().Create(reply).Error; err != nil {
+	// 作者关闭评论或设置仅好友可评论
+	if err := checkCommentPolicy(&moment, userID); err != nil {
 		return nil, err
 	}
 	
-	// 更新原评论的回复列表
-	var replies []map[string]interface{}
-	if parentComment.Replies != nil {
-		json.Unmarshal(parentComment.Replies, &replies)
-	}
+	// 楼主在树洞帖子下回复时沿用帖子的化名
+	authorID := commentAuthorID(&moment, userID)
 	
-	newReply := map[string]interface{}{
-		"id":         reply.ID,
-		"userId":     reply.UserID,
-		"content":    reply.Content,
-		"isAuthor":   reply.IsAuthor,
-		"createdAt":  reply.CreatedAt,
+	// 回复都挂在一级评论下，rootID 为所在楼层
+	rootID := parentComment.RootID
+	if rootID == 0 {
+		rootID = int64(parentComment.ID)
 	}
 	
-	replies = append(replies, newReply)
-	repliesJSON, _ := json.Marshal(replies)
+	// 创建回复评论
+	reply := &models.Comment{
+		PostID:        parentComment.PostID,
+		UserID:        authorID,
+		Content:       content,
+		ParentID:      int64(parentComment.ID),
+		RootID:        rootID,
+		ReplyToUserID: parentComment.UserID,
+		Status:        0,
+		IsAuthor:      authorID == moment.UserID,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
 	
-	// 更新原评论
-	getDB().Model(&parentComment).Updates(map[string]interface{}{
-		"replies":    repliesJSON,
-		"updated_at": time.Now(),
+	err := getDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(reply).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Comment{}).Where("id = ?", rootID).Update("reply_count", gorm.Expr("reply_count + ?", 1)).Error
 	})
+	if err != nil {
+		return nil, err
+	}
 	
 	// 关联用户信息
-	var user models.User
-	if err := getDB().First(&user, "id = ?", userID).Error; err == nil {
-		reply.User = &user
-	}
+	loaded := []models.Comment{*reply}
+	attachCommentUsers(loaded)
+	reply.User, reply.ReplyToUser = loaded[0].User, loaded[0].ReplyToUser
 	
 	// 更新帖子评论数（使用Moment模型）
 	getDB().Model(&models.Moment{}).Where("id = ?", parentComment.PostID).Update("comment_count", gorm.Expr("comment_count + ?", 1))
 	
 	// 更新用户评论数
-	getDB().Model(&models.User{}).Where("id = ?", userID).Update("comment_count", gorm.Expr("comment_count + ?", 1))
+	getDB().Model(&models.User{}).Where("id = ?", authorID).Update("comment_count", gorm.Expr("comment_count + ?", 1))
+	
+	// 通知被回复的评论作者
+	Notify(parentComment.UserID, authorID, models.NotificationCommentReplied, int64(parentComment.ID), parentComment.PostID, content)
+	
+	// 解析@提及并通知
+	reply.Mentions = processMentions(models.MentionSourceComment, int64(reply.ID), parentComment.PostID, moment.UserID, moment.Visibility, authorID, content)
 	
 	return reply, nil
 }
\ No newline at end of file
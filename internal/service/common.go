@@ -1,8 +1,10 @@
 package service
 
 import (
+	"errors"
 	"github.com/Yw332/campus-moments-go/internal/models"
 	"github.com/Yw332/campus-moments-go/pkg/database"
+	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
 	"math/rand"
 	"time"
@@ -37,6 +39,98 @@ func GetFriendIDs(userID string) []string {
 	return friendIDs
 }
 
+// IsBlocked 检查两个用户之间是否存在拉黑关系（任意一方拉黑对方）
+func IsBlocked(userID1, userID2 string) bool {
+	if userID1 == "" || userID2 == "" || userID1 == userID2 {
+		return false
+	}
+	var count int64
+	getDB().Model(&models.FriendRelation{}).
+		Where("((user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)) AND relation_type = 2 AND status = 0",
+			userID1, userID2, userID2, userID1).
+		Count(&count)
+	return count > 0
+}
+
+// GetBlockedUserIDs 获取与用户存在拉黑关系的用户ID列表（我拉黑的和拉黑我的）
+func GetBlockedUserIDs(userID string) []string {
+	var blocked, blockedBy []string
+	getDB().Model(&models.FriendRelation{}).
+		Where("user_id = ? AND relation_type = 2 AND status = 0", userID).
+		Pluck("friend_id", &blocked)
+	getDB().Model(&models.FriendRelation{}).
+		Where("friend_id = ? AND relation_type = 2 AND status = 0", userID).
+		Pluck("user_id", &blockedBy)
+	return append(blocked, blockedBy...)
+}
+
+// ViewerRelations 当前用户的好友和拉黑关系，批量判断帖子可见性时预先查出，避免逐条查询
+type ViewerRelations struct {
+	Friends map[string]bool
+	Blocked map[string]bool
+}
+
+// LoadViewerRelations 查询当前用户的好友和拉黑关系，未登录时为空
+func LoadViewerRelations(viewerID string) *ViewerRelations {
+	if viewerID == "" {
+		return newViewerRelations(nil, nil)
+	}
+	return newViewerRelations(GetFriendIDs(viewerID), GetBlockedUserIDs(viewerID))
+}
+
+// newViewerRelations 由已查出的好友和拉黑用户ID生成关系集合
+func newViewerRelations(friendIDs, blockedIDs []string) *ViewerRelations {
+	rel := &ViewerRelations{
+		Friends: make(map[string]bool, len(friendIDs)),
+		Blocked: make(map[string]bool, len(blockedIDs)),
+	}
+	for _, id := range friendIDs {
+		rel.Friends[id] = true
+	}
+	for _, id := range blockedIDs {
+		rel.Blocked[id] = true
+	}
+	return rel
+}
+
+// CanViewPost 判断用户能否看到某个帖子（按可见性和拉黑关系）
+// rel 为预先查出的当前用户关系，为 nil 时按需查询
+func CanViewPost(viewerID, authorID string, visibility int, rel *ViewerRelations) bool {
+	if viewerID == authorID {
+		return true
+	}
+	if rel != nil {
+		if rel.Blocked[authorID] {
+			return false
+		}
+	} else if IsBlocked(viewerID, authorID) {
+		return false
+	}
+	switch visibility {
+	case 0: // 公开
+		return true
+	case 1: // 好友可见
+		if viewerID == "" {
+			return false
+		}
+		if rel != nil {
+			return rel.Friends[authorID]
+		}
+		return IsFriend(viewerID, authorID)
+	default: // 仅自己
+		return false
+	}
+}
+
+// isDuplicateKeyError 判断是否为唯一索引冲突
+func isDuplicateKeyError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1062
+	}
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
+
 // generateRandomColor 生成随机颜色
 func GenerateRandomColor() string {
 	colors := []string{"FF6B6B", "4ECDC4", "45B7D1", "96CEB4", "FFEAA7", "DDA0DD", "98D8C8", "F7DC6F"}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor 游标格式错误
+var ErrInvalidCursor = errors.New("无效的游标")
+
+// 列表分页的每页数量上限
+const maxPageSize = 100
+
+// 列表排序方式
+const (
+	SortLatest = "latest" // 按发布时间倒序
+	SortHot    = "hot"    // 按热度倒序，帖子按热度分，评论按点赞数
+)
+
+// PageOptions 列表分页参数
+// Cursor 非空时按 (created_at, id) 键集分页并忽略 Page；否则按页码偏移分页，兼容旧客户端
+type PageOptions struct {
+	Page      int
+	PageSize  int
+	Cursor    string
+	WithTotal bool   // 是否统计总数，大表上 COUNT 代价较高
+	Sort      string // 排序方式，帖子列表和评论列表支持 SortHot，此时按 (热度列, id) 键集分页
+}
+
+// PageInfo 分页结果，两种分页方式都会返回 NextCursor，客户端可随时切换到游标分页
+type PageInfo struct {
+	Total      int64
+	NextCursor string
+	HasMore    bool
+}
+
+// encodeCursor 将 (时间, ID) 编码为不透明游标
+func encodeCursor(t time.Time, id int64) string {
+	raw := fmt.Sprintf("%d:%d", t.UnixNano(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor 解析 encodeCursor 生成的游标
+func decodeCursor(cursor string) (time.Time, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+
+	var nanos, id int64
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &nanos, &id); err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+
+	return time.Unix(0, nanos), id, nil
+}
+
+// encodeScoreCursor 将 (热度分, ID) 编码为不透明游标
+func encodeScoreCursor(score float64, id int64) string {
+	raw := fmt.Sprintf("%s:%d", strconv.FormatFloat(score, 'g', -1, 64), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeScoreCursor 解析 encodeScoreCursor 生成的游标
+func decodeScoreCursor(cursor string) (float64, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, ErrInvalidCursor
+	}
+	score, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, ErrInvalidCursor
+	}
+
+	return score, id, nil
+}
+
+// normalizePage 修正非法的页码和每页数量
+func normalizePage(opts PageOptions) PageOptions {
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = 20
+	}
+	if opts.PageSize > maxPageSize {
+		opts.PageSize = maxPageSize
+	}
+	return opts
+}
+
+// paginate 按需统计总数，并为查询追加 (created_at, id) 或 (hot_score, id) 排序与分页条件
+// 多取一条记录用于判断是否还有下一页，调用方查询后需用 finishPage 截断
+func paginate(query *gorm.DB, opts PageOptions, desc bool) (*gorm.DB, PageInfo, error) {
+	if opts.Sort == SortHot {
+		return paginateByScore(query, opts, "hot_score")
+	}
+
+	var info PageInfo
+
+	if opts.WithTotal {
+		if err := query.Count(&info.Total).Error; err != nil {
+			return nil, info, err
+		}
+	}
+
+	cmp, order := ">", "created_at ASC, id ASC"
+	if desc {
+		cmp, order = "<", "created_at DESC, id DESC"
+	}
+
+	if opts.Cursor != "" {
+		createdAt, id, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, info, err
+		}
+		query = query.Where(fmt.Sprintf("(created_at %s ? OR (created_at = ? AND id %s ?))", cmp, cmp), createdAt, createdAt, id)
+	} else if opts.Page > 1 {
+		query = query.Offset((opts.Page - 1) * opts.PageSize)
+	}
+
+	return query.Order(order).Limit(opts.PageSize + 1), info, nil
+}
+
+// paginateByScore 按 (column, id) 倒序分页，column 为热度分、点赞数等数值列
+func paginateByScore(query *gorm.DB, opts PageOptions, column string) (*gorm.DB, PageInfo, error) {
+	var info PageInfo
+
+	if opts.WithTotal {
+		if err := query.Count(&info.Total).Error; err != nil {
+			return nil, info, err
+		}
+	}
+
+	if opts.Cursor != "" {
+		score, id, err := decodeScoreCursor(opts.Cursor)
+		if err != nil {
+			return nil, info, err
+		}
+		query = query.Where(fmt.Sprintf("(%s < ? OR (%s = ? AND id < ?))", column, column), score, score, id)
+	} else if opts.Page > 1 {
+		query = query.Offset((opts.Page - 1) * opts.PageSize)
+	}
+	return query.Order(column + " DESC, id DESC").Limit(opts.PageSize + 1), info, nil
+}
+
+// finishPage 截掉多取的一条记录，并以最后一条记录生成下一页游标
+func finishPage[T any](items []T, opts PageOptions, info *PageInfo, cursor func(T) string) []T {
+	if len(items) > opts.PageSize {
+		items = items[:opts.PageSize]
+		info.HasMore = true
+	}
+	if info.HasMore {
+		info.NextCursor = cursor(items[len(items)-1])
+	}
+	return items
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"gorm.io/gorm"
+)
+
+var ErrCannotFollowSelf = errors.New("不能关注自己")
+
+// FollowUser 关注用户，重复关注直接返回成功
+func FollowUser(userID, targetID string) error {
+	if userID == targetID {
+		return ErrCannotFollowSelf
+	}
+
+	var target models.User
+	if err := getDB().Select("id").First(&target, "id = ?", targetID).Error; err != nil {
+		return err
+	}
+
+	if IsBlocked(userID, targetID) {
+		return ErrUserBlocked
+	}
+
+	err := getDB().Transaction(func(tx *gorm.DB) error {
+		follow := models.Follow{
+			FollowerID: userID,
+			FolloweeID: targetID,
+			CreatedAt:  time.Now(),
+		}
+		if err := tx.Create(&follow).Error; err != nil {
+			return err
+		}
+		return updateFollowCounts(tx, userID, targetID, 1)
+	})
+	if isDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// UnfollowUser 取消关注
+func UnfollowUser(userID, targetID string) error {
+	return getDB().Transaction(func(tx *gorm.DB) error {
+		return removeFollow(tx, userID, targetID)
+	})
+}
+
+// removeFollow 删除关注关系并同步关注数，关系不存在时不做任何事
+func removeFollow(tx *gorm.DB, followerID, followeeID string) error {
+	result := tx.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&models.Follow{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	return updateFollowCounts(tx, followerID, followeeID, -1)
+}
+
+// updateFollowCounts 同步关注者的关注数和被关注者的粉丝数
+func updateFollowCounts(tx *gorm.DB, followerID, followeeID string, delta int) error {
+	if err := tx.Model(&models.User{}).Where("id = ?", followerID).
+		Update("following_count", gorm.Expr("GREATEST(following_count + ?, 0)", delta)).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.User{}).Where("id = ?", followeeID).
+		Update("follower_count", gorm.Expr("GREATEST(follower_count + ?, 0)", delta)).Error
+}
+
+// IsFollowing 是否已关注对方
+func IsFollowing(userID, targetID string) bool {
+	if userID == "" || userID == targetID {
+		return false
+	}
+	var count int64
+	getDB().Model(&models.Follow{}).
+		Where("follower_id = ? AND followee_id = ?", userID, targetID).
+		Count(&count)
+	return count > 0
+}
+
+// getFollowingSet 返回 targetIDs 中已被 userID 关注的用户
+func getFollowingSet(userID string, targetIDs []string) map[string]bool {
+	result := make(map[string]bool)
+	if userID == "" || len(targetIDs) == 0 {
+		return result
+	}
+
+	var followeeIDs []string
+	getDB().Model(&models.Follow{}).
+		Where("follower_id = ? AND followee_id IN ?", userID, targetIDs).
+		Pluck("followee_id", &followeeIDs)
+	for _, id := range followeeIDs {
+		result[id] = true
+	}
+	return result
+}
+
+// GetFollowers 获取用户的粉丝列表，按关注时间倒序，viewerID 为当前登录用户
+func GetFollowers(viewerID, userID string, opts PageOptions) ([]models.Follow, PageInfo, error) {
+	query := getDB().Model(&models.Follow{}).Where("followee_id = ?", userID)
+	return findFollows(viewerID, query, opts, func(f models.Follow) string { return f.FollowerID })
+}
+
+// GetFollowing 获取用户的关注列表，按关注时间倒序，viewerID 为当前登录用户
+func GetFollowing(viewerID, userID string, opts PageOptions) ([]models.Follow, PageInfo, error) {
+	query := getDB().Model(&models.Follow{}).Where("follower_id = ?", userID)
+	return findFollows(viewerID, query, opts, func(f models.Follow) string { return f.FolloweeID })
+}
+
+// findFollows 分页查询关注关系，用 other 取出的对方用户填充 User，并标记当前用户是否已关注对方
+func findFollows(viewerID string, query *gorm.DB, opts PageOptions, other func(models.Follow) string) ([]models.Follow, PageInfo, error) {
+	var follows []models.Follow
+	opts = normalizePage(opts)
+
+	query, info, err := paginate(query, opts, true)
+	if err != nil {
+		return nil, info, err
+	}
+
+	if err := query.Find(&follows).Error; err != nil {
+		return nil, info, err
+	}
+
+	follows = finishPage(follows, opts, &info, func(f models.Follow) string {
+		return encodeCursor(f.CreatedAt, f.ID)
+	})
+
+	userIDSet := make(map[string]bool, len(follows))
+	userIDs := make([]string, 0, len(follows))
+	for _, f := range follows {
+		userIDSet[other(f)] = true
+		userIDs = append(userIDs, other(f))
+	}
+	users := loadUsers(userIDSet)
+	following := getFollowingSet(viewerID, userIDs)
+	for i := range follows {
+		follows[i].User = users[other(follows[i])]
+		follows[i].IsFollowing = following[other(follows[i])]
+	}
+
+	return follows, info, nil
+}
+
+// GetFollowingFeed 关注动态：关注用户的公开帖子，以及其中同时是好友的用户的好友可见帖子
+func GetFollowingFeed(userID string, opts PageOptions) ([]models.Post, PageInfo, error) {
+	opts = normalizePage(opts)
+
+	followees := getDB().Model(&models.Follow{}).Select("followee_id").Where("follower_id = ?", userID)
+	query := getDB().Model(&models.Post{}).Where("status = ? AND user_id IN (?)", 0, followees)
+	query = applyPostVisibility(query, userID, "all")
+
+	return findPosts(query, opts, userID)
+}
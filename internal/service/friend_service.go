@@ -2,12 +2,18 @@ package service
 
 import (
 	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/realtime"
 	"gorm.io/gorm"
 	"time"
 )
 
 // SendFriendRequest 发送好友请求
 func SendFriendRequest(fromUserID, toUserID, message string) (*models.FriendRequest, error) {
+	// 存在拉黑关系时不允许发送
+	if IsBlocked(fromUserID, toUserID) {
+		return nil, ErrUserBlocked
+	}
+	
 	// 检查是否已经是好友
 	if IsFriend(fromUserID, toUserID) {
 		return nil, gorm.ErrInvalidTransaction // 已是好友
@@ -39,6 +45,13 @@ func SendFriendRequest(fromUserID, toUserID, message string) (*models.FriendRequ
 	// 关联用户信息
 	getDB().Preload("FromUser").Preload("ToUser").First(request, request.ID)
 	
+	// 通知对方
+	Notify(toUserID, fromUserID, models.NotificationFriendRequestReceived, int64(request.ID), 0, message)
+	realtime.GetHub().PushToUser(toUserID, realtime.EventFriendRequest, map[string]interface{}{
+		"action":  "received",
+		"request": request,
+	})
+	
 	return request, nil
 }
 
@@ -134,6 +147,12 @@ func HandleFriendRequest(requestID int64, userID, action string) error {
 		
 		tx.Commit()
 		
+		// 好友关系变化，双方的首页时间线需要重新回填
+		evictTimelines(request.FromUserID, request.ToUserID)
+		
+		// 通知请求发起者
+		Notify(request.FromUserID, userID, models.NotificationFriendRequestAccepted, int64(request.ID), 0, "")
+		
 	} else if action == "reject" {
 		// 拒绝好友请求
 		if err := getDB().Model(&request).Updates(map[string]interface{}{
@@ -144,6 +163,13 @@ func HandleFriendRequest(requestID int64, userID, action string) error {
 		}
 	}
 	
+	// 推送处理结果给请求发起者
+	realtime.GetHub().PushToUser(request.FromUserID, realtime.EventFriendRequest, map[string]interface{}{
+		"action":    action,
+		"requestId": request.ID,
+		"userId":    userID,
+	})
+	
 	return nil
 }
 
@@ -204,6 +230,9 @@ func DeleteFriend(userID, friendID string) error {
 	
 	tx.Commit()
 	
+	// 好友关系变化，双方的首页时间线需要重新回填
+	evictTimelines(userID, friendID)
+	
 	return nil
 }
 
@@ -0,0 +1,555 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/realtime"
+	"gorm.io/gorm"
+)
+
+// 群成员数量上限
+const maxGroupMembers = 500
+
+var (
+	ErrGroupNotFound         = errors.New("群聊不存在")
+	ErrNotGroupMember        = errors.New("你不是该群成员")
+	ErrGroupPermissionDenied = errors.New("没有权限执行该操作")
+	ErrGroupMemberLimit      = errors.New("群成员数量已达上限")
+	ErrGroupInviteNotFriend  = errors.New("只能邀请好友加入群聊")
+)
+
+// GroupDetail 群详情（含成员列表和当前用户角色）
+type GroupDetail struct {
+	models.ChatGroup
+	Members []models.GroupMember `json:"members"`
+	MyRole  int                  `json:"myRole"`
+}
+
+// CreateGroup 创建群聊，创建者为群主，初始成员必须是创建者的好友
+func CreateGroup(ownerID, name, avatar string, memberIDs []string) (*models.ChatGroup, error) {
+	memberIDs = uniqueUserIDs(memberIDs, ownerID)
+	if len(memberIDs)+1 > maxGroupMembers {
+		return nil, ErrGroupMemberLimit
+	}
+	for _, memberID := range memberIDs {
+		if !IsFriend(ownerID, memberID) {
+			return nil, ErrGroupInviteNotFriend
+		}
+	}
+
+	now := time.Now()
+	group := &models.ChatGroup{
+		Name:        name,
+		Avatar:      avatar,
+		OwnerID:     ownerID,
+		MemberCount: len(memberIDs) + 1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	err := getDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(group).Error; err != nil {
+			return err
+		}
+		if err := addGroupMembers(tx, group.ID, []string{ownerID}, models.GroupRoleOwner, now); err != nil {
+			return err
+		}
+		return addGroupMembers(tx, group.ID, memberIDs, models.GroupRoleMember, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pushGroupUpdated(append(memberIDs, ownerID), group.ID, "created", ownerID)
+
+	return group, nil
+}
+
+// GetMyGroups 获取用户加入的群聊
+func GetMyGroups(userID string) ([]models.ChatGroup, error) {
+	var groups []models.ChatGroup
+	err := getDB().Model(&models.ChatGroup{}).
+		Joins("JOIN group_members ON group_members.group_id = chat_groups.id").
+		Where("group_members.user_id = ? AND chat_groups.status = 0", userID).
+		Order("chat_groups.updated_at DESC").
+		Find(&groups).Error
+	return groups, err
+}
+
+// GetGroupDetail 获取群详情（仅群成员可查看）
+func GetGroupDetail(userID string, groupID int64) (*GroupDetail, error) {
+	group, err := getActiveGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	self, err := getGroupMember(groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []models.GroupMember
+	if err := getDB().Where("group_id = ?", groupID).
+		Order("role DESC, joined_at ASC, id ASC").
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	userIDSet := make(map[string]bool, len(members))
+	for _, m := range members {
+		userIDSet[m.UserID] = true
+	}
+	users := loadUsers(userIDSet)
+	for i := range members {
+		members[i].User = users[members[i].UserID]
+	}
+
+	return &GroupDetail{ChatGroup: *group, Members: members, MyRole: self.Role}, nil
+}
+
+// UpdateGroup 修改群名称/头像（群主或管理员）
+func UpdateGroup(userID string, groupID int64, name, avatar string) (*models.ChatGroup, error) {
+	group, err := getActiveGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	operator, err := getGroupMember(groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if operator.Role < models.GroupRoleAdmin {
+		return nil, ErrGroupPermissionDenied
+	}
+
+	updates := map[string]interface{}{"updated_at": time.Now()}
+	if name != "" {
+		updates["name"] = name
+	}
+	if avatar != "" {
+		updates["avatar"] = avatar
+	}
+	if err := getDB().Model(group).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	pushGroupUpdated(getGroupMemberIDs(groupID), groupID, "updated", userID)
+
+	return group, nil
+}
+
+// InviteGroupMembers 邀请好友入群（任意成员可邀请），返回实际新加入的人数
+func InviteGroupMembers(userID string, groupID int64, memberIDs []string) (int, error) {
+	group, err := getActiveGroup(groupID)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := getGroupMember(groupID, userID); err != nil {
+		return 0, err
+	}
+
+	// 过滤已在群内的用户
+	var existing []string
+	getDB().Model(&models.GroupMember{}).
+		Where("group_id = ? AND user_id IN ?", groupID, memberIDs).
+		Pluck("user_id", &existing)
+	existingSet := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		existingSet[id] = true
+	}
+
+	var newIDs []string
+	for _, memberID := range uniqueUserIDs(memberIDs, userID) {
+		if existingSet[memberID] {
+			continue
+		}
+		if !IsFriend(userID, memberID) {
+			return 0, ErrGroupInviteNotFriend
+		}
+		newIDs = append(newIDs, memberID)
+	}
+	if len(newIDs) == 0 {
+		return 0, nil
+	}
+	if group.MemberCount+len(newIDs) > maxGroupMembers {
+		return 0, ErrGroupMemberLimit
+	}
+
+	now := time.Now()
+	err = getDB().Transaction(func(tx *gorm.DB) error {
+		if err := addGroupMembers(tx, groupID, newIDs, models.GroupRoleMember, now); err != nil {
+			return err
+		}
+		return tx.Model(group).Updates(map[string]interface{}{
+			"member_count": gorm.Expr("member_count + ?", len(newIDs)),
+			"updated_at":   now,
+		}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	pushGroupUpdated(getGroupMemberIDs(groupID), groupID, "members_joined", userID)
+
+	return len(newIDs), nil
+}
+
+// RemoveGroupMember 移出群成员：群主可移出任何人，管理员只能移出普通成员
+func RemoveGroupMember(operatorID string, groupID int64, targetID string) error {
+	if _, err := getActiveGroup(groupID); err != nil {
+		return err
+	}
+	operator, err := getGroupMember(groupID, operatorID)
+	if err != nil {
+		return err
+	}
+	target, err := getGroupMember(groupID, targetID)
+	if err != nil {
+		return err
+	}
+	if operator.Role < models.GroupRoleAdmin || operator.Role <= target.Role {
+		return ErrGroupPermissionDenied
+	}
+
+	if err := getDB().Transaction(func(tx *gorm.DB) error {
+		return removeGroupMember(tx, groupID, targetID)
+	}); err != nil {
+		return err
+	}
+
+	pushGroupUpdated(append(getGroupMemberIDs(groupID), targetID), groupID, "member_removed", targetID)
+
+	return nil
+}
+
+// LeaveGroup 退出群聊，群主退出时转让给最早加入的管理员（没有则为最早加入的成员），最后一人退出时解散群聊
+func LeaveGroup(userID string, groupID int64) error {
+	group, err := getActiveGroup(groupID)
+	if err != nil {
+		return err
+	}
+	self, err := getGroupMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+
+	err = getDB().Transaction(func(tx *gorm.DB) error {
+		if err := removeGroupMember(tx, groupID, userID); err != nil {
+			return err
+		}
+		if self.Role != models.GroupRoleOwner {
+			return nil
+		}
+
+		var successor models.GroupMember
+		err := tx.Where("group_id = ?", groupID).
+			Order("role DESC, joined_at ASC, id ASC").
+			First(&successor).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 没有其他成员，解散群聊
+			return tx.Model(group).Updates(map[string]interface{}{
+				"status":     1,
+				"updated_at": time.Now(),
+			}).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&successor).Update("role", models.GroupRoleOwner).Error; err != nil {
+			return err
+		}
+		return tx.Model(group).Update("owner_id", successor.UserID).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	pushGroupUpdated(append(getGroupMemberIDs(groupID), userID), groupID, "member_left", userID)
+
+	return nil
+}
+
+// SetGroupAdmin 设置/取消管理员（仅群主）
+func SetGroupAdmin(ownerID string, groupID int64, targetID string, isAdmin bool) error {
+	if _, err := getActiveGroup(groupID); err != nil {
+		return err
+	}
+	operator, err := getGroupMember(groupID, ownerID)
+	if err != nil {
+		return err
+	}
+	if operator.Role != models.GroupRoleOwner {
+		return ErrGroupPermissionDenied
+	}
+	target, err := getGroupMember(groupID, targetID)
+	if err != nil {
+		return err
+	}
+	if target.Role == models.GroupRoleOwner {
+		return ErrGroupPermissionDenied
+	}
+
+	role := models.GroupRoleMember
+	if isAdmin {
+		role = models.GroupRoleAdmin
+	}
+	if err := getDB().Model(target).Update("role", role).Error; err != nil {
+		return err
+	}
+
+	pushGroupUpdated(getGroupMemberIDs(groupID), groupID, "role_changed", targetID)
+
+	return nil
+}
+
+// GetGroupMessageList 获取群消息列表（仅群成员可查看）
+func GetGroupMessageList(userID string, groupID int64, beforeMsgID string, pageSize int) ([]models.Message, int64, error) {
+	if _, err := getGroupMember(groupID, userID); err != nil {
+		return nil, 0, err
+	}
+
+	var messages []models.Message
+	var total int64
+
+	query := getDB().Model(&models.Message{}).Where("group_id = ?", groupID)
+	if beforeMsgID != "" {
+		query = query.Where("id < ?", beforeMsgID)
+	}
+
+	query.Count(&total)
+
+	if err := query.Order("id DESC").Limit(pageSize).Find(&messages).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 填充发送者信息
+	senderIDSet := make(map[string]bool)
+	for _, m := range messages {
+		senderIDSet[m.SenderID] = true
+	}
+	senders := loadUsers(senderIDSet)
+	for i := range messages {
+		messages[i].Sender = senders[messages[i].SenderID]
+	}
+	attachMessageReactions(messages, userID)
+
+	// 反转消息顺序（最新的在后面）
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	return messages, total, nil
+}
+
+// MarkGroupMessagesAsRead 清空群会话未读数
+func MarkGroupMessagesAsRead(userID string, groupID int64) error {
+	var rowsAffected int64
+	err := getDB().Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Conversation{}).
+			Where("user_id = ? AND group_id = ? AND conv_type = ? AND unread_count > 0", userID, groupID, models.ConversationGroup).
+			Update("unread_count", 0)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		rowsAffected = result.RowsAffected
+
+		return recordSyncEvent(tx, userID, models.SyncEventRead, "", groupID, 0, map[string]interface{}{
+			"readerId": userID,
+			"groupId":  groupID,
+			"readAt":   time.Now(),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if rowsAffected > 0 {
+		pushUnreadCount(userID)
+	}
+	return nil
+}
+
+// PinGroupConversation 置顶/取消置顶群会话
+func PinGroupConversation(userID string, groupID int64, pinned bool) error {
+	return updateGroupConversation(userID, groupID, "is_pinned", pinned)
+}
+
+// MuteGroupConversation 静音/取消静音群会话
+func MuteGroupConversation(userID string, groupID int64, muted bool) error {
+	return updateGroupConversation(userID, groupID, "is_muted", muted)
+}
+
+// sendGroupMessage 发送群消息，并扩散到所有成员的会话
+func sendGroupMessage(message *models.Message) (*models.Message, error) {
+	if _, err := getActiveGroup(message.GroupID); err != nil {
+		return nil, err
+	}
+	if _, err := getGroupMember(message.GroupID, message.SenderID); err != nil {
+		return nil, err
+	}
+
+	memberIDs := getGroupMemberIDs(message.GroupID)
+
+	err := getDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+
+		// 发送者自己的会话不增加未读数
+		if err := tx.Model(&models.Conversation{}).
+			Where("group_id = ? AND conv_type = ?", message.GroupID, models.ConversationGroup).
+			Updates(map[string]interface{}{
+				"last_msg_id":      message.ID,
+				"last_msg_preview": message.ContentPreview,
+				"unread_count":     gorm.Expr("unread_count + IF(user_id = ?, 0, 1)", message.SenderID),
+				"updated_at":       message.CreatedAt,
+			}).Error; err != nil {
+			return err
+		}
+
+		return recordGroupSyncEvent(tx, memberIDs, models.SyncEventMessage, message.GroupID, message.ID, message)
+	})
+	if err != nil {
+		// 并发重试时由唯一索引兜底，返回先保存成功的那条
+		if message.ClientMsgID != nil && isDuplicateKeyError(err) {
+			return findMessageByClientID(message.SenderID, message.DeviceID, *message.ClientMsgID, "", message.GroupID)
+		}
+		return nil, err
+	}
+
+	getDB().Model(&models.ChatGroup{}).Where("id = ?", message.GroupID).Update("updated_at", message.CreatedAt)
+
+	// 推送给所有成员（含发送者的其他设备）
+	realtime.GetHub().PushToUsers(memberIDs, realtime.EventNewMessage, message)
+	for _, memberID := range memberIDs {
+		if memberID != message.SenderID {
+			pushUnreadCount(memberID)
+		}
+	}
+
+	return message, nil
+}
+
+// getActiveGroup 获取未解散的群聊
+func getActiveGroup(groupID int64) (*models.ChatGroup, error) {
+	var group models.ChatGroup
+	if err := getDB().Where("id = ? AND status = 0", groupID).First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+// getGroupMember 获取群成员记录
+func getGroupMember(groupID int64, userID string) (*models.GroupMember, error) {
+	var member models.GroupMember
+	if err := getDB().Where("group_id = ? AND user_id = ?", groupID, userID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotGroupMember
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// getGroupMemberIDs 获取群成员ID列表
+func getGroupMemberIDs(groupID int64) []string {
+	var memberIDs []string
+	getDB().Model(&models.GroupMember{}).Where("group_id = ?", groupID).Pluck("user_id", &memberIDs)
+	return memberIDs
+}
+
+// addGroupMembers 添加成员并为其创建群会话
+func addGroupMembers(tx *gorm.DB, groupID int64, userIDs []string, role int, now time.Time) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	members := make([]models.GroupMember, 0, len(userIDs))
+	conversations := make([]models.Conversation, 0, len(userIDs))
+	for _, userID := range userIDs {
+		members = append(members, models.GroupMember{
+			GroupID:  groupID,
+			UserID:   userID,
+			Role:     role,
+			JoinedAt: now,
+		})
+		conversations = append(conversations, models.Conversation{
+			UserID:    userID,
+			ConvType:  models.ConversationGroup,
+			GroupID:   groupID,
+			UpdatedAt: now,
+		})
+	}
+
+	if err := tx.Create(&members).Error; err != nil {
+		return err
+	}
+	return tx.Create(&conversations).Error
+}
+
+// removeGroupMember 删除成员及其群会话
+func removeGroupMember(tx *gorm.DB, groupID int64, userID string) error {
+	if err := tx.Where("group_id = ? AND user_id = ?", groupID, userID).
+		Delete(&models.GroupMember{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ? AND group_id = ? AND conv_type = ?", userID, groupID, models.ConversationGroup).
+		Delete(&models.Conversation{}).Error; err != nil {
+		return err
+	}
+	if err := recordSyncEvent(tx, userID, models.SyncEventConversationDeleted, "", groupID, 0, map[string]interface{}{
+		"groupId": groupID,
+	}); err != nil {
+		return err
+	}
+	return tx.Model(&models.ChatGroup{}).Where("id = ?", groupID).Updates(map[string]interface{}{
+		"member_count": gorm.Expr("member_count - ?", 1),
+		"updated_at":   time.Now(),
+	}).Error
+}
+
+// updateGroupConversation 更新当前用户群会话的置顶/静音状态
+func updateGroupConversation(userID string, groupID int64, column string, value bool) error {
+	if _, err := getGroupMember(groupID, userID); err != nil {
+		return err
+	}
+	if err := getDB().Model(&models.Conversation{}).
+		Where("user_id = ? AND group_id = ? AND conv_type = ?", userID, groupID, models.ConversationGroup).
+		Update(column, value).Error; err != nil {
+		return err
+	}
+
+	key := "isPinned"
+	if column == "is_muted" {
+		key = "isMuted"
+	}
+	return recordSyncEvent(getDB(), userID, models.SyncEventConversationUpdated, "", groupID, 0, map[string]interface{}{
+		key: value,
+	})
+}
+
+// pushGroupUpdated 推送群信息/成员变化事件
+func pushGroupUpdated(userIDs []string, groupID int64, action, userID string) {
+	realtime.GetHub().PushToUsers(userIDs, realtime.EventGroupUpdated, map[string]interface{}{
+		"groupId": groupID,
+		"action":  action,
+		"userId":  userID,
+	})
+}
+
+// uniqueUserIDs 去重并排除指定用户
+func uniqueUserIDs(userIDs []string, exclude string) []string {
+	seen := map[string]bool{exclude: true}
+	result := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
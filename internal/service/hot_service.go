@@ -0,0 +1,59 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/pkg/config"
+	"gorm.io/gorm"
+)
+
+var hotScoreOnce sync.Once
+
+// StartHotScoreWorker 启动后台热度分重算任务，启动时立即计算一次，之后按配置间隔重算
+func StartHotScoreWorker() {
+	hotScoreOnce.Do(func() {
+		go func() {
+			cfg := config.Cfg.Feed
+			interval := cfg.HotRecomputeInterval
+			if interval <= 0 {
+				interval = 5 * time.Minute
+			}
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				if err := RecomputeHotScores(); err != nil {
+					log.Printf("⚠️  重算帖子热度分失败: %v", err)
+				}
+				<-ticker.C
+			}
+		}()
+	})
+}
+
+// RecomputeHotScores 重算热度窗口内帖子的热度分
+// 热度分 = (点赞数*权重 + 评论数*权重 + 浏览量*权重) / (发布小时数 + 2) ^ 衰减指数
+// 窗口外的帖子热度分归零，避免停止重算后残留的旧分数压过新帖
+func RecomputeHotScores() error {
+	cfg := config.Cfg.Feed
+	since := time.Now().Add(-cfg.HotWindow)
+
+	return getDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Moment{}).
+			Where("status = 0 AND created_at >= ?", since).
+			Update("hot_score", gorm.Expr(
+				"(like_count * ? + comment_count * ? + view_count * ?) / POW(GREATEST(TIMESTAMPDIFF(SECOND, created_at, NOW()), 0) / 3600 + 2, ?)",
+				cfg.HotLikeWeight, cfg.HotCommentWeight, cfg.HotViewWeight, cfg.HotGravity,
+			)).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Moment{}).
+			Where("(status <> 0 OR created_at < ?) AND hot_score <> 0", since).
+			Update("hot_score", 0).Error
+	})
+}
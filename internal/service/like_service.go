@@ -1,174 +1,232 @@
 package service
 
 import (
-	"encoding/json"
+	"errors"
 	"github.com/Yw332/campus-moments-go/internal/models"
 	"gorm.io/gorm"
-	"time"
 )
 
-// ToggleLikePost 点赞/取消点赞帖子
+const (
+	friendLikePreview = 3   // 帖子上展示的"好友赞过"人数
+	maxLikeStatusIDs  = 100 // 批量查询点赞状态一次最多的目标数
+)
+
+var (
+	ErrInvalidLikeTarget  = errors.New("只能查询帖子或评论的点赞状态")
+	ErrTooManyLikeTargets = errors.New("一次最多查询100个目标的点赞状态")
+)
+
+// LikeStatus 当前用户对某个目标的点赞状态
+type LikeStatus struct {
+	TargetID int64  `json:"targetId"`
+	Liked    bool   `json:"liked"`
+	Reaction string `json:"reaction"` // 点赞使用的表情，未点赞为空
+}
+
+// ToggleLikePost 点赞/取消点赞帖子，点赞即👍表情回应，已有其他表情回应时取消回应
 func ToggleLikePost(postID int64, userID string) (bool, error) {
-	// 检查帖子是否存在（使用Moment模型，因为它映射到posts表）
-	var moment models.Moment
-	if err := getDB().First(&moment, "id = ? AND status = ?", postID, 0).Error; err != nil {
-		return false, err
-	}
-	
-	// 查找是否已点赞
-	var like models.Like
-	err := getDB().Where("user_id = ? AND target_type = 1 AND target_id = ?", userID, postID).First(&like).Error
-	
-	if err == gorm.ErrRecordNotFound {
-		// 没有点赞记录，添加点赞
-		newLike := models.Like{
-			UserID:     userID,
-			TargetType: 1, // 帖子
-			TargetID:   postID,
-			CreatedAt:  time.Now(),
-		}
-		
-		if err := getDB().Create(&newLike).Error; err != nil {
-			return false, err
-		}
-		
-		// 更新帖子点赞数（使用Moment模型）
-		getDB().Model(&models.Moment{}).Where("id = ?", postID).Update("like_count", gorm.Expr("like_count + ?", 1))
-		
-		// 更新用户获赞数
-		getDB().Model(&models.User{}).Where("id = ?", moment.UserID).Update("like_count", gorm.Expr("like_count + ?", 1))
-		
-		// 更新帖子的点赞用户列表
-		updatePostLikedUsers(postID, userID, true)
-		
-		return true, nil
-	} else if err == nil {
-		// 已有点赞记录，删除点赞
-		if err := getDB().Delete(&like).Error; err != nil {
-			return false, err
-		}
-		
-		// 更新帖子点赞数（使用Moment模型）
-		getDB().Model(&models.Moment{}).Where("id = ?", postID).Update("like_count", gorm.Expr("GREATEST(like_count - ?, 0)", 1))
-		
-		// 更新用户获赞数
-		getDB().Model(&models.User{}).Where("id = ?", moment.UserID).Update("like_count", gorm.Expr("GREATEST(like_count - ?, 0)", 1))
-		
-		// 更新帖子的点赞用户列表
-		updatePostLikedUsers(postID, userID, false)
-		
-		return false, nil
-	}
-	
-	return false, err
+	return toggleLike(userID, models.ReactionTargetPost, postID)
 }
 
-// GetPostLikes 获取帖子点赞列表
-func GetPostLikes(postID int64, page, pageSize int) ([]models.Like, int64, error) {
-	var likes []models.Like
-	var total int64
-	
-	offset := (page - 1) * pageSize
-	
+// GetPostLikes 获取帖子点赞列表，按点赞时间倒序，支持游标分页，reaction 非空时只返回该表情的回应
+// friendsOf 非空时只返回该用户的好友的点赞
+func GetPostLikes(postID int64, reaction, friendsOf string, opts PageOptions) ([]models.Like, PageInfo, error) {
 	query := getDB().Model(&models.Like{}).Where("target_type = 1 AND target_id = ?", postID)
-	
-	// 获取总数
-	query.Count(&total)
-	
-	// 获取点赞列表
-	err := query.Preload("User").
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(pageSize).
-		Find(&likes).Error
-	
-	return likes, total, err
+	if reaction != "" {
+		query = query.Where("reaction = ?", reaction)
+	}
+	if friendsOf != "" {
+		query = whereLikedByFriends(query, friendsOf)
+	}
+	return findLikes(query, opts, "", false)
 }
 
-// GetCommentLikes 获取评论点赞列表
-func GetCommentLikes(commentID int64, page, pageSize int) ([]models.Like, int64, error) {
-	var likes []models.Like
-	var total int64
-	
-	offset := (page - 1) * pageSize
-	
+// GetCommentLikes 获取评论点赞列表，按点赞时间倒序，支持游标分页，reaction 非空时只返回该表情的回应
+func GetCommentLikes(commentID int64, reaction string, opts PageOptions) ([]models.Like, PageInfo, error) {
 	query := getDB().Model(&models.Like{}).Where("target_type = 2 AND target_id = ?", commentID)
-	
-	// 获取总数
-	query.Count(&total)
-	
-	// 获取点赞列表
-	err := query.Preload("User").
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(pageSize).
-		Find(&likes).Error
-	
-	return likes, total, err
+	if reaction != "" {
+		query = query.Where("reaction = ?", reaction)
+	}
+	return findLikes(query, opts, "", false)
 }
 
-// GetUserLikes 获取用户点赞列表
-func GetUserLikes(userID, targetType string, page, pageSize int) ([]models.Like, int64, error) {
+// GetUserLikes 获取用户点赞列表，按点赞时间倒序，支持游标分页，viewerID 为当前登录用户
+func GetUserLikes(viewerID, userID, targetType string, opts PageOptions) ([]models.Like, PageInfo, error) {
+	// 消息的表情回应只对会话参与者可见，不出现在点赞列表中
+	query := getDB().Model(&models.Like{}).Where("user_id = ? AND target_type IN ?", userID,
+		[]int{models.ReactionTargetPost, models.ReactionTargetComment})
+
+	if targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+
+	return findLikes(query, opts, viewerID, true)
+}
+
+// findLikes 分页查询点赞记录并手动加载用户信息（User、Post字段标记为gorm:"-"），withPosts 时同时加载被赞的帖子
+// 帖子按 viewerID 的可见性和拉黑关系过滤，看不到的只返回墓碑
+func findLikes(query *gorm.DB, opts PageOptions, viewerID string, withPosts bool) ([]models.Like, PageInfo, error) {
 	var likes []models.Like
-	var total int64
+	opts = normalizePage(opts)
 
-	offset := (page - 1) * pageSize
+	query, info, err := paginate(query, opts, true)
+	if err != nil {
+		return nil, info, err
+	}
 
-	query := getDB().Model(&models.Like{}).Where("user_id = ?", userID)
+	if err := query.Find(&likes).Error; err != nil {
+		return nil, info, err
+	}
 
-	if targetType != "" {
-		query = query.Where("target_type = ?", targetType)
+	likes = finishPage(likes, opts, &info, func(l models.Like) string {
+		return encodeCursor(l.CreatedAt, int64(l.ID))
+	})
+
+	userIDSet := make(map[string]bool, len(likes))
+	var postIDs []int64
+	for _, like := range likes {
+		userIDSet[like.UserID] = true
+		if withPosts && like.TargetType == 1 {
+			postIDs = append(postIDs, like.TargetID)
+		}
 	}
+	users := loadUsers(userIDSet)
 
-	// 获取总数
-	query.Count(&total)
+	posts, tombstones := loadLikedPosts(postIDs, viewerID)
 
-	// 获取点赞列表,预加载User和Post信息
-	err := query.Preload("User").
-		Preload("Post").
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(pageSize).
-		Find(&likes).Error
+	for i := range likes {
+		likes[i].User = users[likes[i].UserID]
+		if likes[i].TargetType == 1 && withPosts {
+			if post, ok := posts[likes[i].TargetID]; ok {
+				likes[i].Post = post
+			} else if tombstone, ok := tombstones[likes[i].TargetID]; ok {
+				likes[i].Tombstone = tombstone
+			} else {
+				likes[i].Tombstone = models.RepostTombstoneDeleted
+			}
+		}
+	}
 
-	return likes, total, err
+	return likes, info, nil
 }
 
-// updatePostLikedUsers 更新帖子的点赞用户列表
-func updatePostLikedUsers(postID int64, userID string, isAdd bool) {
-	var moment models.Moment
-	if err := getDB().First(&moment, "id = ?", postID).Error; err != nil {
-		return
+// loadLikedPosts 加载点赞列表中的帖子，已删除或 viewerID 无权查看的帖子只返回墓碑
+func loadLikedPosts(postIDs []int64, viewerID string) (map[int64]*models.Post, map[int64]string) {
+	posts := make(map[int64]*models.Post, len(postIDs))
+	tombstones := make(map[int64]string)
+	if len(postIDs) == 0 {
+		return posts, tombstones
 	}
-	
-	var likedUsers []string
-	if moment.LikedUsers != nil {
-		json.Unmarshal(moment.LikedUsers, &likedUsers)
-	}
-	
-	if isAdd {
-		// 添加用户到点赞列表
-		found := false
-		for _, uid := range likedUsers {
-			if uid == userID {
-				found = true
-				break
-			}
+
+	var found []models.Post
+	getDB().Where("id IN ?", postIDs).Find(&found)
+
+	rel := LoadViewerRelations(viewerID)
+
+	for i := range found {
+		switch {
+		case found[i].Status != models.PostStatusNormal:
+			tombstones[found[i].ID] = models.RepostTombstoneDeleted
+		case !CanViewPost(viewerID, found[i].UserID, found[i].Visibility, rel):
+			tombstones[found[i].ID] = models.RepostTombstoneUnavailable
+		default:
+			posts[found[i].ID] = &found[i]
 		}
-		if !found {
-			likedUsers = append(likedUsers, userID)
+	}
+	return posts, tombstones
+}
+
+// loadViewerLikes 批量查询当前用户对一组目标的回应，没有回应的目标不在结果中
+func loadViewerLikes(userID string, targetType int, targetIDs []int64) map[int64]string {
+	mine := make(map[int64]string, len(targetIDs))
+	if userID == "" || len(targetIDs) == 0 {
+		return mine
+	}
+
+	var own []models.Like
+	getDB().Select("target_id", "reaction").
+		Where("user_id = ? AND target_type = ? AND target_id IN ?", userID, targetType, targetIDs).
+		Find(&own)
+	for _, l := range own {
+		mine[l.TargetID] = l.Reaction
+	}
+	return mine
+}
+
+// GetLikeStatus 批量获取当前用户对一页帖子或评论的点赞状态，结果与 targetIDs 顺序一致
+func GetLikeStatus(userID string, targetType int, targetIDs []int64) ([]LikeStatus, error) {
+	if targetType != models.ReactionTargetPost && targetType != models.ReactionTargetComment {
+		return nil, ErrInvalidLikeTarget
+	}
+	if len(targetIDs) > maxLikeStatusIDs {
+		return nil, ErrTooManyLikeTargets
+	}
+
+	mine := loadViewerLikes(userID, targetType, targetIDs)
+	statuses := make([]LikeStatus, 0, len(targetIDs))
+	for _, id := range targetIDs {
+		statuses = append(statuses, LikeStatus{
+			TargetID: id,
+			Liked:    mine[id] != "",
+			Reaction: mine[id],
+		})
+	}
+	return statuses, nil
+}
+
+// whereLikedByFriends 只保留点赞人是 userID 好友的点赞记录
+func whereLikedByFriends(query *gorm.DB, userID string) *gorm.DB {
+	return query.Where("EXISTS (SELECT 1 FROM friend_relations f WHERE f.user_id = ? AND f.friend_id = likes.user_id AND f.relation_type = 1 AND f.status = 0)", userID)
+}
+
+// attachPostFriendLikes 为帖子批量填充"好友赞过"：最近点赞的几位好友和点赞的好友总数
+func attachPostFriendLikes(posts []models.Post, userID string) {
+	for i := range posts {
+		posts[i].LikedByFriends = []*models.PostAuthor{}
+	}
+	if userID == "" || len(posts) == 0 {
+		return
+	}
+
+	ids := make([]int64, len(posts))
+	for i, p := range posts {
+		ids[i] = p.ID
+	}
+
+	// 用窗口函数一次取出每个帖子最近点赞的几位好友以及点赞的好友总数
+	ranked := whereLikedByFriends(getDB().Model(&models.Like{}), userID).
+		Select("target_id, user_id, ROW_NUMBER() OVER (PARTITION BY target_id ORDER BY created_at DESC, id DESC) AS rn, COUNT(*) OVER (PARTITION BY target_id) AS total").
+		Where("target_type = ? AND target_id IN ?", models.ReactionTargetPost, ids)
+	var rows []struct {
+		TargetID int64
+		UserID   string
+		Total    int
+	}
+	if err := getDB().Table("(?) AS ranked", ranked).
+		Where("rn <= ?", friendLikePreview).
+		Order("target_id, rn").
+		Scan(&rows).Error; err != nil {
+		return
+	}
+
+	userIDSet := make(map[string]bool, len(rows))
+	for _, r := range rows {
+		userIDSet[r.UserID] = true
+	}
+	users := loadUsers(userIDSet)
+
+	byPost := make(map[int64][]*models.PostAuthor, len(posts))
+	totals := make(map[int64]int, len(posts))
+	for _, r := range rows {
+		if u := users[r.UserID]; u != nil {
+			byPost[r.TargetID] = append(byPost[r.TargetID], &models.PostAuthor{UserID: u.ID, Username: u.Username, AvatarURL: u.AvatarURL})
 		}
-	} else {
-		// 从点赞列表中移除用户
-		newLikedUsers := make([]string, 0, len(likedUsers))
-		for _, uid := range likedUsers {
-			if uid != userID {
-				newLikedUsers = append(newLikedUsers, uid)
-			}
+		totals[r.TargetID] = r.Total
+	}
+	for i := range posts {
+		if friends := byPost[posts[i].ID]; friends != nil {
+			posts[i].LikedByFriends = friends
 		}
-		likedUsers = newLikedUsers
+		posts[i].FriendLikeCount = totals[posts[i].ID]
 	}
-	
-	likedUsersJSON, _ := json.Marshal(likedUsers)
-	getDB().Model(&models.Moment{}).Where("id = ?", postID).Update("liked_users", likedUsersJSON)
-}
\ No newline at end of file
+}
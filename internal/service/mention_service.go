@@ -0,0 +1,254 @@
+package service
+
+import (
+	"log"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"gorm.io/gorm"
+)
+
+// 用户名最长字符数，与注册时的校验一致
+const maxUsernameRunes = 20
+
+// mentionPattern 匹配 @ 后紧跟的用户名字符（字母、数字、中文和下划线）
+var mentionPattern = regexp.MustCompile(`@([a-zA-Z0-9_\p{Han}]+)`)
+
+// MentionSuggestion @提及候选用户
+type MentionSuggestion struct {
+	ID        string `json:"id"`
+	Username  string `json:"username"`
+	Avatar    string `json:"avatar"`
+	IsFriend  bool   `json:"isFriend"`
+	Signature string `json:"signature"`
+}
+
+// parseMentions 解析内容中的@提及
+// 用户名后可能直接跟着正文（如 "@张三你好"），因此取能匹配到已注册用户的最长前缀
+func parseMentions(content string) []models.Mention {
+	matches := mentionPattern.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	candidates := make(map[string]bool)
+	for _, m := range matches {
+		runes := []rune(content[m[2]:m[3]])
+		for l := 1; l <= len(runes) && l <= maxUsernameRunes; l++ {
+			candidates[string(runes[:l])] = true
+		}
+	}
+
+	names := make([]string, 0, len(candidates))
+	for name := range candidates {
+		names = append(names, name)
+	}
+	var users []models.User
+	getDB().Select("id", "username").Where("username IN ?", names).Find(&users)
+	if len(users) == 0 {
+		return nil
+	}
+	userByName := make(map[string]*models.User, len(users))
+	for i := range users {
+		userByName[users[i].Username] = &users[i]
+	}
+
+	var mentions []models.Mention
+	for _, m := range matches {
+		runes := []rune(content[m[2]:m[3]])
+		l := len(runes)
+		if l > maxUsernameRunes {
+			l = maxUsernameRunes
+		}
+		for ; l > 0; l-- {
+			user, ok := userByName[string(runes[:l])]
+			if !ok {
+				continue
+			}
+			mentions = append(mentions, models.Mention{
+				UserID:   user.ID,
+				Offset:   utf8.RuneCountInString(content[:m[0]]),
+				Length:   l + 1,
+				Username: user.Username,
+			})
+			break
+		}
+	}
+
+	return mentions
+}
+
+// saveMentions 重新解析并保存帖子/评论中的@提及，返回新的提及列表和此前已提及的用户
+func saveMentions(sourceType int, sourceID, postID int64, content string) ([]models.Mention, map[string]bool, error) {
+	var previousIDs []string
+	getDB().Model(&models.Mention{}).
+		Where("source_type = ? AND source_id = ?", sourceType, sourceID).
+		Pluck("user_id", &previousIDs)
+	previous := make(map[string]bool, len(previousIDs))
+	for _, id := range previousIDs {
+		previous[id] = true
+	}
+
+	mentions := parseMentions(content)
+	err := getDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("source_type = ? AND source_id = ?", sourceType, sourceID).
+			Delete(&models.Mention{}).Error; err != nil {
+			return err
+		}
+		if len(mentions) == 0 {
+			return nil
+		}
+		for i := range mentions {
+			mentions[i].SourceType = sourceType
+			mentions[i].SourceID = sourceID
+			mentions[i].PostID = postID
+		}
+		return tx.Create(&mentions).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return mentions, previous, nil
+}
+
+// notifyMentions 通知被@的用户：跳过作者本人、之前已通知过的用户，以及看不到该帖子的用户
+func notifyMentions(mentions []models.Mention, skip map[string]bool, actorID, postAuthorID string, visibility int, notifType int, targetID, postID int64, content string) {
+	notified := make(map[string]bool)
+	for _, m := range mentions {
+		if m.UserID == actorID || skip[m.UserID] || notified[m.UserID] {
+			continue
+		}
+		notified[m.UserID] = true
+
+		if !CanViewPost(m.UserID, postAuthorID, visibility, nil) || IsBlocked(actorID, m.UserID) {
+			continue
+		}
+		Notify(m.UserID, actorID, notifType, targetID, postID, content)
+	}
+}
+
+// processMentions 保存帖子/评论的@提及并通知新被提及的用户，失败只记录日志不影响主流程
+func processMentions(sourceType int, sourceID, postID int64, postAuthorID string, visibility int, actorID, content string) []models.Mention {
+	mentions, previous, err := saveMentions(sourceType, sourceID, postID, content)
+	if err != nil {
+		log.Printf("⚠️  保存@提及失败: %v", err)
+		return nil
+	}
+
+	notifType := models.NotificationPostMentioned
+	if sourceType == models.MentionSourceComment {
+		notifType = models.NotificationCommentMentioned
+	}
+	notifyMentions(mentions, previous, actorID, postAuthorID, visibility, notifType, sourceID, postID, content)
+
+	return mentions
+}
+
+// attachPostMentions 为帖子列表填充@提及
+func attachPostMentions(posts []models.Post) {
+	ids := make([]int64, len(posts))
+	for i, p := range posts {
+		ids[i] = p.ID
+	}
+	mentions := loadMentions(models.MentionSourcePost, ids)
+	for i := range posts {
+		posts[i].Mentions = mentions[posts[i].ID]
+	}
+}
+
+// attachCommentMentions 为评论列表填充@提及
+func attachCommentMentions(comments []models.Comment) {
+	ids := make([]int64, len(comments))
+	for i, c := range comments {
+		ids[i] = int64(c.ID)
+	}
+	mentions := loadMentions(models.MentionSourceComment, ids)
+	for i := range comments {
+		comments[i].Mentions = mentions[int64(comments[i].ID)]
+	}
+}
+
+// loadMentions 批量加载帖子/评论的@提及，并填充被提及用户的当前用户名
+func loadMentions(sourceType int, sourceIDs []int64) map[int64][]models.Mention {
+	result := make(map[int64][]models.Mention)
+	if len(sourceIDs) == 0 {
+		return result
+	}
+
+	var mentions []models.Mention
+	getDB().Where("source_type = ? AND source_id IN ?", sourceType, sourceIDs).
+		Order("source_id ASC, start_pos ASC").
+		Find(&mentions)
+
+	userIDSet := make(map[string]bool)
+	for _, m := range mentions {
+		userIDSet[m.UserID] = true
+	}
+	users := loadUsers(userIDSet)
+
+	for _, m := range mentions {
+		if u, ok := users[m.UserID]; ok {
+			m.Username = u.Username
+		}
+		result[m.SourceID] = append(result[m.SourceID], m)
+	}
+
+	return result
+}
+
+// GetMentionSuggestions @提及自动补全：按用户名前缀匹配，好友优先，排除自己和存在拉黑关系的用户
+func GetMentionSuggestions(userID, keyword string, limit int) ([]MentionSuggestion, error) {
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	excluded := append(GetBlockedUserIDs(userID), userID)
+	friendIDs := GetFriendIDs(userID)
+	friendSet := make(map[string]bool, len(friendIDs))
+	for _, id := range friendIDs {
+		friendSet[id] = true
+	}
+
+	var users []models.User
+
+	// 先查好友
+	if len(friendIDs) > 0 {
+		query := getDB().Where("id IN ? AND id NOT IN ?", friendIDs, excluded)
+		if keyword != "" {
+			query = query.Where("username LIKE ?", keyword+"%")
+		}
+		if err := query.Order("username ASC").Limit(limit).Find(&users).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// 好友不足时补充其他用户（无关键词时只推荐好友）
+	if len(users) < limit && keyword != "" {
+		var others []models.User
+		query := getDB().Where("username LIKE ? AND id NOT IN ?", keyword+"%", excluded)
+		if len(friendIDs) > 0 {
+			query = query.Where("id NOT IN ?", friendIDs)
+		}
+		if err := query.Order("LENGTH(username) ASC, username ASC").
+			Limit(limit - len(users)).
+			Find(&others).Error; err != nil {
+			return nil, err
+		}
+		users = append(users, others...)
+	}
+
+	suggestions := make([]MentionSuggestion, len(users))
+	for i, u := range users {
+		suggestions[i] = MentionSuggestion{
+			ID:        u.ID,
+			Username:  u.Username,
+			Avatar:    u.AvatarURL,
+			IsFriend:  friendSet[u.ID],
+			Signature: u.Signature,
+		}
+	}
+
+	return suggestions, nil
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/realtime"
+	"github.com/Yw332/campus-moments-go/pkg/config"
+	"gorm.io/gorm"
+)
+
+// 撤回后替换消息内容和会话预览的占位文本
+const recalledMessagePreview = "[消息已撤回]"
+
+var (
+	ErrMessageRecalled       = errors.New("消息已撤回")
+	ErrRecallWindowExpired   = errors.New("消息发送时间过久，无法撤回")
+	ErrEditWindowExpired     = errors.New("消息发送时间过久，无法编辑")
+	ErrMessageNotEditable    = errors.New("只能编辑文本消息")
+	ErrMessageContentMissing = errors.New("消息内容不能为空")
+)
+
+// RecallMessage 撤回消息（仅发送者，且在可撤回时间内）
+// 消息内容替换为占位文本；编辑历史保留供审核，撤回前的文本也记入历史，会话参与者不再能查看
+func RecallMessage(userID string, messageID int64) (*models.Message, error) {
+	message, err := getOwnMessage(userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if time.Since(message.CreatedAt) > messageRecallWindow() {
+		return nil, ErrRecallWindowExpired
+	}
+
+	now := time.Now()
+	recallEvent := map[string]interface{}{
+		"messageId":  message.ID,
+		"senderId":   message.SenderID,
+		"receiverId": message.ReceiverID,
+		"groupId":    message.GroupID,
+		"recalledAt": now,
+	}
+
+	err = getDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(message).Updates(map[string]interface{}{
+			"status":          models.MessageRecalled,
+			"content_preview": recalledMessagePreview,
+			"file_url":        "",
+			"file_size":       0,
+			"recalled_at":     now,
+		}).Error; err != nil {
+			return err
+		}
+
+		if message.MsgType == 1 {
+			if err := tx.Create(&models.MessageEdit{
+				MessageID:  message.ID,
+				OldContent: message.ContentPreview,
+				EditedAt:   now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		// 以该消息为最后一条消息的会话，预览改为占位文本
+		if err := tx.Model(&models.Conversation{}).
+			Where("last_msg_id = ?", message.ID).
+			Update("last_msg_preview", recalledMessagePreview).Error; err != nil {
+			return err
+		}
+
+		// 单聊中对方尚未读过的消息，撤回后不再计入未读数
+		if message.GroupID == 0 && !message.IsRead {
+			if err := tx.Model(&models.Conversation{}).
+				Where("user_id = ? AND peer_id = ? AND unread_count > 0", message.ReceiverID, message.SenderID).
+				Update("unread_count", gorm.Expr("unread_count - ?", 1)).Error; err != nil {
+				return err
+			}
+		}
+
+		return recordMessageSyncEvent(tx, message, models.SyncEventRecall, recallEvent)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	message.Status = models.MessageRecalled
+	message.ContentPreview = recalledMessagePreview
+	message.FileURL = ""
+	message.FileSize = 0
+	message.RecalledAt = &now
+
+	realtime.GetHub().PushToUsers(messageParticipants(message), realtime.EventMessageRecalled, recallEvent)
+	if message.GroupID == 0 && !message.IsRead {
+		pushUnreadCount(message.ReceiverID)
+	}
+
+	return message, nil
+}
+
+// EditMessage 编辑文本消息（仅发送者，且在可编辑时间内），保留编辑前的内容
+func EditMessage(userID string, messageID int64, content string) (*models.Message, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, ErrMessageContentMissing
+	}
+
+	message, err := getOwnMessage(userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.MsgType != 1 {
+		return nil, ErrMessageNotEditable
+	}
+	if time.Since(message.CreatedAt) > messageEditWindow() {
+		return nil, ErrEditWindowExpired
+	}
+	if content == message.ContentPreview {
+		return message, nil
+	}
+
+	now := time.Now()
+	err = getDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.MessageEdit{
+			MessageID:  message.ID,
+			OldContent: message.ContentPreview,
+			EditedAt:   now,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(message).Updates(map[string]interface{}{
+			"content_preview": content,
+			"edited_at":       now,
+		}).Error; err != nil {
+			return err
+		}
+		message.ContentPreview = content
+		message.EditedAt = &now
+
+		if err := tx.Model(&models.Conversation{}).
+			Where("last_msg_id = ?", message.ID).
+			Update("last_msg_preview", content).Error; err != nil {
+			return err
+		}
+
+		return recordMessageSyncEvent(tx, message, models.SyncEventEdit, message)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	realtime.GetHub().PushToUsers(messageParticipants(message), realtime.EventMessageEdited, message)
+
+	return message, nil
+}
+
+// GetMessageEditHistory 获取消息的编辑历史（会话参与者可查看，已撤回的消息返回空列表）
+func GetMessageEditHistory(userID string, messageID int64) ([]models.MessageEdit, error) {
+	var message models.Message
+	if err := getDB().First(&message, messageID).Error; err != nil {
+		return nil, err
+	}
+	if !canViewMessage(userID, &message) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if message.Status == models.MessageRecalled {
+		return []models.MessageEdit{}, nil
+	}
+
+	var edits []models.MessageEdit
+	err := getDB().Where("message_id = ?", messageID).
+		Order("edited_at ASC, id ASC").
+		Find(&edits).Error
+	return edits, err
+}
+
+// getOwnMessage 获取当前用户发送的、未撤回的消息
+func getOwnMessage(userID string, messageID int64) (*models.Message, error) {
+	var message models.Message
+	if err := getDB().Where("id = ? AND sender_id = ?", messageID, userID).First(&message).Error; err != nil {
+		return nil, err
+	}
+	if message.Status == models.MessageRecalled {
+		return nil, ErrMessageRecalled
+	}
+	return &message, nil
+}
+
+// canViewMessage 判断用户是否为消息所在会话的参与者
+func canViewMessage(userID string, message *models.Message) bool {
+	if message.GroupID > 0 {
+		_, err := getGroupMember(message.GroupID, userID)
+		return err == nil
+	}
+	return message.SenderID == userID || message.ReceiverID == userID
+}
+
+// messageParticipants 获取消息所在会话的全部参与者
+func messageParticipants(message *models.Message) []string {
+	if message.GroupID > 0 {
+		return getGroupMemberIDs(message.GroupID)
+	}
+	return []string{message.SenderID, message.ReceiverID}
+}
+
+// recordMessageSyncEvent 为消息所在会话的全部参与者记录同步事件
+func recordMessageSyncEvent(tx *gorm.DB, message *models.Message, eventType string, payload interface{}) error {
+	if message.GroupID > 0 {
+		return recordGroupSyncEvent(tx, getGroupMemberIDs(message.GroupID), eventType, message.GroupID, message.ID, payload)
+	}
+	return recordDirectSyncEvent(tx, message.SenderID, message.ReceiverID, eventType, message.ID, payload)
+}
+
+// messageRecallWindow 消息可撤回时间
+func messageRecallWindow() time.Duration {
+	if config.Cfg != nil && config.Cfg.Message.RecallWindow > 0 {
+		return config.Cfg.Message.RecallWindow
+	}
+	return 2 * time.Minute
+}
+
+// messageEditWindow 消息可编辑时间
+func messageEditWindow() time.Duration {
+	if config.Cfg != nil && config.Cfg.Message.EditWindow > 0 {
+		return config.Cfg.Message.EditWindow
+	}
+	return 15 * time.Minute
+}
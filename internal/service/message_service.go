@@ -1,19 +1,45 @@
 package service
 
 import (
+	"errors"
 	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/realtime"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"time"
 )
 
-// SendMessage 发送消息
-func SendMessage(senderID, receiverID string, msgType int, contentPreview, fileURL string, 
-	fileSize int, isEncrypted bool, deviceID, serverMsgID string) (*models.Message, error) {
+// ErrClientMsgIDConflict 同一设备的 clientMsgId 已用于发给其他会话的消息
+var ErrClientMsgIDConflict = errors.New("clientMsgId 已被其他会话的消息使用")
+
+// SendMessage 发送消息（groupID 大于0时为群消息，忽略 receiverID）
+// clientMsgID 由客户端生成，同一发送者同一设备内唯一；重试时返回首次保存的消息，不会重复发送
+func SendMessage(senderID, receiverID string, groupID int64, msgType int, contentPreview, fileURL string, 
+	fileSize int, isEncrypted bool, deviceID, clientMsgID, serverMsgID string) (*models.Message, error) {
+	
+	// 重试请求：直接返回已保存的消息
+	if clientMsgID != "" {
+		if existing, err := findMessageByClientID(senderID, deviceID, clientMsgID, receiverID, groupID); err == nil {
+			return existing, nil
+		} else if err != gorm.ErrRecordNotFound {
+			return nil, err
+		}
+	}
+	
+	// 单聊双方存在拉黑关系时不允许发送
+	if groupID == 0 && IsBlocked(senderID, receiverID) {
+		return nil, ErrUserBlocked
+	}
+	
+	if serverMsgID == "" {
+		serverMsgID = uuid.New().String()
+	}
 	
 	// 创建消息
 	message := &models.Message{
 		SenderID:       senderID,
 		ReceiverID:     receiverID,
+		GroupID:        groupID,
 		MsgType:        msgType,
 		ContentPreview: contentPreview,
 		FileURL:        fileURL,
@@ -24,6 +50,14 @@ func SendMessage(senderID, receiverID string, msgType int, contentPreview, fileU
 		ServerMsgID:    serverMsgID,
 		CreatedAt:      time.Now(),
 	}
+	if clientMsgID != "" {
+		message.ClientMsgID = &clientMsgID
+	}
+	
+	if groupID > 0 {
+		message.ReceiverID = ""
+		return sendGroupMessage(message)
+	}
 	
 	// 开启事务
 	tx := getDB().Begin()
@@ -31,6 +65,10 @@ func SendMessage(senderID, receiverID string, msgType int, contentPreview, fileU
 	// 保存消息
 	if err := tx.Create(message).Error; err != nil {
 		tx.Rollback()
+		// 并发重试时由唯一索引兜底，返回先保存成功的那条
+		if clientMsgID != "" && isDuplicateKeyError(err) {
+			return findMessageByClientID(senderID, deviceID, clientMsgID, receiverID, groupID)
+		}
 		return nil, err
 	}
 	
@@ -46,6 +84,7 @@ func SendMessage(senderID, receiverID string, msgType int, contentPreview, fileU
 		// 创建新会话
 		conversation = models.Conversation{
 			UserID:          senderID,
+			ConvType:        models.ConversationDirect,
 			PeerID:          receiverID,
 			LastMsgID:       message.ID,
 			LastMsgPreview:  contentPreview,
@@ -82,6 +121,7 @@ func SendMessage(senderID, receiverID string, msgType int, contentPreview, fileU
 		// 创建接收者的会话
 		receiverConversation = models.Conversation{
 			UserID:          receiverID,
+			ConvType:        models.ConversationDirect,
 			PeerID:          senderID,
 			LastMsgID:       message.ID,
 			LastMsgPreview:  contentPreview,
@@ -110,11 +150,39 @@ func SendMessage(senderID, receiverID string, msgType int, contentPreview, fileU
 		return nil, err
 	}
 	
-	tx.Commit()
+	// 记录双方的多端同步事件
+	if err := recordDirectSyncEvent(tx, senderID, receiverID, models.SyncEventMessage, message.ID, message); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	
+	// 实时推送给接收者的所有设备，并同步给发送者的其他设备
+	hub := realtime.GetHub()
+	hub.PushToUser(receiverID, realtime.EventNewMessage, message)
+	hub.PushToUser(senderID, realtime.EventNewMessage, message)
+	pushUnreadCount(receiverID)
 	
 	return message, nil
 }
 
+// findMessageByClientID 按客户端消息ID查找已保存的消息，已保存的消息发往其他会话时返回 ErrClientMsgIDConflict
+func findMessageByClientID(senderID, deviceID, clientMsgID, receiverID string, groupID int64) (*models.Message, error) {
+	var message models.Message
+	err := getDB().Where("sender_id = ? AND device_id = ? AND client_msg_id = ?", senderID, deviceID, clientMsgID).
+		First(&message).Error
+	if err != nil {
+		return nil, err
+	}
+	if message.GroupID != groupID || (groupID == 0 && message.ReceiverID != receiverID) {
+		return nil, ErrClientMsgIDConflict
+	}
+	return &message, nil
+}
+
 // GetMessageList 获取消息列表
 func GetMessageList(userID, peerID, beforeMsgID string, page, pageSize int) ([]models.Message, int64, error) {
 	var messages []models.Message
@@ -145,10 +213,13 @@ func GetMessageList(userID, peerID, beforeMsgID string, page, pageSize int) ([]m
 		messages[i], messages[j] = messages[j], messages[i]
 	}
 	
+	// 填充表情回应
+	attachMessageReactions(messages, userID)
+	
 	return messages, total, err
 }
 
-// GetConversationList 获取会话列表
+// GetConversationList 获取会话列表（单聊与群聊按置顶、最后活跃时间统一排序）
 func GetConversationList(userID string, page, pageSize int) ([]models.Conversation, int64, error) {
 	var conversations []models.Conversation
 	var total int64
@@ -161,35 +232,110 @@ func GetConversationList(userID string, page, pageSize int) ([]models.Conversati
 	query.Count(&total)
 	
 	// 获取会话列表
-	err := query.Preload("User").
-		Preload("Peer").
-		Preload("LastMessage").
-		Order("is_pinned DESC, updated_at DESC").
+	err := query.Order("is_pinned DESC, updated_at DESC").
 		Offset(offset).
 		Limit(pageSize).
 		Find(&conversations).Error
+	if err != nil {
+		return nil, 0, err
+	}
 	
-	return conversations, total, err
+	fillConversations(conversations)
+	
+	return conversations, total, nil
 }
 
-// MarkMessagesAsRead 标记消息为已读
-func MarkMessagesAsRead(userID, peerID string) error {
-	// 标记对方发给自己的消息为已读
-	result := getDB().Model(&models.Message{}).
-		Where("sender_id = ? AND receiver_id = ? AND is_read = ?", peerID, userID, false).
-		Updates(map[string]interface{}{
-			"is_read": true,
-		})
+// fillConversations 填充会话的对方用户、群信息和最后一条消息
+func fillConversations(conversations []models.Conversation) {
+	peerIDSet := make(map[string]bool)
+	var groupIDs, msgIDs []int64
+	for _, conv := range conversations {
+		if conv.ConvType == models.ConversationGroup {
+			groupIDs = append(groupIDs, conv.GroupID)
+		} else {
+			peerIDSet[conv.PeerID] = true
+		}
+		if conv.LastMsgID > 0 {
+			msgIDs = append(msgIDs, conv.LastMsgID)
+		}
+	}
 	
-	if result.Error != nil {
-		return result.Error
+	peers := loadUsers(peerIDSet)
+	
+	groups := make(map[int64]*models.ChatGroup)
+	if len(groupIDs) > 0 {
+		var groupList []models.ChatGroup
+		getDB().Where("id IN ?", groupIDs).Find(&groupList)
+		for i := range groupList {
+			groups[groupList[i].ID] = &groupList[i]
+		}
+	}
+	
+	lastMessages := make(map[int64]*models.Message)
+	if len(msgIDs) > 0 {
+		var messages []models.Message
+		getDB().Where("id IN ?", msgIDs).Find(&messages)
+		for i := range messages {
+			lastMessages[messages[i].ID] = &messages[i]
+		}
 	}
 	
-	// 重置会话的未读数
-	if result.RowsAffected > 0 {
-		getDB().Model(&models.Conversation{}).
+	for i := range conversations {
+		conv := &conversations[i]
+		if conv.ConvType == models.ConversationGroup {
+			conv.Group = groups[conv.GroupID]
+		} else {
+			conv.Peer = peers[conv.PeerID]
+		}
+		conv.LastMessage = lastMessages[conv.LastMsgID]
+	}
+}
+
+// MarkMessagesAsRead 标记消息为已读
+func MarkMessagesAsRead(userID, peerID string) error {
+	var rowsAffected int64
+	var receipt map[string]interface{}
+	
+	err := getDB().Transaction(func(tx *gorm.DB) error {
+		// 标记对方发给自己的消息为已读
+		result := tx.Model(&models.Message{}).
+			Where("sender_id = ? AND receiver_id = ? AND is_read = ?", peerID, userID, false).
+			Updates(map[string]interface{}{
+				"is_read": true,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		rowsAffected = result.RowsAffected
+		if rowsAffected == 0 {
+			return nil
+		}
+		
+		// 重置会话的未读数
+		if err := tx.Model(&models.Conversation{}).
 			Where("user_id = ? AND peer_id = ?", userID, peerID).
-			Update("unread_count", 0)
+			Update("unread_count", 0).Error; err != nil {
+			return err
+		}
+		
+		receipt = map[string]interface{}{
+			"readerId": userID,
+			"peerId":   peerID,
+			"count":    rowsAffected,
+			"readAt":   time.Now(),
+		}
+		return recordDirectSyncEvent(tx, userID, peerID, models.SyncEventRead, 0, receipt)
+	})
+	if err != nil {
+		return err
+	}
+	
+	if rowsAffected > 0 {
+		// 已读回执推送给对方，未读数同步给自己的所有设备
+		hub := realtime.GetHub()
+		hub.PushToUser(peerID, realtime.EventMessageRead, receipt)
+		hub.PushToUser(userID, realtime.EventMessageRead, receipt)
+		pushUnreadCount(userID)
 	}
 	
 	return nil
@@ -209,7 +355,9 @@ func PinConversation(userID, peerID string) error {
 		return gorm.ErrRecordNotFound
 	}
 	
-	return nil
+	return recordSyncEvent(getDB(), userID, models.SyncEventConversationUpdated, peerID, 0, 0, map[string]interface{}{
+		"isPinned": true,
+	})
 }
 
 // UnpinConversation 取消置顶会话
@@ -226,7 +374,9 @@ func UnpinConversation(userID, peerID string) error {
 		return gorm.ErrRecordNotFound
 	}
 	
-	return nil
+	return recordSyncEvent(getDB(), userID, models.SyncEventConversationUpdated, peerID, 0, 0, map[string]interface{}{
+		"isPinned": false,
+	})
 }
 
 // MuteConversation 静音会话
@@ -243,7 +393,9 @@ func MuteConversation(userID, peerID string) error {
 		return gorm.ErrRecordNotFound
 	}
 	
-	return nil
+	return recordSyncEvent(getDB(), userID, models.SyncEventConversationUpdated, peerID, 0, 0, map[string]interface{}{
+		"isMuted": true,
+	})
 }
 
 // UnmuteConversation 取消静音会话
@@ -260,7 +412,9 @@ func UnmuteConversation(userID, peerID string) error {
 		return gorm.ErrRecordNotFound
 	}
 	
-	return nil
+	return recordSyncEvent(getDB(), userID, models.SyncEventConversationUpdated, peerID, 0, 0, map[string]interface{}{
+		"isMuted": false,
+	})
 }
 
 // DeleteConversation 删除会话
@@ -281,7 +435,9 @@ func DeleteConversation(userID, peerID string) error {
 	// 注意：这里不删除消息记录，只是删除会话列表显示
 	// 如果需要彻底删除消息，可以另外提供接口
 	
-	return nil
+	return recordSyncEvent(getDB(), userID, models.SyncEventConversationDeleted, peerID, 0, 0, map[string]interface{}{
+		"peerId": peerID,
+	})
 }
 
 // GetUnreadCount 获取未读消息数
@@ -295,4 +451,20 @@ func GetUnreadCount(userID string) (int, error) {
 		Scan(&total)
 	
 	return int(total), nil
-}
\ No newline at end of file
+}
+
+// pushUnreadCount 推送用户最新的未读消息总数
+func pushUnreadCount(userID string) {
+	hub := realtime.GetHub()
+	if !hub.IsOnline(userID) {
+		return
+	}
+	
+	count, err := GetUnreadCount(userID)
+	if err != nil {
+		return
+	}
+	hub.PushToUser(userID, realtime.EventUnreadCount, map[string]interface{}{
+		"unreadCount": count,
+	})
+}
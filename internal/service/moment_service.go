@@ -93,6 +93,9 @@ func (s *MomentService) CreateMoment(userID string, req *CreateMomentRequest) (*
 		return nil, fmt.Errorf("加载作者信息失败: %w", err)
 	}
 
+	// 写入相关用户的首页时间线
+	fanOutPost(int64(moment.ID))
+
 	return moment, nil
 }
 
@@ -115,49 +118,42 @@ func (s *MomentService) GetMomentByID(id int64) (*models.Moment, error) {
 	return &moment, nil
 }
 
-// ListMoments 获取动态列表（支持分页）
-func (s *MomentService) ListMoments(page, pageSize int, userID *string) ([]models.Moment, int64, error) {
+// ListMoments 获取动态列表（支持页码分页和游标分页）
+func (s *MomentService) ListMoments(opts PageOptions, userID *string) ([]models.Moment, PageInfo, error) {
 	db := s.getDB()
 	if db == nil {
-		return nil, 0, errors.New("数据库未连接")
-	}
-
-	if page < 1 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = 10
-	}
-	if pageSize > 100 {
-		pageSize = 100 // 限制最大分页
+		return nil, PageInfo{}, errors.New("数据库未连接")
 	}
 
-	offset := (page - 1) * pageSize
+	opts = normalizePage(opts)
 
 	var moments []models.Moment
-	var total int64
 
-	query := db.Preload("User").Where("status = 0")
+	query := db.Model(&models.Moment{}).Where("status = 0")
 
 	// 如果指定了用户ID，则查询该用户的动态
 	if userID != nil {
-		query = query.Where("user_id = ?", *userID)
+		query = query.Where("user_id = ? AND anonymous = ?", *userID, false)
 	}
 
-	// 查询总数
-	if err := query.Model(&models.Moment{}).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("查询总数失败: %w", err)
+	query, info, err := paginate(query, opts, true)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			return nil, info, err
+		}
+		return nil, info, fmt.Errorf("查询总数失败: %w", err)
 	}
 
 	// 查询列表
-	if err := query.Order("created_at DESC").
-		Offset(offset).
-		Limit(pageSize).
-		Find(&moments).Error; err != nil {
-		return nil, 0, fmt.Errorf("查询动态列表失败: %w", err)
+	if err := query.Preload("User").Find(&moments).Error; err != nil {
+		return nil, info, fmt.Errorf("查询动态列表失败: %w", err)
 	}
 
-	return moments, total, nil
+	moments = finishPage(moments, opts, &info, func(m models.Moment) string {
+		return encodeCursor(m.CreatedAt, int64(m.ID))
+	})
+
+	return moments, info, nil
 }
 
 // UpdateMoment 更新动态
@@ -178,8 +174,9 @@ func (s *MomentService) UpdateMoment(userID string, momentID int64, req *UpdateM
 	}
 
 	// 更新字段
+	now := time.Now()
 	updates := make(map[string]interface{})
-	updates["updated_at"] = time.Now()
+	updates["updated_at"] = now
 
 	if req.Content != nil {
 		updates["content"] = *req.Content
@@ -191,14 +188,38 @@ func (s *MomentService) UpdateMoment(userID string, momentID int64, req *UpdateM
 		updates["media"] = models.MediaItems(req.Media)
 	}
 	if req.Visibility != nil {
+		if err := checkRepostVisibility(moment.RepostOfID, *req.Visibility); err != nil {
+			return nil, err
+		}
 		updates["visibility"] = *req.Visibility
 	}
 
+	oldVisibility := moment.Visibility
+
+	// 编辑前的内容，用于保存历史版本
+	var before models.Post
+	if err := db.First(&before, "id = ?", momentID).Error; err != nil {
+		return nil, fmt.Errorf("查询动态失败: %w", err)
+	}
+
 	// 执行更新
-	if err := db.Model(&moment).Updates(updates).Error; err != nil {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&moment).Updates(updates).Error; err != nil {
+			return err
+		}
+		_, err := recordPostRevision(tx, &before, now)
+		return err
+	})
+	if err != nil {
 		return nil, fmt.Errorf("更新动态失败: %w", err)
 	}
 
+	// 可见性变化时重新写入时间线
+	if req.Visibility != nil && *req.Visibility != oldVisibility {
+		removeFromTimelines(momentID, userID)
+		fanOutPost(momentID)
+	}
+
 	// 重新加载完整数据
 	if err := db.Preload("User").First(&moment, moment.ID).Error; err != nil {
 		return nil, fmt.Errorf("重新加载动态失败: %w", err)
@@ -232,6 +253,10 @@ func (s *MomentService) DeleteMoment(userID string, momentID int64) error {
 		return fmt.Errorf("删除动态失败: %w", err)
 	}
 
+	pushPostDeleted(int64(moment.ID), moment.UserID, moment.Visibility)
+	removeFromTimelines(int64(moment.ID), moment.UserID)
+	onRepostRemoved(moment.RepostOfID)
+
 	return nil
 }
 
@@ -260,10 +285,14 @@ func (s *MomentService) AdminDeleteMoment(momentID int64) error {
 		return fmt.Errorf("删除动态失败: %w", err)
 	}
 
+	pushPostDeleted(int64(moment.ID), moment.UserID, moment.Visibility)
+	removeFromTimelines(int64(moment.ID), moment.UserID)
+	onRepostRemoved(moment.RepostOfID)
+
 	return nil
 }
 
 // GetUserMoments 获取用户的所有动态
-func (s *MomentService) GetUserMoments(userID string, page, pageSize int) ([]models.Moment, int64, error) {
-	return s.ListMoments(page, pageSize, &userID)
+func (s *MomentService) GetUserMoments(userID string, opts PageOptions) ([]models.Moment, PageInfo, error) {
+	return s.ListMoments(opts, &userID)
 }
\ No newline at end of file
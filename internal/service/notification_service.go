@@ -0,0 +1,371 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/realtime"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 聚合展示时返回的最近触发者数量
+const notificationPreviewActors = 3
+
+// aggregatableNotifications 会按目标聚合的通知类型
+var aggregatableNotifications = map[int]bool{
+	models.NotificationPostLiked:     true,
+	models.NotificationCommentLiked:  true,
+	models.NotificationPostCommented: true,
+	models.NotificationPostReposted:  true,
+}
+
+// likeNotifications 点赞类通知，同一个人重复触发时不再提醒
+var likeNotifications = map[int]bool{
+	models.NotificationPostLiked:    true,
+	models.NotificationCommentLiked: true,
+}
+
+// notificationActions 通知摘要中的动作描述
+var notificationActions = map[int]string{
+	models.NotificationPostLiked:             "赞了你的帖子",
+	models.NotificationCommentLiked:          "赞了你的评论",
+	models.NotificationPostCommented:         "评论了你的帖子",
+	models.NotificationCommentReplied:        "回复了你的评论",
+	models.NotificationFriendRequestReceived: "请求添加你为好友",
+	models.NotificationFriendRequestAccepted: "同意了你的好友请求",
+	models.NotificationPostMentioned:         "在帖子中提到了你",
+	models.NotificationCommentMentioned:      "在评论中提到了你",
+	models.NotificationPostReposted:          "转发了你的帖子",
+}
+
+// NotificationItem 通知列表项
+type NotificationItem struct {
+	models.Notification
+	TypeName string `json:"typeName"`
+	Summary  string `json:"summary"` // 例如 "张三等13人赞了你的帖子"
+}
+
+// Notify 记录一条通知；可聚合的类型会合并到同一目标的未读通知中
+// 通知是附带行为，失败只记录日志，不影响主流程
+func Notify(userID, actorID string, notifType int, targetID, postID int64, content string) {
+	if userID == "" || userID == actorID {
+		return
+	}
+	// 树洞帖子及楼主化名评论的通知发给真实作者
+	if userID == models.AnonymousUserID {
+		if userID = anonymousAuthorID(postID); userID == "" || userID == actorID {
+			return
+		}
+	}
+
+	notification, err := saveNotification(userID, actorID, notifType, targetID, postID, truncateRunes(content, 100))
+	if err != nil {
+		log.Printf("⚠️  记录通知失败: %v", err)
+		return
+	}
+	if notification == nil {
+		return
+	}
+
+	// 实时推送
+	hub := realtime.GetHub()
+	if hub.IsOnline(userID) {
+		items := buildNotificationItems([]models.Notification{*notification})
+		hub.PushToUser(userID, realtime.EventNotification, items[0])
+	}
+}
+
+// saveNotification 写入或聚合通知，返回最新的通知记录（同一个人重复点赞时返回nil）
+func saveNotification(userID, actorID string, notifType int, targetID, postID int64, content string) (*models.Notification, error) {
+	now := time.Now()
+	var notification models.Notification
+	changed := true
+
+	err := getDB().Transaction(func(tx *gorm.DB) error {
+		groupKey := ""
+		if aggregatableNotifications[notifType] {
+			groupKey = fmt.Sprintf("%d:%d", notifType, targetID)
+
+			// 查找同一目标的未读通知并加锁
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("user_id = ? AND group_key = ? AND is_read = ?", userID, groupKey, false).
+				Order("id DESC").
+				First(&notification).Error
+			if err == nil {
+				actor := models.NotificationActor{NotificationID: notification.ID, ActorID: actorID, CreatedAt: now}
+				result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&actor)
+				if result.Error != nil {
+					return result.Error
+				}
+				updates := map[string]interface{}{
+					"actor_id":   actorID,
+					"content":    content,
+					"updated_at": now,
+				}
+				if result.RowsAffected > 0 {
+					updates["actor_count"] = gorm.Expr("actor_count + ?", 1)
+				} else if likeNotifications[notifType] {
+					// 同一个人重复点赞（如取消后再次点赞），不重复提醒
+					changed = false
+					return nil
+				} else {
+					// 同一个人再次评论或转发：人数不变，更新内容并把此人排到最近触发者的最前面
+					if err := tx.Model(&models.NotificationActor{}).
+						Where("notification_id = ? AND actor_id = ?", notification.ID, actorID).
+						Update("created_at", now).Error; err != nil {
+						return err
+					}
+				}
+
+				if err := tx.Model(&notification).Updates(updates).Error; err != nil {
+					return err
+				}
+				return tx.First(&notification, notification.ID).Error
+			} else if err != gorm.ErrRecordNotFound {
+				return err
+			}
+		}
+
+		notification = models.Notification{
+			UserID:     userID,
+			Type:       notifType,
+			ActorID:    actorID,
+			ActorCount: 1,
+			TargetID:   targetID,
+			PostID:     postID,
+			Content:    content,
+			GroupKey:   groupKey,
+			IsRead:     false,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := tx.Create(&notification).Error; err != nil {
+			return err
+		}
+
+		actor := models.NotificationActor{NotificationID: notification.ID, ActorID: actorID, CreatedAt: now}
+		return tx.Create(&actor).Error
+	})
+	if err != nil || !changed {
+		return nil, err
+	}
+
+	return &notification, nil
+}
+
+// GetNotificationList 游标分页获取通知列表（按最近更新时间倒序）
+func GetNotificationList(userID string, notifType int, cursor string, limit int) ([]NotificationItem, string, bool, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	query := getDB().Model(&models.Notification{}).Where("user_id = ?", userID)
+	if notifType > 0 {
+		query = query.Where("type = ?", notifType)
+	}
+
+	if cursor != "" {
+		updatedAt, id, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", false, err
+		}
+		query = query.Where("updated_at < ? OR (updated_at = ? AND id < ?)", updatedAt, updatedAt, id)
+	}
+
+	var notifications []models.Notification
+	if err := query.Order("updated_at DESC, id DESC").Limit(limit + 1).Find(&notifications).Error; err != nil {
+		return nil, "", false, err
+	}
+
+	hasMore := len(notifications) > limit
+	if hasMore {
+		notifications = notifications[:limit]
+	}
+
+	nextCursor := ""
+	if hasMore {
+		last := notifications[len(notifications)-1]
+		nextCursor = encodeCursor(last.UpdatedAt, last.ID)
+	}
+
+	return buildNotificationItems(notifications), nextCursor, hasMore, nil
+}
+
+// MarkNotificationRead 标记单条通知为已读
+func MarkNotificationRead(userID string, notificationID int64) error {
+	result := getDB().Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", notificationID, userID).
+		Update("is_read", true)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// 已读时不会影响行数，需要确认通知存在
+	if result.RowsAffected == 0 {
+		var count int64
+		getDB().Model(&models.Notification{}).Where("id = ? AND user_id = ?", notificationID, userID).Count(&count)
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
+	}
+
+	return nil
+}
+
+// MarkAllNotificationsRead 标记全部（或某一类型）通知为已读，返回标记数量
+func MarkAllNotificationsRead(userID string, notifType int) (int64, error) {
+	query := getDB().Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", userID, false)
+	if notifType > 0 {
+		query = query.Where("type = ?", notifType)
+	}
+
+	result := query.Update("is_read", true)
+	return result.RowsAffected, result.Error
+}
+
+// GetNotificationUnreadCount 获取未读通知数（总数及按类型统计）
+func GetNotificationUnreadCount(userID string) (int64, map[string]int64, error) {
+	var rows []struct {
+		Type  int
+		Count int64
+	}
+
+	if err := getDB().Model(&models.Notification{}).
+		Select("type, COUNT(*) AS count").
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Group("type").
+		Scan(&rows).Error; err != nil {
+		return 0, nil, err
+	}
+
+	var total int64
+	byType := make(map[string]int64, len(models.NotificationTypeNames))
+	for _, name := range models.NotificationTypeNames {
+		byType[name] = 0
+	}
+	for _, row := range rows {
+		if name, ok := models.NotificationTypeNames[row.Type]; ok {
+			byType[name] = row.Count
+		}
+		total += row.Count
+	}
+
+	return total, byType, nil
+}
+
+// ParseNotificationType 将对外类型名转换为类型值，未知类型返回0
+func ParseNotificationType(name string) int {
+	for t, n := range models.NotificationTypeNames {
+		if n == name {
+			return t
+		}
+	}
+	return 0
+}
+
+// buildNotificationItems 加载最近触发者并生成摘要
+func buildNotificationItems(notifications []models.Notification) []NotificationItem {
+	items := make([]NotificationItem, len(notifications))
+	if len(notifications) == 0 {
+		return items
+	}
+
+	ids := make([]int64, len(notifications))
+	for i, n := range notifications {
+		ids[i] = n.ID
+	}
+
+	// 用窗口函数一次取出每条通知最近的几位触发者
+	ranked := getDB().Model(&models.NotificationActor{}).
+		Select("notification_id, actor_id, ROW_NUMBER() OVER (PARTITION BY notification_id ORDER BY created_at DESC, id DESC) AS rn").
+		Where("notification_id IN ?", ids)
+	var actors []struct {
+		NotificationID int64
+		ActorID        string
+	}
+	getDB().Table("(?) AS ranked", ranked).
+		Where("rn <= ?", notificationPreviewActors).
+		Order("notification_id, rn").
+		Scan(&actors)
+
+	actorIDs := make(map[int64][]string)
+	userIDSet := make(map[string]bool)
+	for _, a := range actors {
+		actorIDs[a.NotificationID] = append(actorIDs[a.NotificationID], a.ActorID)
+		userIDSet[a.ActorID] = true
+	}
+
+	users := loadUsers(userIDSet)
+
+	// 楼主以化名评论或回复时触发者显示为帖子的化名
+	var anonymousPostIDs []int64
+	if userIDSet[models.AnonymousUserID] {
+		for _, n := range notifications {
+			anonymousPostIDs = append(anonymousPostIDs, n.PostID)
+		}
+	}
+	pseudonyms := anonymousUsers(anonymousPostIDs)
+
+	for i, n := range notifications {
+		for _, uid := range actorIDs[n.ID] {
+			u, ok := users[uid]
+			if uid == models.AnonymousUserID {
+				u, ok = pseudonyms[n.PostID]
+			}
+			if ok {
+				n.Actors = append(n.Actors, &models.PostAuthor{UserID: u.ID, Username: u.Username, AvatarURL: u.AvatarURL})
+			}
+		}
+
+		actorName := "有人"
+		if len(n.Actors) > 0 {
+			actorName = n.Actors[0].Username
+		}
+		summary := actorName + notificationActions[n.Type]
+		if n.ActorCount > 1 {
+			summary = fmt.Sprintf("%s等%d人%s", actorName, n.ActorCount, notificationActions[n.Type])
+		}
+
+		items[i] = NotificationItem{
+			Notification: n,
+			TypeName:     models.NotificationTypeNames[n.Type],
+			Summary:      summary,
+		}
+	}
+
+	return items
+}
+
+// loadUsers 批量加载用户信息
+func loadUsers(userIDSet map[string]bool) map[string]*models.User {
+	result := make(map[string]*models.User, len(userIDSet))
+	if len(userIDSet) == 0 {
+		return result
+	}
+
+	ids := make([]string, 0, len(userIDSet))
+	for id := range userIDSet {
+		ids = append(ids, id)
+	}
+
+	var users []models.User
+	getDB().Where("id IN ?", ids).Find(&users)
+	for i := range users {
+		result[users[i].ID] = &users[i]
+	}
+
+	return result
+}
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
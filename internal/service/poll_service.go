@@ -0,0 +1,340 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrPollOptionCount     = errors.New("投票选项数量必须为2到10个")
+	ErrPollOptionInvalid   = errors.New("投票选项不能为空、不能重复，且不超过100个字")
+	ErrPollDeadlineInvalid = errors.New("投票截止时间必须晚于发布时间")
+	ErrPollNotFound        = errors.New("该帖子没有投票")
+	ErrPollClosed          = errors.New("投票已截止")
+	ErrAlreadyVoted        = errors.New("已经投过票，如需修改请先撤销")
+	ErrNotVoted            = errors.New("还没有投票")
+	ErrInvalidPollChoice   = errors.New("投票选项无效")
+	ErrPollVotersHidden    = errors.New("匿名投票不公开投票人")
+	ErrPollResultsHidden   = errors.New("投票后才能查看结果")
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 10
+	maxPollOptionLength = 100
+)
+
+// PollInput 创建帖子时附带的投票
+type PollInput struct {
+	Options               []string   `json:"options"`
+	MultipleChoice        bool       `json:"multipleChoice"`
+	Anonymous             bool       `json:"anonymous"`
+	HideResultsUntilVoted bool       `json:"hideResultsUntilVoted"`
+	Deadline              *time.Time `json:"deadline"`
+}
+
+// validatePollInput 校验投票参数并去掉选项首尾空白，publishAt 为帖子的发布时间
+func validatePollInput(input *PollInput, publishAt time.Time) error {
+	if len(input.Options) < minPollOptions || len(input.Options) > maxPollOptions {
+		return ErrPollOptionCount
+	}
+
+	seen := make(map[string]bool, len(input.Options))
+	for i, option := range input.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || seen[option] || utf8.RuneCountInString(option) > maxPollOptionLength {
+			return ErrPollOptionInvalid
+		}
+		seen[option] = true
+		input.Options[i] = option
+	}
+
+	if input.Deadline != nil && !input.Deadline.After(publishAt) {
+		return ErrPollDeadlineInvalid
+	}
+	return nil
+}
+
+// createPoll 在创建帖子的事务中创建投票和选项
+func createPoll(tx *gorm.DB, postID int64, input *PollInput) (*models.Poll, error) {
+	poll := &models.Poll{
+		PostID:                postID,
+		MultipleChoice:        input.MultipleChoice,
+		Anonymous:             input.Anonymous,
+		HideResultsUntilVoted: input.HideResultsUntilVoted,
+		Deadline:              input.Deadline,
+		CreatedAt:             time.Now(),
+	}
+	if err := tx.Create(poll).Error; err != nil {
+		return nil, err
+	}
+
+	poll.Options = make([]models.PollOption, len(input.Options))
+	for i, text := range input.Options {
+		poll.Options[i] = models.PollOption{PollID: poll.ID, Position: i + 1, Text: text}
+	}
+	if err := tx.Create(&poll.Options).Error; err != nil {
+		return nil, err
+	}
+
+	poll.MyOptionIDs = []int64{}
+	return poll, nil
+}
+
+// pollClosed 投票是否已截止
+func pollClosed(poll *models.Poll) bool {
+	return poll.Deadline != nil && !time.Now().Before(*poll.Deadline)
+}
+
+// lockPoll 在事务中锁定帖子的投票，同一投票的投票和撤销串行执行
+func lockPoll(tx *gorm.DB, postID int64) (*models.Poll, error) {
+	var poll models.Poll
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&poll, "post_id = ?", postID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPollNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pollClosed(&poll) {
+		return nil, ErrPollClosed
+	}
+	return &poll, nil
+}
+
+// VotePoll 投票，单选只能选一个选项，每人只能投一次
+func VotePoll(userID string, postID int64, optionIDs []int64) (*models.Poll, error) {
+	post, err := findVisiblePost(userID, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 去重
+	seen := make(map[int64]bool, len(optionIDs))
+	choices := make([]int64, 0, len(optionIDs))
+	for _, id := range optionIDs {
+		if !seen[id] {
+			seen[id] = true
+			choices = append(choices, id)
+		}
+	}
+
+	err = getDB().Transaction(func(tx *gorm.DB) error {
+		poll, err := lockPoll(tx, postID)
+		if err != nil {
+			return err
+		}
+
+		if len(choices) == 0 || (!poll.MultipleChoice && len(choices) > 1) {
+			return ErrInvalidPollChoice
+		}
+		var validCount int64
+		if err := tx.Model(&models.PollOption{}).
+			Where("poll_id = ? AND id IN ?", poll.ID, choices).
+			Count(&validCount).Error; err != nil {
+			return err
+		}
+		if int(validCount) != len(choices) {
+			return ErrInvalidPollChoice
+		}
+
+		var votedCount int64
+		if err := tx.Model(&models.PollVote{}).
+			Where("poll_id = ? AND user_id = ?", poll.ID, userID).
+			Count(&votedCount).Error; err != nil {
+			return err
+		}
+		if votedCount > 0 {
+			return ErrAlreadyVoted
+		}
+
+		now := time.Now()
+		votes := make([]models.PollVote, len(choices))
+		for i, optionID := range choices {
+			votes[i] = models.PollVote{PollID: poll.ID, UserID: userID, OptionID: optionID, CreatedAt: now}
+		}
+		if err := tx.Create(&votes).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.PollOption{}).Where("id IN ?", choices).
+			UpdateColumn("vote_count", gorm.Expr("vote_count + ?", 1)).Error; err != nil {
+			return err
+		}
+		return tx.Model(poll).UpdateColumn("voter_count", gorm.Expr("voter_count + ?", 1)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loadPostPoll(post, userID), nil
+}
+
+// UnvotePoll 撤销投票，截止后不能撤销
+func UnvotePoll(userID string, postID int64) (*models.Poll, error) {
+	post, err := findVisiblePost(userID, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = getDB().Transaction(func(tx *gorm.DB) error {
+		poll, err := lockPoll(tx, postID)
+		if err != nil {
+			return err
+		}
+
+		var optionIDs []int64
+		if err := tx.Model(&models.PollVote{}).
+			Where("poll_id = ? AND user_id = ?", poll.ID, userID).
+			Pluck("option_id", &optionIDs).Error; err != nil {
+			return err
+		}
+		if len(optionIDs) == 0 {
+			return ErrNotVoted
+		}
+
+		if err := tx.Where("poll_id = ? AND user_id = ?", poll.ID, userID).Delete(&models.PollVote{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.PollOption{}).Where("id IN ?", optionIDs).
+			UpdateColumn("vote_count", gorm.Expr("GREATEST(vote_count - ?, 0)", 1)).Error; err != nil {
+			return err
+		}
+		return tx.Model(poll).UpdateColumn("voter_count", gorm.Expr("GREATEST(voter_count - ?, 0)", 1)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loadPostPoll(post, userID), nil
+}
+
+// loadPostPoll 重新加载单个帖子的投票（当前用户视角）
+func loadPostPoll(post *models.Post, userID string) *models.Poll {
+	posts := []models.Post{*post}
+	attachPolls(posts, userID)
+	return posts[0].Poll
+}
+
+// GetPollVoters 获取公开投票的投票人，optionID 为0时返回所有选项的投票人，按投票时间倒序
+func GetPollVoters(userID string, postID, optionID int64, opts PageOptions) ([]models.PollVote, PageInfo, error) {
+	var info PageInfo
+
+	post, err := findVisiblePost(userID, postID)
+	if err != nil {
+		return nil, info, err
+	}
+	poll := loadPostPoll(post, userID)
+	if poll == nil {
+		return nil, info, ErrPollNotFound
+	}
+	if poll.Anonymous {
+		return nil, info, ErrPollVotersHidden
+	}
+	if poll.ResultsHidden {
+		return nil, info, ErrPollResultsHidden
+	}
+
+	opts = normalizePage(opts)
+	query := getDB().Model(&models.PollVote{}).Where("poll_id = ?", poll.ID)
+	if optionID > 0 {
+		query = query.Where("option_id = ?", optionID)
+	}
+
+	query, info, err = paginate(query, opts, true)
+	if err != nil {
+		return nil, info, err
+	}
+
+	var votes []models.PollVote
+	if err := query.Find(&votes).Error; err != nil {
+		return nil, info, err
+	}
+	votes = finishPage(votes, opts, &info, func(v models.PollVote) string {
+		return encodeCursor(v.CreatedAt, v.ID)
+	})
+
+	userIDSet := make(map[string]bool, len(votes))
+	for _, v := range votes {
+		userIDSet[v.UserID] = true
+	}
+	users := loadUsers(userIDSet)
+	for i := range votes {
+		votes[i].User = users[votes[i].UserID]
+	}
+
+	return votes, info, nil
+}
+
+// attachPolls 为帖子填充投票、实时票数和当前用户的投票情况
+// 作者选择投票前隐藏结果时，未投票的其他用户在截止前看不到票数
+func attachPolls(posts []models.Post, userID string) {
+	if len(posts) == 0 {
+		return
+	}
+
+	postIDs := make([]int64, len(posts))
+	for i, p := range posts {
+		postIDs[i] = p.ID
+	}
+
+	var polls []models.Poll
+	if err := getDB().Where("post_id IN ?", postIDs).Find(&polls).Error; err != nil || len(polls) == 0 {
+		return
+	}
+
+	pollIDs := make([]int64, len(polls))
+	for i, p := range polls {
+		pollIDs[i] = p.ID
+	}
+
+	var options []models.PollOption
+	getDB().Where("poll_id IN ?", pollIDs).Order("position ASC").Find(&options)
+	optionMap := make(map[int64][]models.PollOption)
+	for _, o := range options {
+		optionMap[o.PollID] = append(optionMap[o.PollID], o)
+	}
+
+	myVotes := make(map[int64][]int64)
+	if userID != "" {
+		var votes []models.PollVote
+		getDB().Select("poll_id", "option_id").
+			Where("poll_id IN ? AND user_id = ?", pollIDs, userID).
+			Find(&votes)
+		for _, v := range votes {
+			myVotes[v.PollID] = append(myVotes[v.PollID], v.OptionID)
+		}
+	}
+
+	pollMap := make(map[int64]*models.Poll, len(polls))
+	for i := range polls {
+		pollMap[polls[i].PostID] = &polls[i]
+	}
+
+	for i := range posts {
+		source, ok := pollMap[posts[i].ID]
+		if !ok {
+			continue
+		}
+		poll := *source
+		poll.Options = append([]models.PollOption{}, optionMap[poll.ID]...)
+		poll.MyOptionIDs = append([]int64{}, myVotes[poll.ID]...)
+		poll.HasVoted = len(poll.MyOptionIDs) > 0
+		poll.Closed = pollClosed(&poll)
+
+		if poll.HideResultsUntilVoted && !poll.HasVoted && !poll.Closed && posts[i].UserID != userID {
+			poll.ResultsHidden = true
+			poll.VoterCount = 0
+			for j := range poll.Options {
+				poll.Options[j].VoteCount = 0
+			}
+		}
+		posts[i].Poll = &poll
+	}
+}
@@ -19,6 +19,18 @@ type PostResponse struct {
 	CommentCount int      `json:"commentCount"`
 	ViewCount   int       `json:"viewCount"`
 	CreatedAt   time.Time `json:"createdAt"`
+	EditedAt    *time.Time `json:"editedAt"`   // 最后一次编辑内容的时间，未编辑为 null
+	ShareCount  int       `json:"shareCount"`
+	Anonymous   bool      `json:"anonymous"`                // 树洞帖子，作者信息为化名
+	RepostOf    *models.RepostOrigin `json:"repostOf"` // 转发的原帖，非转发为 null
+	Poll        *models.Poll `json:"poll"`             // 附带的投票，没有投票为 null
+	IsBookmarked bool     `json:"isBookmarked"`     // 当前用户是否已收藏
+	CommentPolicy int     `json:"commentPolicy"`    // 评论权限：0-所有人 1-仅好友 2-关闭评论
+	Reactions   []models.ReactionCount `json:"reactions"` // 各表情回应的数量
+	MyReaction  string    `json:"myReaction"`       // 当前用户的表情回应，没有回应为空
+	IsLiked     bool      `json:"isLiked"`          // 当前用户是否点赞（任意表情回应）
+	LikedByFriends []*models.PostAuthor `json:"likedByFriends"` // 最近点赞的几位好友
+	FriendLikeCount int   `json:"friendLikeCount"`  // 点赞的好友总数
 	
 	// 用户信息
 	Username string `json:"username"`
@@ -37,6 +49,18 @@ func ConvertToPostResponse(post models.Post) PostResponse {
 		CommentCount: post.CommentCount,
 		ViewCount:    post.ViewCount,
 		CreatedAt:    post.CreatedAt,
+		EditedAt:     post.EditedAt,
+		ShareCount:   post.ShareCount,
+		Anonymous:    post.Anonymous,
+		RepostOf:     post.RepostOf,
+		Poll:         post.Poll,
+		IsBookmarked: post.IsBookmarked,
+		CommentPolicy: post.CommentPolicy,
+		Reactions:    post.Reactions,
+		MyReaction:   post.MyReaction,
+		IsLiked:      post.IsLiked,
+		LikedByFriends: post.LikedByFriends,
+		FriendLikeCount: post.FriendLikeCount,
 	}
 	
 	// 处理图片和封面
@@ -61,7 +85,7 @@ func ConvertToPostResponse(post models.Post) PostResponse {
 
 // GetEnhancedPostList 获取增强版帖子列表
 func GetEnhancedPostList(userID string, postType string, page, pageSize int) ([]PostResponse, int64, error) {
-	posts, total, err := GetPostList(userID, postType, page, pageSize)
+	posts, pageInfo, err := GetPostList(userID, postType, PageOptions{Page: page, PageSize: pageSize, WithTotal: true})
 	if err != nil {
 		return nil, 0, err
 	}
@@ -71,7 +95,7 @@ func GetEnhancedPostList(userID string, postType string, page, pageSize int) ([]
 		responses[i] = ConvertToPostResponse(post)
 	}
 	
-	return responses, total, nil
+	return responses, pageInfo.Total, nil
 }
 
 // GetEnhancedPostDetail 获取增强版帖子详情
@@ -5,22 +5,45 @@ import (
 	"time"
 
 	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/realtime"
 	"gorm.io/gorm"
 )
 
-// CreatePost 创建帖子
-func CreatePost(userID, title, content string, images []string, video string, visibility int, tags []string) (*models.Post, error) {
+// CreatePost 创建帖子，poll 不为空时同时创建投票
+// publishAt 不为空时保存为定时帖子，draft 为 true 时保存为草稿，否则立即发布
+func CreatePost(userID, title, content string, images []string, video string, visibility int, tags []string, draft bool, publishAt *time.Time, poll *PollInput) (*models.Post, error) {
 	post := &models.Post{
 		UserID:     userID,
 		Title:       title,
 		Content:     content,
 		Visibility:  visibility,
-		Status:      0, // 正常状态
+		Status:      models.PostStatusNormal,
 		ViewCount:   0,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 	
+	if publishAt != nil {
+		if !publishAt.After(time.Now()) {
+			return nil, ErrPublishAtInPast
+		}
+		post.Status = models.PostStatusScheduled
+		post.PublishAt = publishAt
+	} else if draft {
+		post.Status = models.PostStatusDraft
+	}
+	
+	// 投票截止时间不能早于帖子发布时间
+	if poll != nil {
+		publishTime := post.CreatedAt
+		if publishAt != nil {
+			publishTime = *publishAt
+		}
+		if err := validatePollInput(poll, publishTime); err != nil {
+			return nil, err
+		}
+	}
+	
 	// 处理图片
 	if len(images) > 0 {
 		imagesJSON, _ := json.Marshal(images)
@@ -39,7 +62,18 @@ func CreatePost(userID, title, content string, images []string, video string, vi
 	}
 	
 	// 保存到数据库
-	if err := getDB().Create(post).Error; err != nil {
+	err := getDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		if poll == nil {
+			return nil
+		}
+		created, err := createPoll(tx, post.ID, poll)
+		post.Poll = created
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 	
@@ -49,31 +83,57 @@ func CreatePost(userID, title, content string, images []string, video string, vi
 		post.User = &user
 	}
 	
+	// 草稿和定时帖子在发布时才计入发帖数、标签统计并通知
+	if post.Status == models.PostStatusNormal {
+		onPostPublished(post)
+	}
+	
+	return post, nil
+}
+
+// onPostPublished 帖子发布后的处理：更新发帖数和标签统计、通知被@的用户、写入时间线
+func onPostPublished(post *models.Post) {
 	// 更新用户发帖数
-	getDB().Model(&models.User{}).Where("id = ?", userID).Update("post_count", gorm.Expr("post_count + ?", 1))
+	getDB().Model(&models.User{}).Where("id = ?", post.UserID).Update("post_count", gorm.Expr("post_count + ?", 1))
 	
 	// 更新标签使用统计
+	var tags []string
+	if len(post.Tags) > 0 {
+		json.Unmarshal(post.Tags, &tags)
+	}
 	for _, tagName := range tags {
 		updateTagUsage(tagName)
 	}
 	
-	return post, nil
+	// 解析@提及并通知
+	post.Mentions = processMentions(models.MentionSourcePost, post.ID, post.ID, post.UserID, post.Visibility, post.UserID, post.Content)
+	
+	// 写入相关用户的首页时间线
+	fanOutPost(post.ID)
 }
 
 // GetHomePagePosts 获取主页帖子（公开和好友帖子）
-func GetHomePagePosts(userID string, page, pageSize int) ([]models.Post, int64, error) {
-	return GetPostList(userID, "all", page, pageSize)
+// 登录用户按时间排序的首屏和游标翻页走时间线，热度排序和页码翻页仍直接查询
+func GetHomePagePosts(userID string, opts PageOptions) ([]models.Post, PageInfo, error) {
+	opts = normalizePage(opts)
+	if userID == "" || opts.Sort == SortHot || (opts.Cursor == "" && opts.Page > 1) {
+		return GetPostList(userID, "all", opts)
+	}
+	return getHomeTimeline(userID, opts)
 }
 
-// GetPostList 获取帖子列表
-func GetPostList(userID, visibility string, page, pageSize int) ([]models.Post, int64, error) {
-	var posts []models.Post
-	var total int64
-	
-	offset := (page - 1) * pageSize
+// GetPostList 获取帖子列表，默认按 (created_at, id) 倒序，opts.Sort 为 SortHot 时按热度分倒序
+func GetPostList(userID, visibility string, opts PageOptions) ([]models.Post, PageInfo, error) {
+	opts = normalizePage(opts)
 	
 	query := getDB().Model(&models.Post{}).Where("status = ?", 0)
+	query = applyPostVisibility(query, userID, visibility)
 	
+	return findPosts(query, opts, userID)
+}
+
+// applyPostVisibility 根据可见性和拉黑关系过滤帖子
+func applyPostVisibility(query *gorm.DB, userID, visibility string) *gorm.DB {
 	// 根据可见性过滤
 	if visibility == "0" {
 		// 公开帖子
@@ -94,25 +154,74 @@ func GetPostList(userID, visibility string, page, pageSize int) ([]models.Post,
 		query = query.Where("visibility = ?", 0)
 	}
 	
-	// 获取总数
-	query.Count(&total)
+	// 过滤存在拉黑关系的用户的帖子
+	if userID != "" {
+		if blockedIDs := GetBlockedUserIDs(userID); len(blockedIDs) > 0 {
+			query = query.Where("user_id NOT IN ?", blockedIDs)
+		}
+	}
 	
-	// 获取帖子列表
-	err := query.Preload("User").
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(pageSize).
-		Find(&posts).Error
+	return query
+}
+
+// findPosts 分页查询帖子并填充作者、@提及和转发的原帖，userID 为当前登录用户
+func findPosts(query *gorm.DB, opts PageOptions, userID string) ([]models.Post, PageInfo, error) {
+	var posts []models.Post
 	
-	return posts, total, err
+	query, info, err := paginate(query, opts, true)
+	if err != nil {
+		return nil, info, err
+	}
+	
+	// 获取帖子列表（User字段标记为gorm:"-"，需要手动加载）
+	if err := query.Find(&posts).Error; err != nil {
+		return nil, info, err
+	}
+	
+	posts = finishPage(posts, opts, &info, func(p models.Post) string {
+		if opts.Sort == SortHot {
+			return encodeScoreCursor(p.HotScore, p.ID)
+		}
+		return encodeCursor(p.CreatedAt, p.ID)
+	})
+	attachPostUsers(posts)
+	attachPostMentions(posts)
+	attachReposts(posts, userID)
+	attachPolls(posts, userID)
+	attachBookmarks(posts, userID)
+	attachPostReactions(posts, userID)
+	attachPostFriendLikes(posts, userID)
+	
+	return posts, info, nil
+}
+
+// attachPostUsers 为帖子列表批量填充作者信息，树洞帖子填充化名
+func attachPostUsers(posts []models.Post) {
+	userIDSet := make(map[string]bool, len(posts))
+	var anonymousPostIDs []int64
+	for _, p := range posts {
+		if p.Anonymous {
+			anonymousPostIDs = append(anonymousPostIDs, p.ID)
+		} else {
+			userIDSet[p.UserID] = true
+		}
+	}
+	users := loadUsers(userIDSet)
+	pseudonyms := anonymousUsers(anonymousPostIDs)
+	for i := range posts {
+		if posts[i].Anonymous {
+			posts[i].User = pseudonyms[posts[i].ID]
+		} else {
+			posts[i].User = users[posts[i].UserID]
+		}
+	}
 }
 
 // GetPostDetail 获取帖子详情
 func GetPostDetail(postID int64, userID string) (*models.Post, error) {
 	var post models.Post
 	
-	err := getDB().Preload("User").
-		First(&post, "id = ? AND status = ?", postID, 0).Error
+	err := getDB().First(&post, "id = ? AND status = ?", postID, 0).Error
 	
 	if err != nil {
 		return nil, err
@@ -130,19 +239,41 @@ func GetPostDetail(postID int64, userID string) (*models.Post, error) {
 		}
 	}
 	
-	return &post, nil
+	// 存在拉黑关系时对方的帖子不可见
+	if IsBlocked(userID, post.UserID) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	
+	post.Mentions = loadMentions(models.MentionSourcePost, []int64{post.ID})[post.ID]
+	
+	posts := []models.Post{post}
+	attachPostUsers(posts)
+	attachReposts(posts, userID)
+	attachPolls(posts, userID)
+	attachBookmarks(posts, userID)
+	attachPostReactions(posts, userID)
+	attachPostFriendLikes(posts, userID)
+	
+	return &posts[0], nil
 }
 
-// UpdatePost 更新帖子
+// UpdatePost 更新帖子（包括草稿和定时帖子）
 func UpdatePost(postID int64, userID, title, content string, images []string, video string, visibility int, tags []string) (*models.Post, error) {
 	var post models.Post
 	
 	// 检查帖子是否存在且属于当前用户
-	if err := getDB().First(&post, "id = ? AND user_id = ? AND status = ?", postID, userID, 0).Error; err != nil {
+	if err := getDB().First(&post, "id = ? AND user_id = ? AND status IN ?", postID, userID,
+		[]int{models.PostStatusNormal, models.PostStatusDraft, models.PostStatusScheduled}).Error; err != nil {
+		return nil, err
+	}
+	
+	if err := checkRepostVisibility(post.RepostOfID, visibility); err != nil {
 		return nil, err
 	}
 	
 	// 更新字段
+	before := post
+	oldVisibility := post.Visibility
 	post.Title = title
 	post.Content = content
 	post.Visibility = visibility
@@ -163,28 +294,62 @@ func UpdatePost(postID int64, userID, title, content string, images []string, vi
 		post.Tags = tagsJSON
 	}
 	
-	// 保存更新
-	if err := getDB().Save(&post).Error; err != nil {
+	// 保存更新，已发布的帖子同时保存编辑前的版本
+	err := getDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&post).Error; err != nil {
+			return err
+		}
+		if post.Status != models.PostStatusNormal {
+			return nil
+		}
+		edited, err := recordPostRevision(tx, &before, post.UpdatedAt)
+		if edited {
+			post.EditedAt = &post.UpdatedAt
+		}
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 	
+	// 未发布的帖子只保存内容，发布时再处理统计、@提及和时间线
+	if post.Status != models.PostStatusNormal {
+		post.User = loadUsers(map[string]bool{userID: true})[userID]
+		return &post, nil
+	}
+	
 	// 更新标签使用统计
 	for _, tagName := range tags {
 		updateTagUsage(tagName)
 	}
 	
+	// 可见性变化时重新写入时间线
+	if oldVisibility != visibility {
+		removeFromTimelines(postID, userID)
+		fanOutPost(postID)
+	}
+	
 	// 重新加载用户信息
 	getDB().Preload("User").First(&post, postID)
 	
+	// 重新解析@提及，只通知新增的被提及用户
+	post.Mentions = processMentions(models.MentionSourcePost, post.ID, post.ID, userID, post.Visibility, userID, content)
+	
 	return &post, nil
 }
 
-// DeletePost 删除帖子
+// DeletePost 删除帖子（包括草稿和定时帖子）
 func DeletePost(postID int64, userID string) error {
-	// 软删除：更新状态为删除
+	var post models.Post
+	if err := whereOwnPost(getDB().Select("id", "user_id", "visibility", "status", "repost_of_id"), userID).
+		First(&post, "id = ? AND status <> ?", postID, models.PostStatusDeleted).Error; err != nil {
+		return err
+	}
+	
+	// 软删除：更新状态为删除，按原状态做条件更新，避免与定时发布并发时漏减发帖数
 	result := getDB().Model(&models.Post{}).
-		Where("id = ? AND user_id = ?", postID, userID).
-		Update("status", 1)
+		Where("id = ? AND status = ?", postID, post.Status).
+		Update("status", models.PostStatusDeleted)
 	
 	if result.Error != nil {
 		return result.Error
@@ -194,20 +359,54 @@ func DeletePost(postID int64, userID string) error {
 		return gorm.ErrRecordNotFound
 	}
 	
-	// 更新用户发帖数
-	getDB().Model(&models.User{}).Where("id = ?", userID).Update("post_count", gorm.Expr("post_count - ?", 1))
+	// 草稿和定时帖子未计入发帖数，也没有被其他人看到过
+	if post.Status != models.PostStatusNormal {
+		return nil
+	}
+	
+	// 更新用户发帖数（树洞帖子的 user_id 为占位ID，不会暴露真实作者）
+	getDB().Model(&models.User{}).Where("id = ?", post.UserID).Update("post_count", gorm.Expr("post_count - ?", 1))
+	onRepostRemoved(post.RepostOfID)
+	
+	// 通知能看到该帖子的在线用户移除它
+	pushPostDeleted(postID, post.UserID, post.Visibility)
+	removeFromTimelines(postID, post.UserID)
 	
 	return nil
 }
 
+// pushPostDeleted 推送帖子删除事件，推送范围与帖子可见性一致
+func pushPostDeleted(postID int64, authorID string, visibility int) {
+	hub := realtime.GetHub()
+	data := map[string]interface{}{
+		"postId": postID,
+		"userId": authorID,
+	}
+
+	switch visibility {
+	case 0: // 公开
+		hub.Broadcast(realtime.EventPostDeleted, data)
+	case 1: // 好友可见
+		hub.PushToUsers(append(GetFriendIDs(authorID), authorID), realtime.EventPostDeleted, data)
+	default: // 仅自己
+		hub.PushToUser(authorID, realtime.EventPostDeleted, data)
+	}
+}
+
 // GetUserPosts 获取用户帖子列表
 func GetUserPosts(currentUserID, targetUserID string, page, pageSize int) ([]models.Post, int64, error) {
 	var posts []models.Post
 	var total int64
 	
+	// 存在拉黑关系时看不到对方的帖子
+	if IsBlocked(currentUserID, targetUserID) {
+		return posts, 0, nil
+	}
+	
 	offset := (page - 1) * pageSize
 	
-	query := getDB().Model(&models.Post{}).Where("user_id = ? AND status = ?", targetUserID, 0)
+	// 树洞帖子不出现在任何人的帖子列表中
+	query := getDB().Model(&models.Post{}).Where("user_id = ? AND status = ? AND anonymous = ?", targetUserID, 0, false)
 	
 	// 如果不是查看自己的帖子，需要过滤可见性
 	if currentUserID != targetUserID {
@@ -219,12 +418,21 @@ func GetUserPosts(currentUserID, targetUserID string, page, pageSize int) ([]mod
 	query.Count(&total)
 	
 	// 获取帖子列表
-	err := query.Preload("User").
-		Order("created_at DESC").
+	err := query.Order("created_at DESC").
 		Offset(offset).
 		Limit(pageSize).
 		Find(&posts).Error
 	
+	if err == nil {
+		attachPostUsers(posts)
+		attachPostMentions(posts)
+		attachReposts(posts, currentUserID)
+		attachPolls(posts, currentUserID)
+		attachBookmarks(posts, currentUserID)
+		attachPostReactions(posts, currentUserID)
+		attachPostFriendLikes(posts, currentUserID)
+	}
+	
 	return posts, total, err
 }
 
@@ -0,0 +1,344 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/realtime"
+	"github.com/Yw332/campus-moments-go/pkg/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidReaction = errors.New("不支持的表情回应")
+
+// ReactionSummary 某个目标的表情回应汇总
+type ReactionSummary struct {
+	Reactions  []models.ReactionCount `json:"reactions"`
+	MyReaction string                 `json:"myReaction"`
+	LikeCount  int                    `json:"likeCount"` // 全部表情回应的总数，与旧版点赞数一致
+}
+
+// reactionTarget 表情回应的目标
+type reactionTarget struct {
+	targetType int
+	targetID   int64
+	ownerID    string // 目标的作者，接收通知和获赞数
+	postID     int64
+	preview    string // 通知中显示的内容
+	message    *models.Message
+}
+
+// toggleLike 旧版点赞接口：已有任意表情回应时取消，否则回应👍，返回操作后是否为点赞状态
+func toggleLike(userID string, targetType int, targetID int64) (bool, error) {
+	var count int64
+	if err := getDB().Model(&models.Like{}).
+		Where("user_id = ? AND target_type = ? AND target_id = ?", userID, targetType, targetID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		_, err := RemoveReaction(userID, targetType, targetID)
+		return false, err
+	}
+	if _, err := SetReaction(userID, targetType, targetID, models.ReactionLike); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ReactionEmojis 获取可用的表情回应，👍 始终可用，保证旧版点赞可以映射
+func ReactionEmojis() []string {
+	var emojis []string
+	if config.Cfg != nil {
+		emojis = config.Cfg.Reaction.Emojis
+	}
+	if len(emojis) == 0 {
+		emojis = []string{models.ReactionLike, "❤️", "😂", "😮", "😢", "🎉"}
+	}
+	for _, e := range emojis {
+		if e == models.ReactionLike {
+			return emojis
+		}
+	}
+	return append([]string{models.ReactionLike}, emojis...)
+}
+
+// isValidReaction 判断表情是否在可用的表情回应中
+func isValidReaction(emoji string) bool {
+	for _, e := range ReactionEmojis() {
+		if e == emoji {
+			return true
+		}
+	}
+	return false
+}
+
+// findReactionTarget 获取当前用户可以回应的目标
+func findReactionTarget(userID string, targetType int, targetID int64) (*reactionTarget, error) {
+	switch targetType {
+	case models.ReactionTargetPost:
+		post, err := findVisiblePost(userID, targetID)
+		if err != nil {
+			return nil, err
+		}
+		return &reactionTarget{targetType: targetType, targetID: targetID, ownerID: post.UserID, postID: post.ID, preview: post.Title}, nil
+
+	case models.ReactionTargetComment:
+		var comment models.Comment
+		if err := getDB().First(&comment, "id = ? AND status = ?", targetID, models.CommentStatusNormal).Error; err != nil {
+			return nil, err
+		}
+		if IsBlocked(userID, comment.UserID) {
+			return nil, ErrUserBlocked
+		}
+		return &reactionTarget{targetType: targetType, targetID: targetID, ownerID: comment.UserID, postID: comment.PostID, preview: comment.Content}, nil
+
+	case models.ReactionTargetMessage:
+		var message models.Message
+		if err := getDB().First(&message, "id = ?", targetID).Error; err != nil {
+			return nil, err
+		}
+		if !canViewMessage(userID, &message) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		if message.Status == models.MessageRecalled {
+			return nil, ErrMessageRecalled
+		}
+		return &reactionTarget{targetType: targetType, targetID: targetID, ownerID: message.SenderID, message: &message}, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// SetReaction 对帖子、评论或消息做出表情回应，已有回应时替换为新的表情
+func SetReaction(userID string, targetType int, targetID int64, emoji string) (*ReactionSummary, error) {
+	if !isValidReaction(emoji) {
+		return nil, ErrInvalidReaction
+	}
+	target, err := findReactionTarget(userID, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing models.Like
+	err = getDB().Where("user_id = ? AND target_type = ? AND target_id = ?", userID, targetType, targetID).First(&existing).Error
+	switch {
+	case err == nil:
+		if existing.Reaction != emoji {
+			if err := getDB().Model(&existing).Update("reaction", emoji).Error; err != nil {
+				return nil, err
+			}
+			onReactionChanged(target, userID, emoji)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		// 唯一索引保证并发回应只插入一条，没有插入说明其他请求先回应了，改为替换表情
+		result := getDB().Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Like{
+			UserID:     userID,
+			TargetType: targetType,
+			TargetID:   targetID,
+			Reaction:   emoji,
+			CreatedAt:  time.Now(),
+		})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			adjustReactionCounts(target, userID, 1)
+		} else if err := getDB().Model(&models.Like{}).
+			Where("user_id = ? AND target_type = ? AND target_id = ?", userID, targetType, targetID).
+			Update("reaction", emoji).Error; err != nil {
+			return nil, err
+		}
+		onReactionChanged(target, userID, emoji)
+	default:
+		return nil, err
+	}
+
+	return reactionSummary(targetType, targetID, userID)
+}
+
+// RemoveReaction 取消表情回应，没有回应时直接返回
+func RemoveReaction(userID string, targetType int, targetID int64) (*ReactionSummary, error) {
+	result := getDB().Where("user_id = ? AND target_type = ? AND target_id = ?", userID, targetType, targetID).Delete(&models.Like{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		// 目标已删除时只删除回应记录，不再更新计数
+		if target, err := findReactionTargetForRemoval(targetType, targetID); err == nil {
+			adjustReactionCounts(target, userID, -1)
+			onReactionChanged(target, userID, "")
+		}
+	}
+
+	return reactionSummary(targetType, targetID, userID)
+}
+
+// findReactionTargetForRemoval 取消回应时获取目标，不检查可见性，拉黑后也可以取消之前的回应
+func findReactionTargetForRemoval(targetType int, targetID int64) (*reactionTarget, error) {
+	target := &reactionTarget{targetType: targetType, targetID: targetID}
+	switch targetType {
+	case models.ReactionTargetPost:
+		var post models.Moment
+		if err := getDB().Select("id", "user_id").First(&post, "id = ?", targetID).Error; err != nil {
+			return nil, err
+		}
+		target.ownerID, target.postID = post.UserID, int64(post.ID)
+	case models.ReactionTargetComment:
+		var comment models.Comment
+		if err := getDB().Select("id", "user_id", "post_id").First(&comment, "id = ?", targetID).Error; err != nil {
+			return nil, err
+		}
+		target.ownerID, target.postID = comment.UserID, comment.PostID
+	case models.ReactionTargetMessage:
+		var message models.Message
+		if err := getDB().First(&message, "id = ?", targetID).Error; err != nil {
+			return nil, err
+		}
+		target.ownerID, target.message = message.SenderID, &message
+	}
+	return target, nil
+}
+
+// adjustReactionCounts 新增或取消回应时更新帖子、评论的点赞数和作者的获赞数，新增时通知作者
+func adjustReactionCounts(target *reactionTarget, userID string, delta int) {
+	expr := gorm.Expr("like_count + ?", delta)
+	if delta < 0 {
+		expr = gorm.Expr("GREATEST(like_count - ?, 0)", -delta)
+	}
+
+	switch target.targetType {
+	case models.ReactionTargetPost:
+		getDB().Model(&models.Moment{}).Where("id = ?", target.targetID).Update("like_count", expr)
+		getDB().Model(&models.User{}).Where("id = ?", target.ownerID).Update("like_count", expr)
+		if delta > 0 {
+			Notify(target.ownerID, userID, models.NotificationPostLiked, target.targetID, target.postID, target.preview)
+		}
+	case models.ReactionTargetComment:
+		getDB().Model(&models.Comment{}).Where("id = ?", target.targetID).Update("like_count", expr)
+		if delta > 0 {
+			Notify(target.ownerID, userID, models.NotificationCommentLiked, target.targetID, target.postID, target.preview)
+		}
+	}
+}
+
+// onReactionChanged 消息的表情回应变化后同步给会话的全部参与者，reaction 为空表示取消
+func onReactionChanged(target *reactionTarget, userID, reaction string) {
+	if target.targetType != models.ReactionTargetMessage || target.message == nil {
+		return
+	}
+
+	counts, _ := loadReactions(models.ReactionTargetMessage, []int64{target.targetID}, "")
+	event := map[string]interface{}{
+		"messageId":  target.message.ID,
+		"senderId":   target.message.SenderID,
+		"receiverId": target.message.ReceiverID,
+		"groupId":    target.message.GroupID,
+		"userId":     userID,
+		"reaction":   reaction,
+		"reactions":  counts[target.targetID],
+	}
+	if err := getDB().Transaction(func(tx *gorm.DB) error {
+		return recordMessageSyncEvent(tx, target.message, models.SyncEventReaction, event)
+	}); err != nil {
+		return
+	}
+	realtime.GetHub().PushToUsers(messageParticipants(target.message), realtime.EventMessageReaction, event)
+}
+
+// reactionSummary 获取单个目标的表情回应汇总
+func reactionSummary(targetType int, targetID int64, userID string) (*ReactionSummary, error) {
+	counts, mine := loadReactions(targetType, []int64{targetID}, userID)
+	summary := &ReactionSummary{
+		Reactions:  counts[targetID],
+		MyReaction: mine[targetID],
+	}
+	for _, r := range summary.Reactions {
+		summary.LikeCount += r.Count
+	}
+	return summary, nil
+}
+
+// loadReactions 批量统计目标的各表情回应数量和当前用户的回应
+// 数量按可用表情的顺序排列，只返回数量大于0且仍可用的表情
+func loadReactions(targetType int, targetIDs []int64, userID string) (map[int64][]models.ReactionCount, map[int64]string) {
+	counts := make(map[int64][]models.ReactionCount, len(targetIDs))
+	mine := loadViewerLikes(userID, targetType, targetIDs)
+	if len(targetIDs) == 0 {
+		return counts, mine
+	}
+
+	var rows []struct {
+		TargetID int64
+		Reaction string
+		Count    int
+	}
+	getDB().Model(&models.Like{}).
+		Select("target_id, reaction, COUNT(*) AS count").
+		Where("target_type = ? AND target_id IN ?", targetType, targetIDs).
+		Group("target_id, reaction").
+		Scan(&rows)
+
+	byTarget := make(map[int64]map[string]int, len(targetIDs))
+	for _, r := range rows {
+		if byTarget[r.TargetID] == nil {
+			byTarget[r.TargetID] = make(map[string]int)
+		}
+		byTarget[r.TargetID][r.Reaction] = r.Count
+	}
+	for _, id := range targetIDs {
+		counts[id] = []models.ReactionCount{}
+	}
+	emojis := ReactionEmojis()
+	for id, m := range byTarget {
+		for _, e := range emojis {
+			if m[e] > 0 {
+				counts[id] = append(counts[id], models.ReactionCount{Emoji: e, Count: m[e]})
+			}
+		}
+	}
+
+	return counts, mine
+}
+
+// attachPostReactions 为帖子批量填充表情回应
+func attachPostReactions(posts []models.Post, userID string) {
+	ids := make([]int64, len(posts))
+	for i, p := range posts {
+		ids[i] = p.ID
+	}
+	counts, mine := loadReactions(models.ReactionTargetPost, ids, userID)
+	for i := range posts {
+		posts[i].Reactions = counts[posts[i].ID]
+		posts[i].MyReaction = mine[posts[i].ID]
+		posts[i].IsLiked = posts[i].MyReaction != ""
+	}
+}
+
+// attachCommentReactions 为评论批量填充表情回应
+func attachCommentReactions(comments []models.Comment, userID string) {
+	ids := make([]int64, len(comments))
+	for i, c := range comments {
+		ids[i] = int64(c.ID)
+	}
+	counts, mine := loadReactions(models.ReactionTargetComment, ids, userID)
+	for i := range comments {
+		comments[i].Reactions = counts[int64(comments[i].ID)]
+		comments[i].MyReaction = mine[int64(comments[i].ID)]
+	}
+}
+
+// attachMessageReactions 为消息批量填充表情回应
+func attachMessageReactions(messages []models.Message, userID string) {
+	ids := make([]int64, len(messages))
+	for i, m := range messages {
+		ids[i] = m.ID
+	}
+	counts, mine := loadReactions(models.ReactionTargetMessage, ids, userID)
+	for i := range messages {
+		messages[i].Reactions = counts[messages[i].ID]
+		messages[i].MyReaction = mine[messages[i].ID]
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRepostNotAllowed = errors.New("仅自己可见的帖子不能转发")
+	ErrRepostVisibility = errors.New("转发的可见范围不能大于原帖")
+)
+
+// CreateRepost 转发帖子，content 为可选的转发评论
+// 转发的转发指向最初的原帖；转发的可见范围不能大于原帖，好友可见的帖子只能以好友可见或仅自己转发
+func CreateRepost(userID string, postID int64, content string, visibility int) (*models.Post, error) {
+	original, err := findVisiblePost(userID, postID)
+	if err != nil {
+		return nil, err
+	}
+	if original.RepostOfID != nil {
+		if original, err = findVisiblePost(userID, *original.RepostOfID); err != nil {
+			return nil, err
+		}
+	}
+
+	if original.Visibility == 2 {
+		return nil, ErrRepostNotAllowed
+	}
+	if visibility < original.Visibility {
+		return nil, ErrRepostVisibility
+	}
+
+	now := time.Now()
+	post := &models.Post{
+		UserID:     userID,
+		Content:    content,
+		Visibility: visibility,
+		Status:     models.PostStatusNormal,
+		RepostOfID: &original.ID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	err = getDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Post{}).Where("id = ?", original.ID).
+			UpdateColumn("share_count", gorm.Expr("share_count + ?", 1)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	onPostPublished(post)
+
+	// 通知原帖作者
+	summary := content
+	if summary == "" {
+		summary = original.Title
+	}
+	Notify(original.UserID, userID, models.NotificationPostReposted, original.ID, original.ID, summary)
+
+	post.User = loadUsers(map[string]bool{userID: true})[userID]
+	post.RepostOf = buildRepostOrigin(original, loadUsers(map[string]bool{original.UserID: true}))
+	return post, nil
+}
+
+// checkRepostVisibility 修改转发帖子的可见性时，可见范围同样不能大于原帖
+func checkRepostVisibility(repostOfID *int64, visibility int) error {
+	if repostOfID == nil {
+		return nil
+	}
+	var original models.Post
+	if err := getDB().Select("id", "visibility").First(&original, "id = ?", *repostOfID).Error; err != nil {
+		// 原帖已不存在时不再限制
+		return nil
+	}
+	if visibility < original.Visibility {
+		return ErrRepostVisibility
+	}
+	return nil
+}
+
+// findVisiblePost 查找当前用户可以看到的帖子，看不到时按不存在处理
+func findVisiblePost(userID string, postID int64) (*models.Post, error) {
+	var post models.Post
+	if err := getDB().First(&post, "id = ? AND status = ?", postID, models.PostStatusNormal).Error; err != nil {
+		return nil, err
+	}
+	if !CanViewPost(userID, post.UserID, post.Visibility, nil) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &post, nil
+}
+
+// attachReposts 为转发帖子填充内嵌的原帖，原帖已删除或当前用户无权查看时填充墓碑
+func attachReposts(posts []models.Post, userID string) {
+	idSet := make(map[int64]bool)
+	for _, p := range posts {
+		if p.RepostOfID != nil {
+			idSet[*p.RepostOfID] = true
+		}
+	}
+	if len(idSet) == 0 {
+		return
+	}
+
+	ids := make([]int64, 0, len(idSet))
+	for id := range idSet {
+		ids = append(ids, id)
+	}
+	var originals []models.Post
+	getDB().Where("id IN ?", ids).Find(&originals)
+
+	rel := LoadViewerRelations(userID)
+
+	originalMap := make(map[int64]*models.Post, len(originals))
+	userIDSet := make(map[string]bool)
+	for i := range originals {
+		originalMap[originals[i].ID] = &originals[i]
+		userIDSet[originals[i].UserID] = true
+	}
+	users := loadUsers(userIDSet)
+
+	for i := range posts {
+		if posts[i].RepostOfID == nil {
+			continue
+		}
+		original, ok := originalMap[*posts[i].RepostOfID]
+		switch {
+		case !ok || original.Status != models.PostStatusNormal:
+			posts[i].RepostOf = &models.RepostOrigin{PostID: *posts[i].RepostOfID, Tombstone: models.RepostTombstoneDeleted}
+		case !CanViewPost(userID, original.UserID, original.Visibility, rel):
+			posts[i].RepostOf = &models.RepostOrigin{PostID: original.ID, Tombstone: models.RepostTombstoneUnavailable}
+		default:
+			posts[i].RepostOf = buildRepostOrigin(original, users)
+		}
+	}
+}
+
+// buildRepostOrigin 生成内嵌展示的原帖
+func buildRepostOrigin(original *models.Post, users map[string]*models.User) *models.RepostOrigin {
+	origin := &models.RepostOrigin{
+		PostID:    original.ID,
+		Title:     original.Title,
+		Content:   original.Content,
+		Images:    original.Images,
+		Video:     original.Video,
+		Tags:      original.Tags,
+		CreatedAt: &original.CreatedAt,
+		EditedAt:  original.EditedAt,
+	}
+	if original.Anonymous {
+		if u := anonymousUsers([]int64{original.ID})[original.ID]; u != nil {
+			origin.Author = &models.PostAuthor{UserID: u.ID, Username: u.Username, AvatarURL: u.AvatarURL}
+		}
+	} else if u, ok := users[original.UserID]; ok {
+		origin.Author = &models.PostAuthor{UserID: u.ID, Username: u.Username, AvatarURL: u.AvatarURL}
+	}
+	return origin
+}
+
+// onRepostRemoved 转发被删除后减少原帖的转发数
+func onRepostRemoved(repostOfID *int64) {
+	if repostOfID == nil {
+		return
+	}
+	getDB().Model(&models.Post{}).Where("id = ?", *repostOfID).
+		UpdateColumn("share_count", gorm.Expr("GREATEST(share_count - ?, 0)", 1))
+}
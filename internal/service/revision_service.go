@@ -0,0 +1,291 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"gorm.io/gorm"
+)
+
+// PostFieldDiff 帖子某个字段在一次编辑中的变化
+type PostFieldDiff struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+	Lines []DiffLine  `json:"lines,omitempty"` // 仅 content 字段提供逐行对比
+}
+
+// DiffLine 逐行对比中的一行
+type DiffLine struct {
+	Op   string `json:"op"` // equal / insert / delete
+	Text string `json:"text"`
+}
+
+// PostRevisionDiff 某个历史版本与其下一个版本的差异
+type PostRevisionDiff struct {
+	PostID      int64           `json:"postId"`
+	FromVersion int             `json:"fromVersion"`
+	ToVersion   int             `json:"toVersion"`
+	EditedAt    time.Time       `json:"editedAt"`
+	Changes     []PostFieldDiff `json:"changes"`
+}
+
+// recordPostRevision 在同一事务中保存编辑前的版本，before 为编辑前从数据库读出的帖子
+// 只修改可见性不算编辑内容，不记录版本，返回是否记录
+func recordPostRevision(tx *gorm.DB, before *models.Post, editedAt time.Time) (bool, error) {
+	var after models.Post
+	if err := tx.Select("id", "title", "content", "images", "video", "tags").
+		First(&after, "id = ?", before.ID).Error; err != nil {
+		return false, err
+	}
+	if !postContentChanged(before, &after) {
+		return false, nil
+	}
+
+	if err := tx.Create(&models.PostRevision{
+		PostID:     before.ID,
+		Title:      before.Title,
+		Content:    before.Content,
+		Images:     before.Images,
+		Video:      before.Video,
+		Tags:       before.Tags,
+		Visibility: before.Visibility,
+		EditedAt:   editedAt,
+	}).Error; err != nil {
+		return false, err
+	}
+
+	return true, tx.Model(&models.Post{}).Where("id = ?", before.ID).
+		UpdateColumn("edited_at", editedAt).Error
+}
+
+// postContentChanged 标题、正文、图片、视频或标签是否变化
+func postContentChanged(before, after *models.Post) bool {
+	return before.Title != after.Title ||
+		before.Content != after.Content ||
+		before.Video != after.Video ||
+		!bytes.Equal(before.Images, after.Images) ||
+		!bytes.Equal(before.Tags, after.Tags)
+}
+
+// GetPostRevisions 作者查看自己帖子的编辑历史
+func GetPostRevisions(postID int64, userID string) (*models.Post, []models.PostRevision, error) {
+	var post models.Post
+	if err := getDB().First(&post, "id = ? AND user_id = ? AND status = ?", postID, userID, models.PostStatusNormal).Error; err != nil {
+		return nil, nil, err
+	}
+	return listPostRevisions(&post)
+}
+
+// AdminGetPostRevisions 管理员查看帖子的编辑历史（包括已删除的帖子）
+func AdminGetPostRevisions(postID int64) (*models.Post, []models.PostRevision, error) {
+	var post models.Post
+	if err := getDB().First(&post, "id = ?", postID).Error; err != nil {
+		return nil, nil, err
+	}
+	return listPostRevisions(&post)
+}
+
+// listPostRevisions 按编辑顺序返回帖子的历史版本
+func listPostRevisions(post *models.Post) (*models.Post, []models.PostRevision, error) {
+	var revisions []models.PostRevision
+	if err := getDB().Where("post_id = ?", post.ID).Order("id ASC").Find(&revisions).Error; err != nil {
+		return nil, nil, err
+	}
+	for i := range revisions {
+		revisions[i].Version = i + 1
+	}
+
+	post.User = loadUsers(map[string]bool{post.UserID: true})[post.UserID]
+	return post, revisions, nil
+}
+
+// GetPostRevisionDiff 作者查看某次编辑的差异，version 为历史版本号，与其下一个版本对比
+func GetPostRevisionDiff(postID int64, userID string, version int) (*PostRevisionDiff, error) {
+	post, revisions, err := GetPostRevisions(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return diffPostRevision(post, revisions, version)
+}
+
+// AdminGetPostRevisionDiff 管理员查看某次编辑的差异
+func AdminGetPostRevisionDiff(postID int64, version int) (*PostRevisionDiff, error) {
+	post, revisions, err := AdminGetPostRevisions(postID)
+	if err != nil {
+		return nil, err
+	}
+	return diffPostRevision(post, revisions, version)
+}
+
+// diffPostRevision 对比第 version 个历史版本与下一个版本（最后一个历史版本与当前内容对比）
+func diffPostRevision(post *models.Post, revisions []models.PostRevision, version int) (*PostRevisionDiff, error) {
+	if version < 1 || version > len(revisions) {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	from := revisions[version-1]
+	to := models.PostRevision{
+		Title:   post.Title,
+		Content: post.Content,
+		Images:  post.Images,
+		Video:   post.Video,
+		Tags:    post.Tags,
+	}
+	if version < len(revisions) {
+		to = revisions[version]
+	}
+
+	diff := &PostRevisionDiff{
+		PostID:      post.ID,
+		FromVersion: version,
+		ToVersion:   version + 1,
+		EditedAt:    from.EditedAt,
+		Changes:     []PostFieldDiff{},
+	}
+	if from.Title != to.Title {
+		diff.Changes = append(diff.Changes, PostFieldDiff{Field: "title", Old: from.Title, New: to.Title})
+	}
+	if from.Content != to.Content {
+		diff.Changes = append(diff.Changes, PostFieldDiff{
+			Field: "content",
+			Old:   from.Content,
+			New:   to.Content,
+			Lines: diffLines(from.Content, to.Content),
+		})
+	}
+	if !bytes.Equal(from.Images, to.Images) {
+		diff.Changes = append(diff.Changes, PostFieldDiff{Field: "images", Old: decodeStringList(from.Images), New: decodeStringList(to.Images)})
+	}
+	if from.Video != to.Video {
+		diff.Changes = append(diff.Changes, PostFieldDiff{Field: "video", Old: from.Video, New: to.Video})
+	}
+	if !bytes.Equal(from.Tags, to.Tags) {
+		diff.Changes = append(diff.Changes, PostFieldDiff{Field: "tags", Old: decodeStringList(from.Tags), New: decodeStringList(to.Tags)})
+	}
+	return diff, nil
+}
+
+// decodeStringList 解析 JSON 字符串数组，空值返回空数组
+func decodeStringList(raw json.RawMessage) []string {
+	list := []string{}
+	if len(raw) > 0 {
+		json.Unmarshal(raw, &list)
+	}
+	return list
+}
+
+// 逐行对比时去掉公共前后缀后，两边行数的乘积超过该值就不再逐行对比，整段记为删除和插入，限制计算量
+const maxDiffCells = 4000000
+
+// diffLines 基于最长公共子序列的逐行对比，先去掉公共前后缀，再用 Hirschberg 算法在线性空间内求解
+func diffLines(oldText, newText string) []DiffLine {
+	a := strings.Split(oldText, "\n")
+	b := strings.Split(newText, "\n")
+	lines := make([]DiffLine, 0, len(a)+len(b))
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, line := range a[:prefix] {
+		lines = append(lines, DiffLine{Op: "equal", Text: line})
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(midA)*len(midB) > maxDiffCells {
+		lines = appendDiffOps(lines, "delete", midA)
+		lines = appendDiffOps(lines, "insert", midB)
+	} else {
+		lines = diffLinesLCS(lines, midA, midB)
+	}
+	for _, line := range a[len(a)-suffix:] {
+		lines = append(lines, DiffLine{Op: "equal", Text: line})
+	}
+	return lines
+}
+
+// diffLinesLCS Hirschberg 算法：把 a 从中间分开，找到 b 中使两半最长公共子序列之和最大的切分点后递归
+func diffLinesLCS(lines []DiffLine, a, b []string) []DiffLine {
+	switch {
+	case len(a) == 0:
+		return appendDiffOps(lines, "insert", b)
+	case len(b) == 0:
+		return appendDiffOps(lines, "delete", a)
+	case len(a) == 1:
+		for j, line := range b {
+			if line == a[0] {
+				lines = appendDiffOps(lines, "insert", b[:j])
+				lines = append(lines, DiffLine{Op: "equal", Text: line})
+				return appendDiffOps(lines, "insert", b[j+1:])
+			}
+		}
+		lines = append(lines, DiffLine{Op: "delete", Text: a[0]})
+		return appendDiffOps(lines, "insert", b)
+	}
+
+	mid := len(a) / 2
+	forward := lcsLengths(a[:mid], b, false)
+	backward := lcsLengths(a[mid:], b, true)
+	split, best := 0, -1
+	for j := 0; j <= len(b); j++ {
+		if n := forward[j] + backward[j]; n > best {
+			split, best = j, n
+		}
+	}
+
+	lines = diffLinesLCS(lines, a[:mid], b[:split])
+	return diffLinesLCS(lines, a[mid:], b[split:])
+}
+
+// lcsLengths 只保留两行的最长公共子序列长度表
+// 正向时 result[j] 为 a 与 b[:j] 的长度，reverse 时 result[j] 为 a 与 b[j:] 的长度
+func lcsLengths(a, b []string, reverse bool) []int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		ai := a[i]
+		if reverse {
+			ai = a[len(a)-1-i]
+		}
+		for j := 1; j <= len(b); j++ {
+			bj := b[j-1]
+			if reverse {
+				bj = b[len(b)-j]
+			}
+			if ai == bj {
+				cur[j] = prev[j-1] + 1
+			} else if prev[j] >= cur[j-1] {
+				cur[j] = prev[j]
+			} else {
+				cur[j] = cur[j-1]
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	if reverse {
+		// prev[k] 为 a 与 b 的最后 k 行的长度，换算为从 b[j:] 开始
+		result := make([]int, len(b)+1)
+		for j := range result {
+			result[j] = prev[len(b)-j]
+		}
+		return result
+	}
+	return prev
+}
+
+// appendDiffOps 把多行记为同一种操作
+func appendDiffOps(lines []DiffLine, op string, texts []string) []DiffLine {
+	for _, text := range texts {
+		lines = append(lines, DiffLine{Op: op, Text: text})
+	}
+	return lines
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// diffOps 把逐行对比结果写成 "op:text" 便于断言
+func diffOps(lines []DiffLine) []string {
+	ops := make([]string, len(lines))
+	for i, l := range lines {
+		ops[i] = l.Op + ":" + l.Text
+	}
+	return ops
+}
+
+// referenceLCS 用完整的二维表求最长公共子序列长度
+func referenceLCS(a, b []string) int {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				table[i][j] = table[i-1][j-1] + 1
+			} else {
+				table[i][j] = max(table[i-1][j], table[i][j-1])
+			}
+		}
+	}
+	return table[len(a)][len(b)]
+}
+
+func TestDiffLinesTrimsCommonPrefixAndSuffix(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []string
+	}{
+		{
+			name: "内容相同",
+			old:  "a\nb",
+			new:  "a\nb",
+			want: []string{"equal:a", "equal:b"},
+		},
+		{
+			name: "修改中间一行",
+			old:  "a\nb\nc\nd",
+			new:  "a\nx\nc\nd",
+			want: []string{"equal:a", "delete:b", "insert:x", "equal:c", "equal:d"},
+		},
+		{
+			name: "末尾追加",
+			old:  "a\nb",
+			new:  "a\nb\nc",
+			want: []string{"equal:a", "equal:b", "insert:c"},
+		},
+		{
+			name: "开头删除",
+			old:  "a\nb\nc",
+			new:  "b\nc",
+			want: []string{"delete:a", "equal:b", "equal:c"},
+		},
+		{
+			name: "前后缀相同时中间仍逐行对比",
+			old:  "head\nx\ny\nz\ntail",
+			new:  "head\ny\nw\nz\ntail",
+			want: []string{"equal:head", "delete:x", "equal:y", "insert:w", "equal:z", "equal:tail"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, diffOps(diffLines(tt.old, tt.new)))
+		})
+	}
+}
+
+func TestDiffLinesFallsBackAboveMaxCells(t *testing.T) {
+	// 去掉公共前后缀后两边各 n 行，n*n 超过 maxDiffCells
+	n := 2001
+	assert.Greater(t, n*n, maxDiffCells)
+
+	oldLines := []string{"head"}
+	newLines := []string{"head"}
+	for i := 0; i < n; i++ {
+		oldLines = append(oldLines, "old"+strconv.Itoa(i))
+		newLines = append(newLines, "new"+strconv.Itoa(i))
+	}
+	// 两边都有的一行，逐行对比时会记为相同，整段替换时不会
+	oldLines[1], newLines[n] = "shared", "shared"
+	oldLines = append(oldLines, "tail")
+	newLines = append(newLines, "tail")
+
+	lines := diffLines(strings.Join(oldLines, "\n"), strings.Join(newLines, "\n"))
+	if !assert.Len(t, lines, 2*n+2) {
+		return
+	}
+	assert.Equal(t, DiffLine{Op: "equal", Text: "head"}, lines[0])
+	for i, l := range lines[1 : n+1] {
+		assert.Equal(t, DiffLine{Op: "delete", Text: oldLines[i+1]}, l)
+	}
+	for i, l := range lines[n+1 : 2*n+1] {
+		assert.Equal(t, DiffLine{Op: "insert", Text: newLines[i+1]}, l)
+	}
+	assert.Equal(t, DiffLine{Op: "equal", Text: "tail"}, lines[2*n+1])
+}
+
+func TestDiffLinesMatchesReferenceLCS(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := randomLines(), randomLines()
+		lines := diffLines(strings.Join(a, "\n"), strings.Join(b, "\n"))
+
+		// 相同行和删除行还原旧文本，相同行和插入行还原新文本
+		var oldText, newText []string
+		equal := 0
+		for _, l := range lines {
+			switch l.Op {
+			case "equal":
+				oldText = append(oldText, l.Text)
+				newText = append(newText, l.Text)
+				equal++
+			case "delete":
+				oldText = append(oldText, l.Text)
+			case "insert":
+				newText = append(newText, l.Text)
+			}
+		}
+		if !assert.Equal(t, strings.Join(a, "\n"), strings.Join(oldText, "\n"), "old %q new %q", a, b) ||
+			!assert.Equal(t, strings.Join(b, "\n"), strings.Join(newText, "\n"), "old %q new %q", a, b) {
+			return
+		}
+
+		// 相同行数等于最长公共子序列长度，即对比结果最短；空文本按一个空行处理
+		want := referenceLCS(strings.Split(strings.Join(a, "\n"), "\n"), strings.Split(strings.Join(b, "\n"), "\n"))
+		if !assert.Equal(t, want, equal, "old %q new %q", a, b) {
+			return
+		}
+	}
+}
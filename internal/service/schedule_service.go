@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/pkg/config"
+	"gorm.io/gorm"
+)
+
+var ErrPublishAtInPast = errors.New("定时发布时间必须晚于当前时间")
+
+// 每次扫描最多发布的定时帖子数，剩余的留到下一轮
+const scheduleBatchSize = 100
+
+var postSchedulerOnce sync.Once
+
+// StartPostScheduler 启动后台定时发布任务，按配置间隔发布到期的定时帖子
+func StartPostScheduler() {
+	postSchedulerOnce.Do(func() {
+		go func() {
+			interval := config.Cfg.Feed.ScheduleInterval
+			if interval <= 0 {
+				interval = 30 * time.Second
+			}
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				if err := PublishDuePosts(); err != nil {
+					log.Printf("⚠️  发布定时帖子失败: %v", err)
+				}
+				<-ticker.C
+			}
+		}()
+	})
+}
+
+// PublishDuePosts 发布到期的定时帖子
+func PublishDuePosts() error {
+	var posts []models.Post
+	if err := getDB().
+		Where("status = ? AND publish_at <= ?", models.PostStatusScheduled, time.Now()).
+		Order("publish_at ASC").
+		Limit(scheduleBatchSize).
+		Find(&posts).Error; err != nil {
+		return err
+	}
+
+	for i := range posts {
+		if _, err := publishPost(&posts[i]); err != nil {
+			log.Printf("⚠️  发布定时帖子 %d 失败: %v", posts[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// publishPost 将草稿或定时帖子改为已发布，返回是否由本次调用发布
+// 按原状态做条件更新，多个实例同时扫描或用户同时手动发布时只有一方会成功
+// 发布时间记为 created_at，帖子按实际发布时间出现在信息流中
+func publishPost(post *models.Post) (bool, error) {
+	now := time.Now()
+	result := getDB().Model(&models.Post{}).
+		Where("id = ? AND status = ?", post.ID, post.Status).
+		Updates(map[string]interface{}{
+			"status":     models.PostStatusNormal,
+			"created_at": now,
+			"updated_at": now,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	post.Status = models.PostStatusNormal
+	post.CreatedAt = now
+	post.UpdatedAt = now
+	onPostPublished(post)
+	return true, nil
+}
+
+// findUnpublishedPost 查找当前用户的草稿或定时帖子
+func findUnpublishedPost(postID int64, userID string) (*models.Post, error) {
+	var post models.Post
+	if err := getDB().First(&post, "id = ? AND user_id = ? AND status IN ?", postID, userID,
+		[]int{models.PostStatusDraft, models.PostStatusScheduled}).Error; err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// PublishPost 立即发布草稿或定时帖子
+func PublishPost(postID int64, userID string) (*models.Post, error) {
+	post, err := findUnpublishedPost(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	published, err := publishPost(post)
+	if err != nil {
+		return nil, err
+	}
+	if !published {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	post.User = loadUsers(map[string]bool{userID: true})[userID]
+	return post, nil
+}
+
+// SchedulePost 设置草稿或定时帖子的发布时间，publishAt 为空时取消定时、转回草稿
+func SchedulePost(postID int64, userID string, publishAt *time.Time) (*models.Post, error) {
+	if publishAt != nil && !publishAt.After(time.Now()) {
+		return nil, ErrPublishAtInPast
+	}
+
+	post, err := findUnpublishedPost(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := models.PostStatusDraft
+	if publishAt != nil {
+		status = models.PostStatusScheduled
+	}
+
+	result := getDB().Model(&models.Post{}).
+		Where("id = ? AND status = ?", post.ID, post.Status).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"publish_at": publishAt,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	// 定时任务已抢先发布
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	post.Status = status
+	post.PublishAt = publishAt
+	post.User = loadUsers(map[string]bool{userID: true})[userID]
+	return post, nil
+}
+
+// GetDraftPosts 获取当前用户的草稿，按创建时间倒序
+func GetDraftPosts(userID string, opts PageOptions) ([]models.Post, PageInfo, error) {
+	opts = normalizePage(opts)
+	query := getDB().Model(&models.Post{}).Where("user_id = ? AND status = ?", userID, models.PostStatusDraft)
+	return findPosts(query, opts, userID)
+}
+
+// GetScheduledPosts 获取当前用户等待发布的定时帖子，按创建时间倒序
+func GetScheduledPosts(userID string, opts PageOptions) ([]models.Post, PageInfo, error) {
+	opts = normalizePage(opts)
+	query := getDB().Model(&models.Post{}).Where("user_id = ? AND status = ?", userID, models.PostStatusScheduled)
+	return findPosts(query, opts, userID)
+}
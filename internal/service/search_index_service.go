@@ -0,0 +1,218 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/search"
+	"github.com/Yw332/campus-moments-go/pkg/config"
+	"gorm.io/gorm"
+)
+
+const (
+	searchIndexBatchSize = 500
+	searchSyncOverlap    = 2 * time.Second // 增量同步的时间窗口向前多取一点，避免漏掉同步期间写入的行
+	userStatusDisabled   = 2               // 被禁用的用户不出现在搜索结果中
+)
+
+// 各字段在相关度中的权重
+const (
+	searchWeightPostTitle     = 3
+	searchWeightPostTags      = 2
+	searchWeightPostContent   = 1
+	searchWeightUsername      = 3
+	searchWeightUserSignature = 1
+	searchWeightTagName       = 3
+	searchWeightTagDesc       = 1
+)
+
+var (
+	searchIndexOnce sync.Once
+	indexedTagIDs   map[string]bool // 上次同步时已索引的标签，用于发现被删除的标签
+)
+
+// StartSearchIndexer 启动搜索索引任务：启动时全量建立索引，之后按配置间隔增量同步
+// 帖子和用户按 updated_at 增量同步，标签数量少且没有 updated_at，每次全量同步
+func StartSearchIndexer() {
+	searchIndexOnce.Do(func() {
+		go func() {
+			interval := config.Cfg.Search.SyncInterval
+			if interval <= 0 {
+				interval = 10 * time.Second
+			}
+
+			since := time.Now()
+			if err := rebuildSearchIndex(); err != nil {
+				log.Printf("⚠️  建立搜索索引失败: %v", err)
+			}
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for range ticker.C {
+				next := time.Now()
+				if err := syncSearchIndex(since.Add(-searchSyncOverlap)); err != nil {
+					log.Printf("⚠️  同步搜索索引失败: %v", err)
+					continue
+				}
+				since = next
+			}
+		}()
+	})
+}
+
+// rebuildSearchIndex 全量建立帖子、用户和标签的索引
+func rebuildSearchIndex() error {
+	engine := search.GetEngine()
+
+	var posts []models.Post
+	if err := getDB().Where("status = ?", models.PostStatusNormal).
+		FindInBatches(&posts, searchIndexBatchSize, func(tx *gorm.DB, batch int) error {
+			return engine.Index(postDocuments(posts)...)
+		}).Error; err != nil {
+		return err
+	}
+
+	var users []models.User
+	if err := getDB().Where("status IS NULL OR status <> ?", userStatusDisabled).
+		FindInBatches(&users, searchIndexBatchSize, func(tx *gorm.DB, batch int) error {
+			return engine.Index(userDocuments(users)...)
+		}).Error; err != nil {
+		return err
+	}
+
+	return syncSearchTags()
+}
+
+// syncSearchIndex 同步 since 之后修改过的帖子和用户，不再可见的从索引中删除
+func syncSearchIndex(since time.Time) error {
+	engine := search.GetEngine()
+
+	var posts []models.Post
+	if err := getDB().Where("updated_at >= ?", since).
+		FindInBatches(&posts, searchIndexBatchSize, func(tx *gorm.DB, batch int) error {
+			var removed []string
+			var visible []models.Post
+			for _, p := range posts {
+				if p.Status == models.PostStatusNormal {
+					visible = append(visible, p)
+				} else {
+					removed = append(removed, strconv.FormatInt(p.ID, 10))
+				}
+			}
+			if err := engine.Delete(search.KindPost, removed...); err != nil {
+				return err
+			}
+			return engine.Index(postDocuments(visible)...)
+		}).Error; err != nil {
+		return err
+	}
+
+	var users []models.User
+	if err := getDB().Where("updated_at >= ?", since).
+		FindInBatches(&users, searchIndexBatchSize, func(tx *gorm.DB, batch int) error {
+			var removed []string
+			var active []models.User
+			for _, u := range users {
+				if u.Status == userStatusDisabled {
+					removed = append(removed, u.ID)
+				} else {
+					active = append(active, u)
+				}
+			}
+			if err := engine.Delete(search.KindUser, removed...); err != nil {
+				return err
+			}
+			return engine.Index(userDocuments(active)...)
+		}).Error; err != nil {
+		return err
+	}
+
+	return syncSearchTags()
+}
+
+// syncSearchTags 全量同步正常状态的标签，删除已禁用或已删除的标签
+func syncSearchTags() error {
+	var tags []models.Tag
+	if err := getDB().Where("status = ?", 0).Find(&tags).Error; err != nil {
+		return err
+	}
+
+	engine := search.GetEngine()
+	current := make(map[string]bool, len(tags))
+	docs := make([]search.Document, len(tags))
+	for i, t := range tags {
+		id := strconv.Itoa(t.ID)
+		current[id] = true
+		docs[i] = search.Document{
+			Kind: search.KindTag,
+			ID:   id,
+			Fields: []search.Field{
+				{Name: "name", Text: t.Name, Weight: searchWeightTagName},
+				{Name: "description", Text: t.Description, Weight: searchWeightTagDesc},
+			},
+		}
+	}
+
+	var removed []string
+	for id := range indexedTagIDs {
+		if !current[id] {
+			removed = append(removed, id)
+		}
+	}
+	if err := engine.Delete(search.KindTag, removed...); err != nil {
+		return err
+	}
+	if err := engine.Index(docs...); err != nil {
+		return err
+	}
+	indexedTagIDs = current
+	return nil
+}
+
+// postDocuments 帖子的索引文档：标题、标签和正文，不索引作者，避免树洞帖子被按作者搜到
+func postDocuments(posts []models.Post) []search.Document {
+	docs := make([]search.Document, len(posts))
+	for i, p := range posts {
+		docs[i] = search.Document{
+			Kind: search.KindPost,
+			ID:   strconv.FormatInt(p.ID, 10),
+			Fields: []search.Field{
+				{Name: "title", Text: p.Title, Weight: searchWeightPostTitle},
+				{Name: "tags", Text: postTagsText(p.Tags), Weight: searchWeightPostTags},
+				{Name: "content", Text: p.Content, Weight: searchWeightPostContent},
+			},
+		}
+	}
+	return docs
+}
+
+// userDocuments 用户的索引文档：用户名和个性签名
+func userDocuments(users []models.User) []search.Document {
+	docs := make([]search.Document, len(users))
+	for i, u := range users {
+		docs[i] = search.Document{
+			Kind: search.KindUser,
+			ID:   u.ID,
+			Fields: []search.Field{
+				{Name: "username", Text: u.Username, Weight: searchWeightUsername},
+				{Name: "signature", Text: u.Signature, Weight: searchWeightUserSignature},
+			},
+		}
+	}
+	return docs
+}
+
+// postTagsText 把帖子的标签 JSON 数组拼接为空格分隔的文本
+func postTagsText(raw json.RawMessage) string {
+	var tags []string
+	if len(raw) == 0 || json.Unmarshal(raw, &tags) != nil {
+		return ""
+	}
+	return strings.Join(tags, " ")
+}
@@ -1,9 +1,13 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/search"
+	"github.com/Yw332/campus-moments-go/pkg/config"
 	"github.com/Yw332/campus-moments-go/pkg/database"
 	"gorm.io/gorm"
 )
@@ -35,6 +39,45 @@ type SearchResponse struct {
 	Pagination Pagination    `json:"pagination"`
 }
 
+// 搜索结果类型
+const (
+	SearchTypeAll   = "all"
+	SearchTypePosts = "posts"
+	SearchTypeUsers = "users"
+	SearchTypeTags  = "tags"
+)
+
+var ErrInvalidSearchType = errors.New("无效的搜索类型，可选 all、posts、users、tags")
+
+// SearchPage 一类搜索结果的分页信息，Total 为过滤掉不可见内容后的命中数
+type SearchPage struct {
+	Page     int   `json:"page"`
+	PageSize int   `json:"pageSize"`
+	Total    int64 `json:"total"`
+	HasMore  bool  `json:"hasMore"`
+}
+
+// UserSearchHit 用户搜索结果，Highlight 为命中字段的高亮片段
+type UserSearchHit struct {
+	PublicUserInfo
+	Highlight map[string]string `json:"highlight"`
+}
+
+// TagSearchHit 标签搜索结果，Highlight 为命中字段的高亮片段
+type TagSearchHit struct {
+	models.Tag
+	Highlight map[string]string `json:"highlight"`
+}
+
+// SearchResults 全文搜索结果，帖子、用户和标签各自分页，Pagination 只包含本次搜索的类型
+type SearchResults struct {
+	Posts          []models.Post
+	PostHighlights map[int64]map[string]string // 帖子ID -> 字段 -> 高亮片段
+	Users          []UserSearchHit
+	Tags           []TagSearchHit
+	Pagination     map[string]SearchPage
+}
+
 type FilterRequest struct {
 	Page       int      `json:"page"`
 	PageSize   int      `json:"pageSize"`
@@ -51,67 +94,285 @@ type Pagination struct {
 	Total    int64 `json:"total"`
 }
 
-// SearchContent 搜索内容
-func (s *SearchService) SearchContent(keyword string, page, pageSize int) (*SearchResponse, error) {
+// SearchContent 全文搜索帖子、用户和标签，按相关度排序，各类结果单独分页
+// searchType 为 all 时返回每类结果的同一页，为 posts/users/tags 时只返回该类结果
+// 帖子只返回当前用户可以看到的，用户不包括存在拉黑关系的用户
+func (s *SearchService) SearchContent(userID, keyword, searchType string, page, pageSize int) (*SearchResults, error) {
 	if page <= 0 {
 		page = 1
 	}
 	if pageSize <= 0 || pageSize > 100 {
 		pageSize = 10
 	}
+	if searchType == "" {
+		searchType = SearchTypeAll
+	}
+
+	results := &SearchResults{
+		Posts:          []models.Post{},
+		PostHighlights: map[int64]map[string]string{},
+		Users:          []UserSearchHit{},
+		Tags:           []TagSearchHit{},
+		Pagination:     map[string]SearchPage{},
+	}
+
+	searchAll := searchType == SearchTypeAll
+	if !searchAll && searchType != SearchTypePosts && searchType != SearchTypeUsers && searchType != SearchTypeTags {
+		return nil, ErrInvalidSearchType
+	}
+	if searchAll || searchType == SearchTypePosts {
+		if err := searchPosts(results, userID, keyword, page, pageSize); err != nil {
+			return nil, fmt.Errorf("搜索动态失败: %v", err)
+		}
+	}
+	if searchAll || searchType == SearchTypeUsers {
+		if err := searchUsers(results, userID, keyword, page, pageSize); err != nil {
+			return nil, fmt.Errorf("搜索用户失败: %v", err)
+		}
+	}
+	if searchAll || searchType == SearchTypeTags {
+		if err := searchTags(results, keyword, page, pageSize); err != nil {
+			return nil, fmt.Errorf("搜索标签失败: %v", err)
+		}
+	}
+
+	return results, nil
+}
+
+// searchHits 从检索引擎取回命中的文档ID，按相关度倒序
+func searchHits(kind, keyword string) ([]string, error) {
+	maxHits := config.Cfg.Search.MaxHits
+	if maxHits <= 0 {
+		maxHits = 1000
+	}
+	hits, err := search.GetEngine().Search(kind, keyword, maxHits)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+	}
+	return ids, nil
+}
 
-	offset := (page - 1) * pageSize
+// pageSearchHits 按页截取过滤后的命中，返回本页的ID和分页信息
+func pageSearchHits(ids []string, page, pageSize int) ([]string, SearchPage) {
+	info := SearchPage{Page: page, PageSize: pageSize, Total: int64(len(ids))}
+	start := (page - 1) * pageSize
+	if start >= len(ids) {
+		return nil, info
+	}
+	end := start + pageSize
+	if end < len(ids) {
+		info.HasMore = true
+	} else {
+		end = len(ids)
+	}
+	return ids[start:end], info
+}
+
+// snippetLength 高亮片段的最大字数
+func snippetLength() int {
+	if n := config.Cfg.Search.SnippetLength; n > 0 {
+		return n
+	}
+	return 80
+}
+
+// searchPosts 搜索帖子：索引只负责相关度，可见性和拉黑关系按数据库中的最新状态过滤
+func searchPosts(results *SearchResults, userID, keyword string, page, pageSize int) error {
+	hitIDs, err := searchHits(search.KindPost, keyword)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int64, 0, len(hitIDs))
+	for _, h := range hitIDs {
+		if id, err := strconv.ParseInt(h, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	visible := make(map[int64]bool, len(ids))
+	if len(ids) > 0 {
+		var visibleIDs []int64
+		query := getDB().Model(&models.Post{}).Where("id IN ? AND status = ?", ids, models.PostStatusNormal)
+		if err := applyPostVisibility(query, userID, "all").Pluck("id", &visibleIDs).Error; err != nil {
+			return err
+		}
+		for _, id := range visibleIDs {
+			visible[id] = true
+		}
+	}
+	var ordered []string
+	for _, id := range ids {
+		if visible[id] {
+			ordered = append(ordered, strconv.FormatInt(id, 10))
+		}
+	}
+
+	pageIDs, info := pageSearchHits(ordered, page, pageSize)
+	results.Pagination[SearchTypePosts] = info
+	if len(pageIDs) == 0 {
+		return nil
+	}
 
 	var posts []models.Post
-	var totalPosts int64
-	var users []models.User
-	var totalUsers int64
+	if err := getDB().Where("id IN ?", pageIDs).Find(&posts).Error; err != nil {
+		return err
+	}
+	byID := make(map[string]models.Post, len(posts))
+	for _, p := range posts {
+		byID[strconv.FormatInt(p.ID, 10)] = p
+	}
+	for _, id := range pageIDs {
+		if p, ok := byID[id]; ok {
+			results.Posts = append(results.Posts, p)
+		}
+	}
 
-	// 检查数据库连接
-	if s.db == nil {
-		return &SearchResponse{
-			Posts:      []models.Post{},
-			Users:      []models.User{},
-			Pagination: Pagination{Page: page, PageSize: pageSize, Total: 0},
-		}, nil
+	// 树洞帖子只显示化名
+	attachPostUsers(results.Posts)
+	attachPostMentions(results.Posts)
+	attachReposts(results.Posts, userID)
+	attachPolls(results.Posts, userID)
+	attachBookmarks(results.Posts, userID)
+	attachPostReactions(results.Posts, userID)
+	attachPostFriendLikes(results.Posts, userID)
+
+	for _, p := range results.Posts {
+		highlight := map[string]string{
+			"content": search.Highlight(p.Content, keyword, snippetLength()),
+		}
+		if search.Matches(p.Title, keyword) {
+			highlight["title"] = search.Highlight(p.Title, keyword, 0)
+		}
+		if tags := postTagsText(p.Tags); search.Matches(tags, keyword) {
+			highlight["tags"] = search.Highlight(tags, keyword, 0)
+		}
+		results.PostHighlights[p.ID] = highlight
 	}
+	return nil
+}
 
-	// 搜索动态（使用Post模型）
-	postQuery := s.getDB().Model(&models.Post{}).
-		Where("(title LIKE ? OR content LIKE ?) AND status = ?", 
-			"%"+keyword+"%", "%"+keyword+"%", 0).
-		Preload("User").
-		Order("created_at DESC")
+// searchUsers 搜索用户，跳过存在拉黑关系和已禁用的用户
+func searchUsers(results *SearchResults, userID, keyword string, page, pageSize int) error {
+	hitIDs, err := searchHits(search.KindUser, keyword)
+	if err != nil {
+		return err
+	}
 
-	// 统计动态总数
-	postQuery.Count(&totalPosts)
+	var ordered []string
+	if len(hitIDs) > 0 {
+		query := getDB().Model(&models.User{}).
+			Where("id IN ? AND (status IS NULL OR status <> ?)", hitIDs, userStatusDisabled)
+		if userID != "" {
+			if blockedIDs := GetBlockedUserIDs(userID); len(blockedIDs) > 0 {
+				query = query.Where("id NOT IN ?", blockedIDs)
+			}
+		}
+		var activeIDs []string
+		if err := query.Pluck("id", &activeIDs).Error; err != nil {
+			return err
+		}
+		active := make(map[string]bool, len(activeIDs))
+		for _, id := range activeIDs {
+			active[id] = true
+		}
+		for _, id := range hitIDs {
+			if active[id] {
+				ordered = append(ordered, id)
+			}
+		}
+	}
 
-	// 分页查询动态
-	if err := postQuery.Offset(offset).Limit(pageSize).Find(&posts).Error; err != nil {
-		return nil, fmt.Errorf("搜索动态失败: %v", err)
+	pageIDs, info := pageSearchHits(ordered, page, pageSize)
+	results.Pagination[SearchTypeUsers] = info
+	if len(pageIDs) == 0 {
+		return nil
 	}
 
-	// 搜索用户
-	userQuery := s.getDB().Model(&models.User{}).
-		Where("username LIKE ? OR signature LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	var users []models.User
+	if err := getDB().Where("id IN ?", pageIDs).Find(&users).Error; err != nil {
+		return err
+	}
+	byID := make(map[string]models.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	following := getFollowingSet(userID, pageIDs)
 
-	// 统计用户总数
-	userQuery.Count(&totalUsers)
+	for _, id := range pageIDs {
+		user, ok := byID[id]
+		if !ok {
+			continue
+		}
+		highlight := map[string]string{
+			"username": search.Highlight(user.Username, keyword, 0),
+		}
+		if search.Matches(user.Signature, keyword) {
+			highlight["signature"] = search.Highlight(user.Signature, keyword, snippetLength())
+		}
+		results.Users = append(results.Users, UserSearchHit{
+			PublicUserInfo: PublicUserInfo{
+				ID:              user.ID,
+				Username:        user.Username,
+				Avatar:          user.AvatarURL,
+				AvatarType:      user.AvatarType,
+				AvatarUpdatedAt: user.AvatarUpdatedAt,
+				PostCount:       user.PostCount,
+				LikeCount:       user.LikeCount,
+				CommentCount:    user.CommentCount,
+				FollowerCount:   user.FollowerCount,
+				FollowingCount:  user.FollowingCount,
+				IsFollowing:     following[user.ID],
+				Signature:       user.Signature,
+				LastActiveAt:    user.LastActiveAt,
+			},
+			Highlight: highlight,
+		})
+	}
+	return nil
+}
 
-	// 分页查询用户
-	if err := userQuery.Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
-		return nil, fmt.Errorf("搜索用户失败: %v", err)
+// searchTags 搜索标签，跳过已禁用的标签
+func searchTags(results *SearchResults, keyword string, page, pageSize int) error {
+	hitIDs, err := searchHits(search.KindTag, keyword)
+	if err != nil {
+		return err
 	}
 
-	return &SearchResponse{
-		Posts: posts,
-		Users: users,
-		Pagination: Pagination{
-			Page:     page,
-			PageSize: pageSize,
-			Total:    totalPosts + totalUsers, // 总数只是示例
-		},
-	}, nil
+	var tags []models.Tag
+	if len(hitIDs) > 0 {
+		if err := getDB().Where("id IN ? AND status = ?", hitIDs, 0).Find(&tags).Error; err != nil {
+			return err
+		}
+	}
+	byID := make(map[string]models.Tag, len(tags))
+	for _, t := range tags {
+		byID[strconv.Itoa(t.ID)] = t
+	}
+	var ordered []string
+	for _, id := range hitIDs {
+		if _, ok := byID[id]; ok {
+			ordered = append(ordered, id)
+		}
+	}
+
+	pageIDs, info := pageSearchHits(ordered, page, pageSize)
+	results.Pagination[SearchTypeTags] = info
+	for _, id := range pageIDs {
+		tag := byID[id]
+		highlight := map[string]string{
+			"name": search.Highlight(tag.Name, keyword, 0),
+		}
+		if search.Matches(tag.Description, keyword) {
+			highlight["description"] = search.Highlight(tag.Description, keyword, snippetLength())
+		}
+		results.Tags = append(results.Tags, TagSearchHit{Tag: tag, Highlight: highlight})
+	}
+	return nil
 }
 
 // GetHotWords 获取热词
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/pkg/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrStoryNotFound  = errors.New("快拍不存在或已过期")
+	ErrNotStoryOwner  = errors.New("只能查看自己快拍的浏览记录")
+	ErrStoryForbidden = errors.New("只能查看好友的快拍")
+)
+
+// uploadRoot 上传文件的根目录，与 /static 路由一致
+const uploadRoot = "./uploads"
+
+const storyCleanupBatchSize = 100
+
+var storyCleanerOnce sync.Once
+
+// StoryTrayItem 快拍栏中的一位用户
+type StoryTrayItem struct {
+	User        *models.User `json:"user"`
+	StoryCount  int          `json:"storyCount"`
+	HasUnseen   bool         `json:"hasUnseen"`
+	LatestAt    time.Time    `json:"latestAt"`
+	FirstUnseen int64        `json:"firstUnseenId"` // 第一条未看的快拍，全部看过时为0
+}
+
+// storyTTL 快拍有效期
+func storyTTL() time.Duration {
+	if ttl := config.Cfg.Story.TTL; ttl > 0 {
+		return ttl
+	}
+	return 24 * time.Hour
+}
+
+// CreateStory 发布快拍，mediaPath 为媒体文件在 uploads 目录下的相对路径
+func CreateStory(userID string, mediaType int, mediaURL, mediaPath, caption string) (*models.Story, error) {
+	now := time.Now()
+	story := &models.Story{
+		UserID:    userID,
+		MediaType: mediaType,
+		MediaURL:  mediaURL,
+		MediaPath: mediaPath,
+		Caption:   caption,
+		ExpiresAt: now.Add(storyTTL()),
+		CreatedAt: now,
+	}
+	if err := getDB().Create(story).Error; err != nil {
+		return nil, err
+	}
+	return story, nil
+}
+
+// canViewStories 快拍仅本人和好友可见
+func canViewStories(userID, ownerID string) bool {
+	return userID == ownerID || IsFriend(userID, ownerID)
+}
+
+// GetStoryTray 获取快拍栏：有未过期快拍的好友，有未看快拍的排在前面，其次按最新一条快拍的时间倒序
+// mine 为当前用户自己的快拍概况，没有快拍时为 nil
+func GetStoryTray(userID string) (mine *StoryTrayItem, tray []StoryTrayItem, err error) {
+	ownerIDs := append(GetFriendIDs(userID), userID)
+
+	var stories []models.Story
+	if err := getDB().Select("id", "user_id", "created_at").
+		Where("user_id IN ? AND expires_at > ?", ownerIDs, time.Now()).
+		Order("created_at ASC, id ASC").
+		Find(&stories).Error; err != nil {
+		return nil, nil, err
+	}
+	markSeenStories(stories, userID)
+
+	items := make(map[string]*StoryTrayItem)
+	var order []string
+	for _, s := range stories {
+		item, ok := items[s.UserID]
+		if !ok {
+			item = &StoryTrayItem{}
+			items[s.UserID] = item
+			order = append(order, s.UserID)
+		}
+		item.StoryCount++
+		item.LatestAt = s.CreatedAt
+		if !s.Seen && !item.HasUnseen && s.UserID != userID {
+			item.HasUnseen = true
+			item.FirstUnseen = s.ID
+		}
+	}
+
+	userIDSet := make(map[string]bool, len(order))
+	for _, id := range order {
+		userIDSet[id] = true
+	}
+	users := loadUsers(userIDSet)
+
+	tray = make([]StoryTrayItem, 0, len(order))
+	for _, id := range order {
+		items[id].User = users[id]
+		if id == userID {
+			mine = items[id]
+			continue
+		}
+		tray = append(tray, *items[id])
+	}
+	sort.SliceStable(tray, func(i, j int) bool {
+		if tray[i].HasUnseen != tray[j].HasUnseen {
+			return tray[i].HasUnseen
+		}
+		return tray[i].LatestAt.After(tray[j].LatestAt)
+	})
+
+	return mine, tray, nil
+}
+
+// GetUserStories 获取某个用户未过期的快拍，按发布时间正序
+func GetUserStories(userID, ownerID string) ([]models.Story, error) {
+	if !canViewStories(userID, ownerID) {
+		return nil, ErrStoryForbidden
+	}
+
+	var stories []models.Story
+	if err := getDB().Where("user_id = ? AND expires_at > ?", ownerID, time.Now()).
+		Order("created_at ASC, id ASC").
+		Find(&stories).Error; err != nil {
+		return nil, err
+	}
+	if userID == ownerID {
+		for i := range stories {
+			stories[i].Seen = true
+		}
+	} else {
+		markSeenStories(stories, userID)
+	}
+	return stories, nil
+}
+
+// markSeenStories 标记当前用户已看过的快拍
+func markSeenStories(stories []models.Story, userID string) {
+	if len(stories) == 0 {
+		return
+	}
+	ids := make([]int64, len(stories))
+	for i, s := range stories {
+		ids[i] = s.ID
+	}
+
+	var seen []int64
+	getDB().Model(&models.StoryView{}).
+		Where("viewer_id = ? AND story_id IN ?", userID, ids).
+		Pluck("story_id", &seen)
+
+	set := make(map[int64]bool, len(seen))
+	for _, id := range seen {
+		set[id] = true
+	}
+	for i := range stories {
+		stories[i].Seen = set[stories[i].ID]
+	}
+}
+
+// findActiveStory 获取未过期的快拍
+func findActiveStory(storyID int64) (*models.Story, error) {
+	var story models.Story
+	err := getDB().First(&story, "id = ? AND expires_at > ?", storyID, time.Now()).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrStoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &story, nil
+}
+
+// ViewStory 记录查看快拍，重复查看和作者本人查看不计数
+func ViewStory(userID string, storyID int64) error {
+	story, err := findActiveStory(storyID)
+	if err != nil {
+		return err
+	}
+	if !canViewStories(userID, story.UserID) {
+		return ErrStoryForbidden
+	}
+	if userID == story.UserID {
+		return nil
+	}
+
+	return getDB().Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.StoryView{
+			StoryID:  storyID,
+			ViewerID: userID,
+			ViewedAt: time.Now(),
+		})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&models.Story{}).Where("id = ?", storyID).
+			UpdateColumn("view_count", gorm.Expr("view_count + ?", 1)).Error
+	})
+}
+
+// GetStoryViewers 作者查看快拍的浏览者，按查看时间倒序
+func GetStoryViewers(userID string, storyID int64, opts PageOptions) ([]models.StoryView, PageInfo, error) {
+	var info PageInfo
+
+	story, err := findActiveStory(storyID)
+	if err != nil {
+		return nil, info, err
+	}
+	if story.UserID != userID {
+		return nil, info, ErrNotStoryOwner
+	}
+
+	opts = normalizePage(opts)
+	query, info, err := paginate(getDB().Model(&models.StoryView{}).Where("story_id = ?", storyID), opts, true)
+	if err != nil {
+		return nil, info, err
+	}
+
+	var views []models.StoryView
+	if err := query.Find(&views).Error; err != nil {
+		return nil, info, err
+	}
+	views = finishPage(views, opts, &info, func(v models.StoryView) string {
+		return encodeCursor(v.ViewedAt, v.ID)
+	})
+
+	userIDSet := make(map[string]bool, len(views))
+	for _, v := range views {
+		userIDSet[v.ViewerID] = true
+	}
+	users := loadUsers(userIDSet)
+	for i := range views {
+		views[i].User = users[views[i].ViewerID]
+	}
+
+	return views, info, nil
+}
+
+// DeleteStory 作者提前删除快拍
+func DeleteStory(userID string, storyID int64) error {
+	var story models.Story
+	err := getDB().First(&story, "id = ? AND user_id = ?", storyID, userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrStoryNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return removeStories([]models.Story{story})
+}
+
+// StartStoryCleaner 启动后台任务，按配置间隔删除过期快拍及其媒体文件
+func StartStoryCleaner() {
+	storyCleanerOnce.Do(func() {
+		go func() {
+			interval := config.Cfg.Story.CleanupInterval
+			if interval <= 0 {
+				interval = 5 * time.Minute
+			}
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				if err := CleanupExpiredStories(); err != nil {
+					log.Printf("⚠️  清理过期快拍失败: %v", err)
+				}
+				<-ticker.C
+			}
+		}()
+	})
+}
+
+// CleanupExpiredStories 删除已过期的快拍、浏览记录和媒体文件
+func CleanupExpiredStories() error {
+	for {
+		var stories []models.Story
+		if err := getDB().Where("expires_at <= ?", time.Now()).
+			Order("expires_at ASC").
+			Limit(storyCleanupBatchSize).
+			Find(&stories).Error; err != nil {
+			return err
+		}
+		if len(stories) == 0 {
+			return nil
+		}
+		if err := removeStories(stories); err != nil {
+			return err
+		}
+		if len(stories) < storyCleanupBatchSize {
+			return nil
+		}
+	}
+}
+
+// removeStories 删除快拍记录后再删除媒体文件，文件删除失败只记录日志
+func removeStories(stories []models.Story) error {
+	ids := make([]int64, len(stories))
+	for i, s := range stories {
+		ids[i] = s.ID
+	}
+
+	err := getDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("story_id IN ?", ids).Delete(&models.StoryView{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Delete(&models.Story{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, s := range stories {
+		if err := os.Remove(filepath.Join(uploadRoot, filepath.Clean("/"+s.MediaPath))); err != nil && !os.IsNotExist(err) {
+			log.Printf("⚠️  删除快拍媒体文件失败: %v", err)
+		}
+	}
+	return nil
+}
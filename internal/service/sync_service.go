@@ -0,0 +1,162 @@
+package service
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 单次同步返回的最大事件数
+const maxSyncBatch = 500
+
+// GetSyncEvents 获取 since 之后的同步事件，返回下次请求使用的 since
+func GetSyncEvents(userID string, since int64, limit int) ([]models.SyncEvent, int64, bool, error) {
+	if limit <= 0 || limit > maxSyncBatch {
+		limit = maxSyncBatch
+	}
+
+	var events []models.SyncEvent
+	err := getDB().Where("user_id = ? AND seq > ?", userID, since).
+		Order("seq ASC").
+		Limit(limit + 1).
+		Find(&events).Error
+	if err != nil {
+		return nil, since, false, err
+	}
+
+	hasMore := len(events) > limit
+	if hasMore {
+		events = events[:limit]
+	}
+
+	next := since
+	if len(events) > 0 {
+		next = events[len(events)-1].Seq
+	}
+
+	return events, next, hasMore, nil
+}
+
+// GetCurrentSyncSeq 获取用户当前的同步序号（新设备首次同步时以此为起点）
+func GetCurrentSyncSeq(userID string) int64 {
+	var seq models.UserSyncSeq
+	if err := getDB().Where("user_id = ?", userID).First(&seq).Error; err != nil {
+		return 0
+	}
+	return seq.Seq
+}
+
+// recordSyncEvent 为单个用户记录同步事件（peerID 为该用户视角下的单聊对方）
+func recordSyncEvent(db *gorm.DB, userID, eventType, peerID string, groupID, messageID int64, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		seq, err := nextSyncSeq(tx, userID)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&models.SyncEvent{
+			UserID:    userID,
+			Seq:       seq,
+			Type:      eventType,
+			PeerID:    peerID,
+			GroupID:   groupID,
+			MessageID: messageID,
+			Payload:   data,
+			CreatedAt: time.Now(),
+		}).Error
+	})
+}
+
+// recordGroupSyncEvent 为群成员批量记录同步事件：一次递增全部成员的序号，再批量写入事件
+func recordGroupSyncEvent(db *gorm.DB, userIDs []string, eventType string, groupID, messageID int64, payload interface{}) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	// 去重并固定顺序，按主键顺序加锁，避免并发群消息互相死锁
+	seen := make(map[string]bool, len(userIDs))
+	sorted := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if !seen[id] {
+			seen[id] = true
+			sorted = append(sorted, id)
+		}
+	}
+	sort.Strings(sorted)
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		rows := make([]models.UserSyncSeq, len(sorted))
+		for i, id := range sorted {
+			rows[i] = models.UserSyncSeq{UserID: id}
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.UserSyncSeq{}).Where("user_id IN ?", sorted).
+			UpdateColumn("seq", gorm.Expr("seq + 1")).Error; err != nil {
+			return err
+		}
+
+		var seqs []models.UserSyncSeq
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id IN ?", sorted).Find(&seqs).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		events := make([]models.SyncEvent, len(seqs))
+		for i, seq := range seqs {
+			events[i] = models.SyncEvent{
+				UserID:    seq.UserID,
+				Seq:       seq.Seq,
+				Type:      eventType,
+				GroupID:   groupID,
+				MessageID: messageID,
+				Payload:   data,
+				CreatedAt: now,
+			}
+		}
+		return tx.Create(&events).Error
+	})
+}
+
+// recordDirectSyncEvent 为单聊双方记录同步事件
+func recordDirectSyncEvent(db *gorm.DB, userID, peerID, eventType string, messageID int64, payload interface{}) error {
+	// 固定加锁顺序，避免双方同时发消息时互相死锁
+	first, second := userID, peerID
+	if first > second {
+		first, second = second, first
+	}
+	if err := recordSyncEvent(db, first, eventType, second, 0, messageID, payload); err != nil {
+		return err
+	}
+	return recordSyncEvent(db, second, eventType, first, 0, messageID, payload)
+}
+
+// nextSyncSeq 递增并返回用户的同步序号，序号行在事务提交前保持锁定
+func nextSyncSeq(tx *gorm.DB, userID string) (int64, error) {
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"seq": gorm.Expr("seq + 1")}),
+	}).Create(&models.UserSyncSeq{UserID: userID, Seq: 1}).Error; err != nil {
+		return 0, err
+	}
+
+	var seq models.UserSyncSeq
+	if err := tx.Where("user_id = ?", userID).First(&seq).Error; err != nil {
+		return 0, err
+	}
+	return seq.Seq, nil
+}
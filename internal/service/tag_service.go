@@ -3,6 +3,7 @@ package service
 import (
 	"github.com/Yw332/campus-moments-go/internal/models"
 	"gorm.io/gorm"
+	"strconv"
 	"time"
 )
 
@@ -113,7 +114,7 @@ func DeleteTag(tagID int64) error {
 	// 检查标签是否正在被使用
 	var postCount int64
 	getDB().Model(&models.Post{}).
-		Where("JSON_CONTAINS(tags, ?)", `"`+string(tagID)+`"`).
+		Where("JSON_CONTAINS(tags, ?)", `"`+strconv.FormatInt(tagID, 10)+`"`).
 		Count(&postCount)
 	
 	if postCount > 0 {
@@ -161,38 +162,15 @@ func SearchTags(keyword string, limit int) ([]models.Tag, error) {
 }
 
 // GetTagPosts 获取标签相关帖子
-func GetTagPosts(tagName, userID string, page, pageSize int) ([]models.Post, int64, error) {
-	var posts []models.Post
-	var total int64
-	
-	offset := (page - 1) * pageSize
+func GetTagPosts(tagName, userID string, opts PageOptions) ([]models.Post, PageInfo, error) {
+	opts = normalizePage(opts)
 	
-	// 查询包含该标签的帖子
+	// 查询包含该标签的帖子，可见性规则与帖子列表一致
 	query := getDB().Model(&models.Post{}).
 		Where("status = 0 AND JSON_CONTAINS(tags, ?)", `"`+tagName+`"`)
+	query = applyPostVisibility(query, userID, "all")
 	
-	// 根据可见性过滤
-	if userID != "" {
-		// 获取好友ID
-		friendIDs := GetFriendIDs(userID)
-		query = query.Where("(visibility = 0 OR (visibility = 1 AND user_id IN (?)) OR (visibility = 2 AND user_id = ?))", 
-			append(friendIDs, userID), userID)
-	} else {
-		// 只显示公开帖子
-		query = query.Where("visibility = 0")
-	}
-	
-	// 获取总数
-	query.Count(&total)
-	
-	// 获取帖子列表
-	err := query.Preload("User").
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(pageSize).
-		Find(&posts).Error
-	
-	return posts, total, err
+	return findPosts(query, opts, userID)
 }
 
 // UpdateTagUsage 更新标签使用统计
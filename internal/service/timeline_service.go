@@ -0,0 +1,209 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/Yw332/campus-moments-go/internal/models"
+	"github.com/Yw332/campus-moments-go/internal/timeline"
+	"github.com/Yw332/campus-moments-go/pkg/config"
+	"gorm.io/gorm"
+)
+
+// 首页由两部分合并而成：
+//   - 公开帖子：直接按 (created_at, id) 查询 posts 表，无需扇出
+//   - 好友可见帖子和自己的非公开帖子：发帖时写入作者和好友的时间线（写扩散），
+//     好友数超过 TimelineFanoutLimit 的作者只写入自己的时间线，由读取方按需拉取（读扩散）
+
+// fanOutPost 将非公开帖子写入相关用户的时间线
+func fanOutPost(postID int64) {
+	var post models.Post
+	if err := getDB().Select("id", "user_id", "visibility", "created_at", "status").
+		First(&post, "id = ?", postID).Error; err != nil || post.Status != 0 {
+		return
+	}
+
+	store := timeline.GetStore()
+	entry := timeline.Entry{PostID: post.ID, AuthorID: post.UserID, CreatedAt: post.CreatedAt}
+
+	switch post.Visibility {
+	case 1: // 好友可见
+		friendIDs := GetFriendIDs(post.UserID)
+		if len(friendIDs) > config.Cfg.Feed.TimelineFanoutLimit {
+			store.MarkHighFanout(post.UserID)
+			store.Push([]string{post.UserID}, entry)
+			return
+		}
+		store.Push(append(friendIDs, post.UserID), entry)
+	case 2: // 仅自己
+		store.Push([]string{post.UserID}, entry)
+	}
+}
+
+// removeFromTimelines 从作者及其好友的时间线中移除帖子（删帖或修改可见性时调用）
+func removeFromTimelines(postID int64, authorID string) {
+	timeline.GetStore().Remove(append(GetFriendIDs(authorID), authorID), postID)
+}
+
+// evictTimelines 好友关系变化后丢弃双方的时间线，下次读取时重新回填
+func evictTimelines(userIDs ...string) {
+	store := timeline.GetStore()
+	for _, id := range userIDs {
+		store.Evict(id)
+	}
+}
+
+// timelinePostsQuery 时间线覆盖的帖子：好友的好友可见帖子和自己的非公开帖子
+func timelinePostsQuery(userID string, friendIDs []string) *gorm.DB {
+	return getDB().Model(&models.Post{}).
+		Where("status = 0 AND ((visibility = 1 AND user_id IN ?) OR (user_id = ? AND visibility <> 0))", friendIDs, userID)
+}
+
+// loadTimeline 从数据库回填用户时间线
+// 先开始回填再读取好友和帖子，读取期间的发帖、删帖和好友关系变化由时间线存储合并或作废
+func loadTimeline(userID string) error {
+	maxLength := config.Cfg.Feed.TimelineMaxLength
+	store := timeline.GetStore()
+	store.BeginLoad(userID)
+
+	var posts []models.Post
+	if err := timelinePostsQuery(userID, GetFriendIDs(userID)).
+		Select("id", "user_id", "created_at").
+		Order("created_at DESC, id DESC").
+		Limit(maxLength).
+		Find(&posts).Error; err != nil {
+		return err
+	}
+
+	entries := make([]timeline.Entry, len(posts))
+	for i, p := range posts {
+		entries[i] = timeline.Entry{PostID: p.ID, AuthorID: p.UserID, CreatedAt: p.CreatedAt}
+	}
+	store.Load(userID, entries, len(posts) >= maxLength)
+	return nil
+}
+
+// getHomeTimeline 合并公开帖子、时间线和高扇出好友的帖子生成首页
+func getHomeTimeline(userID string, opts PageOptions) ([]models.Post, PageInfo, error) {
+	var info PageInfo
+
+	var after *timeline.Entry
+	if opts.Cursor != "" {
+		createdAt, id, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, info, err
+		}
+		after = &timeline.Entry{PostID: id, CreatedAt: createdAt}
+	}
+	limit := opts.PageSize + 1
+	keyset := PageOptions{PageSize: opts.PageSize, Cursor: opts.Cursor}
+	friendIDs := GetFriendIDs(userID)
+	blockedIDs := GetBlockedUserIDs(userID)
+
+	// 公开帖子
+	public := getDB().Model(&models.Post{}).Where("status = 0 AND visibility = 0")
+	if len(blockedIDs) > 0 {
+		public = public.Where("user_id NOT IN ?", blockedIDs)
+	}
+	candidates, err := findPostsAfter(public, keyset)
+	if err != nil {
+		return nil, info, err
+	}
+
+	// 时间线
+	store := timeline.GetStore()
+	entries, ok := store.Range(userID, after, limit)
+	if !ok && !store.Loaded(userID) {
+		if err := loadTimeline(userID); err != nil {
+			return nil, info, err
+		}
+		entries, ok = store.Range(userID, after, limit)
+	}
+
+	if ok {
+		if len(entries) > 0 {
+			ids := make([]int64, len(entries))
+			for i, e := range entries {
+				ids[i] = e.PostID
+			}
+			var posts []models.Post
+			if err := getDB().Where("id IN ? AND status = 0", ids).Find(&posts).Error; err != nil {
+				return nil, info, err
+			}
+			// 时间线只是索引，按帖子当前的可见性再检查一遍
+			rel := newViewerRelations(friendIDs, blockedIDs)
+			for _, p := range posts {
+				if CanViewPost(userID, p.UserID, p.Visibility, rel) {
+					candidates = append(candidates, p)
+				}
+			}
+		}
+
+		// 高扇出好友的帖子没有写入时间线，按需拉取
+		if highFanout := store.HighFanout(friendIDs); len(highFanout) > 0 {
+			query := getDB().Model(&models.Post{}).Where("status = 0 AND visibility = 1 AND user_id IN ?", highFanout)
+			posts, err := findPostsAfter(query, keyset)
+			if err != nil {
+				return nil, info, err
+			}
+			candidates = append(candidates, posts...)
+		}
+	} else {
+		// 超出内存中时间线的范围，回退到数据库查询
+		posts, err := findPostsAfter(timelinePostsQuery(userID, friendIDs), keyset)
+		if err != nil {
+			return nil, info, err
+		}
+		candidates = append(candidates, posts...)
+	}
+
+	posts := mergePosts(candidates)
+	posts = finishPage(posts, opts, &info, func(p models.Post) string {
+		return encodeCursor(p.CreatedAt, p.ID)
+	})
+
+	if opts.WithTotal {
+		query := getDB().Model(&models.Post{}).Where("status = ?", 0)
+		applyPostVisibility(query, userID, "all").Count(&info.Total)
+	}
+
+	attachPostUsers(posts)
+	attachPostMentions(posts)
+	attachReposts(posts, userID)
+	attachPolls(posts, userID)
+	attachBookmarks(posts, userID)
+	attachPostReactions(posts, userID)
+	attachPostFriendLikes(posts, userID)
+
+	return posts, info, nil
+}
+
+// findPostsAfter 按 (created_at, id) 倒序查询游标之后的帖子，多取一条用于判断是否还有下一页
+func findPostsAfter(query *gorm.DB, opts PageOptions) ([]models.Post, error) {
+	query, _, err := paginate(query, opts, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []models.Post
+	err = query.Find(&posts).Error
+	return posts, err
+}
+
+// mergePosts 按 (created_at, id) 倒序合并多路帖子并去重
+func mergePosts(posts []models.Post) []models.Post {
+	sort.Slice(posts, func(i, j int) bool {
+		if posts[i].CreatedAt.Equal(posts[j].CreatedAt) {
+			return posts[i].ID > posts[j].ID
+		}
+		return posts[i].CreatedAt.After(posts[j].CreatedAt)
+	})
+
+	merged := make([]models.Post, 0, len(posts))
+	for _, p := range posts {
+		if n := len(merged); n > 0 && merged[n-1].ID == p.ID {
+			continue
+		}
+		merged = append(merged, p)
+	}
+	return merged
+}
@@ -37,6 +37,9 @@ type PublicUserInfo struct {
 	PostCount       int        `json:"postCount"`
 	LikeCount       int        `json:"likeCount"`
 	CommentCount    int        `json:"commentCount"`
+	FollowerCount   int        `json:"followerCount"`
+	FollowingCount  int        `json:"followingCount"`
+	IsFollowing     bool       `json:"isFollowing"` // 当前用户是否已关注
 	Signature       string     `json:"signature"`
 	LastActiveAt    *time.Time `json:"lastActiveAt"`
 }
@@ -56,8 +59,8 @@ func (s *UserService) GetUserByID(userID string) (*models.User, error) {
 	return &user, nil
 }
 
-// GetPublicUserInfo 获取公开用户信息
-func (s *UserService) GetPublicUserInfo(userID string) (*PublicUserInfo, error) {
+// GetPublicUserInfo 获取公开用户信息，viewerID 为当前登录用户
+func (s *UserService) GetPublicUserInfo(viewerID, userID string) (*PublicUserInfo, error) {
 	user, err := s.GetUserByID(userID)
 	if err != nil {
 		return nil, err
@@ -72,6 +75,9 @@ func (s *UserService) GetPublicUserInfo(userID string) (*PublicUserInfo, error)
 		PostCount:       user.PostCount,
 		LikeCount:       user.LikeCount,
 		CommentCount:    user.CommentCount,
+		FollowerCount:   user.FollowerCount,
+		FollowingCount:  user.FollowingCount,
+		IsFollowing:     IsFollowing(viewerID, user.ID),
 		Signature:       user.Signature,
 		LastActiveAt:    user.LastActiveAt,
 	}, nil
@@ -209,14 +215,18 @@ func (s *UserService) UpdateLastActive(userID string) error {
 	return nil
 }
 
-// SearchUsers 搜索用户
-func (s *UserService) SearchUsers(keyword string, page, pageSize int) ([]PublicUserInfo, int64, error) {
+// SearchUsers 搜索用户（不返回与当前用户存在拉黑关系的用户）
+func (s *UserService) SearchUsers(currentUserID, keyword string, page, pageSize int) ([]PublicUserInfo, int64, error) {
 	db := database.GetDB()
 
 	var total int64
 	query := db.Model(&models.User{}).
 		Where("username LIKE ? OR phone LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
 
+	if blockedIDs := GetBlockedUserIDs(currentUserID); len(blockedIDs) > 0 {
+		query = query.Where("id NOT IN ?", blockedIDs)
+	}
+
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -227,6 +237,12 @@ func (s *UserService) SearchUsers(keyword string, page, pageSize int) ([]PublicU
 		return nil, 0, err
 	}
 
+	userIDs := make([]string, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+	following := getFollowingSet(currentUserID, userIDs)
+
 	// 转换为公开信息
 	result := make([]PublicUserInfo, len(users))
 	for i, user := range users {
@@ -239,6 +255,9 @@ func (s *UserService) SearchUsers(keyword string, page, pageSize int) ([]PublicU
 			PostCount:       user.PostCount,
 			LikeCount:       user.LikeCount,
 			CommentCount:    user.CommentCount,
+			FollowerCount:   user.FollowerCount,
+			FollowingCount:  user.FollowingCount,
+			IsFollowing:     following[user.ID],
 			Signature:       user.Signature,
 			LastActiveAt:    user.LastActiveAt,
 		}
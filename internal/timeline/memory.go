@@ -0,0 +1,252 @@
+package timeline
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 每个用户时间线默认保留的最大条目数
+const defaultMaxLength = 800
+
+// 时间线默认的闲置过期时间
+const defaultIdleTTL = 30 * time.Minute
+
+// userTimeline 单个用户的时间线，entries 按 (CreatedAt, PostID) 倒序排列
+type userTimeline struct {
+	entries   []Entry
+	truncated bool         // 更早的条目已被丢弃或未载入
+	lastRead  atomic.Int64 // 最近一次读取的时间（UnixNano），Range 只持有读锁，因此用原子操作
+}
+
+// pendingLoad 回填期间（BeginLoad 到 Load 之间）写入的变更，Load 时合并到数据库读到的条目上
+type pendingLoad struct {
+	pushed    map[int64]Entry
+	removed   map[int64]bool
+	stale     bool // 回填期间时间线被丢弃（如好友关系变化），读到的条目可能已过时
+	startedAt time.Time
+}
+
+// MemoryStore 进程内时间线存储，多实例部署时各实例独立维护，重启后按需回填
+// 闲置超过 idleTTL 的时间线在回填其他用户的时间线时顺带清理，再次读取时重新回填
+type MemoryStore struct {
+	mu         sync.RWMutex
+	maxLength  int
+	idleTTL    time.Duration
+	lastSweep  time.Time
+	timelines  map[string]*userTimeline
+	pending    map[string]*pendingLoad
+	highFanout map[string]struct{}
+}
+
+// NewMemoryStore 创建进程内时间线存储，maxLength 为每个用户保留的最大条目数，idleTTL 为时间线的闲置过期时间
+func NewMemoryStore(maxLength int, idleTTL time.Duration) *MemoryStore {
+	if maxLength <= 0 {
+		maxLength = defaultMaxLength
+	}
+	if idleTTL <= 0 {
+		idleTTL = defaultIdleTTL
+	}
+	return &MemoryStore{
+		maxLength:  maxLength,
+		idleTTL:    idleTTL,
+		lastSweep:  time.Now(),
+		timelines:  make(map[string]*userTimeline),
+		pending:    make(map[string]*pendingLoad),
+		highFanout: make(map[string]struct{}),
+	}
+}
+
+// Loaded 用户时间线是否已加载
+func (s *MemoryStore) Loaded(userID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.timelines[userID]
+	return ok
+}
+
+// BeginLoad 开始回填用户时间线，之后到 Load 之间的 Push、Remove 会被记录下来
+// 上次回填未完成时沿用原来的记录，它覆盖的时间段更长
+func (s *MemoryStore) BeginLoad(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.pending[userID]; !ok {
+		s.pending[userID] = &pendingLoad{pushed: make(map[int64]Entry), removed: make(map[int64]bool), startedAt: time.Now()}
+	}
+}
+
+// Load 回填用户时间线，合并回填期间写入的变更
+// 时间线已被并发的回填加载时保留已有的（它一直在增量维护），回填期间被丢弃时不加载
+func (s *MemoryStore) Load(userID string, entries []Entry, truncated bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= s.idleTTL {
+		s.sweep(now)
+	}
+
+	p := s.pending[userID]
+	delete(s.pending, userID)
+	if _, ok := s.timelines[userID]; ok || (p != nil && p.stale) {
+		return
+	}
+
+	merged := make([]Entry, 0, len(entries))
+	seen := make(map[int64]bool, len(entries))
+	for _, e := range entries {
+		if seen[e.PostID] || (p != nil && p.removed[e.PostID]) {
+			continue
+		}
+		seen[e.PostID] = true
+		merged = append(merged, e)
+	}
+	if p != nil {
+		for id, e := range p.pushed {
+			if !seen[id] {
+				seen[id] = true
+				merged = append(merged, e)
+			}
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[j].Before(merged[i]) })
+
+	if len(merged) > s.maxLength {
+		merged = merged[:s.maxLength]
+		truncated = true
+	}
+	tl := &userTimeline{entries: merged, truncated: truncated}
+	tl.lastRead.Store(now.UnixNano())
+	s.timelines[userID] = tl
+}
+
+// sweep 清理闲置过期的时间线，以及超时未完成（如读取数据库失败）的回填记录
+func (s *MemoryStore) sweep(now time.Time) {
+	expired := now.Add(-s.idleTTL)
+	for userID, tl := range s.timelines {
+		if tl.lastRead.Load() < expired.UnixNano() {
+			delete(s.timelines, userID)
+		}
+	}
+	for userID, p := range s.pending {
+		if p.startedAt.Before(expired) {
+			delete(s.pending, userID)
+		}
+	}
+	s.lastSweep = now
+}
+
+// Push 将帖子写入多个用户已加载的时间线
+func (s *MemoryStore) Push(userIDs []string, entry Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, userID := range userIDs {
+		if p, ok := s.pending[userID]; ok {
+			p.pushed[entry.PostID] = entry
+			delete(p.removed, entry.PostID)
+		}
+		tl, ok := s.timelines[userID]
+		if !ok {
+			continue
+		}
+
+		// 找到第一个排在 entry 之后的位置插入，新帖通常插在最前面
+		i := sort.Search(len(tl.entries), func(i int) bool { return tl.entries[i].Before(entry) })
+		if i > 0 && tl.entries[i-1].PostID == entry.PostID {
+			continue
+		}
+		tl.entries = append(tl.entries, Entry{})
+		copy(tl.entries[i+1:], tl.entries[i:])
+		tl.entries[i] = entry
+
+		if len(tl.entries) > s.maxLength {
+			tl.entries = tl.entries[:s.maxLength]
+			tl.truncated = true
+		}
+	}
+}
+
+// Remove 从多个用户的时间线中移除帖子
+func (s *MemoryStore) Remove(userIDs []string, postID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, userID := range userIDs {
+		if p, ok := s.pending[userID]; ok {
+			delete(p.pushed, postID)
+			p.removed[postID] = true
+		}
+		tl, ok := s.timelines[userID]
+		if !ok {
+			continue
+		}
+		for i, e := range tl.entries {
+			if e.PostID == postID {
+				tl.entries = append(tl.entries[:i], tl.entries[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Range 按倒序返回排在 after 之后的最多 limit 条
+func (s *MemoryStore) Range(userID string, after *Entry, limit int) ([]Entry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tl, ok := s.timelines[userID]
+	if !ok {
+		return nil, false
+	}
+	tl.lastRead.Store(time.Now().UnixNano())
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(tl.entries), func(i int) bool { return tl.entries[i].Before(*after) })
+	}
+	end := start + limit
+	if end > len(tl.entries) {
+		// 已读到载入部分的末尾，更早的条目不在内存中
+		if tl.truncated {
+			return nil, false
+		}
+		end = len(tl.entries)
+	}
+
+	result := make([]Entry, end-start)
+	copy(result, tl.entries[start:end])
+	return result, true
+}
+
+// Evict 丢弃用户时间线，正在进行的回填读到的条目也作废
+func (s *MemoryStore) Evict(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.timelines, userID)
+	if p, ok := s.pending[userID]; ok {
+		p.stale = true
+	}
+}
+
+// MarkHighFanout 记录高扇出作者
+func (s *MemoryStore) MarkHighFanout(authorID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.highFanout[authorID] = struct{}{}
+}
+
+// HighFanout 返回 authorIDs 中的高扇出作者
+func (s *MemoryStore) HighFanout(authorIDs []string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []string
+	for _, id := range authorIDs {
+		if _, ok := s.highFanout[id]; ok {
+			result = append(result, id)
+		}
+	}
+	return result
+}
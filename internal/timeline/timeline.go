@@ -0,0 +1,68 @@
+package timeline
+
+import (
+	"sync"
+	"time"
+)
+
+// Entry 时间线中的一条帖子
+type Entry struct {
+	PostID    int64
+	AuthorID  string
+	CreatedAt time.Time
+}
+
+// Before 是否排在 other 之后（按 (CreatedAt, PostID) 倒序）
+func (e Entry) Before(other Entry) bool {
+	if e.CreatedAt.Equal(other.CreatedAt) {
+		return e.PostID < other.PostID
+	}
+	return e.CreatedAt.Before(other.CreatedAt)
+}
+
+// Store 按用户保存首页时间线（好友可见帖子和自己的非公开帖子）
+// 时间线在首次读取时从数据库回填，之后由发帖、删帖等写操作增量维护；实现可以随时丢弃闲置的时间线
+type Store interface {
+	// Loaded 用户时间线是否已加载
+	Loaded(userID string) bool
+	// BeginLoad 开始回填用户时间线，需在从数据库读取条目之前调用
+	// 之后到 Load 之间的 Push、Remove 会在 Load 时合并，避免回填期间的写入丢失
+	BeginLoad(userID string)
+	// Load 回填用户时间线，truncated 表示数据库中还有更早的条目未载入
+	// 回填期间时间线被 Evict 时不加载，下次读取时重新回填
+	Load(userID string, entries []Entry, truncated bool)
+	// Push 将帖子写入多个用户的时间线，未加载的时间线会被跳过
+	Push(userIDs []string, entry Entry)
+	// Remove 从多个用户的时间线中移除帖子
+	Remove(userIDs []string, postID int64)
+	// Range 按 (CreatedAt, PostID) 倒序返回排在 after 之后的最多 limit 条，after 为 nil 时从最新开始
+	// ok 为 false 表示时间线未加载，或请求范围超出了已载入的部分，调用方需回退到数据库查询
+	Range(userID string, after *Entry, limit int) (entries []Entry, ok bool)
+	// Evict 丢弃用户时间线，下次读取时重新回填（好友关系变化时使用）
+	Evict(userID string)
+
+	// MarkHighFanout 记录高扇出作者：其好友可见帖子不写入好友的时间线，由读取方拉取
+	MarkHighFanout(authorID string)
+	// HighFanout 返回 authorIDs 中的高扇出作者
+	HighFanout(authorIDs []string) []string
+}
+
+var (
+	instance Store
+	once     sync.Once
+)
+
+// GetStore 获取时间线存储，默认使用进程内实现
+func GetStore() Store {
+	once.Do(func() {
+		if instance == nil {
+			instance = NewMemoryStore(defaultMaxLength, defaultIdleTTL)
+		}
+	})
+	return instance
+}
+
+// SetStore 替换时间线存储（如 Redis 实现），需在首次调用 GetStore 前设置
+func SetStore(store Store) {
+	instance = store
+}
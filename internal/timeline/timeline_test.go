@@ -0,0 +1,191 @@
+package timeline_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yw332/campus-moments-go/internal/timeline"
+	"github.com/stretchr/testify/assert"
+)
+
+var base = time.Date(2024, 12, 30, 12, 0, 0, 0, time.UTC)
+
+// entry 编号越大越新
+func entry(id int64) timeline.Entry {
+	return timeline.Entry{PostID: id, AuthorID: "0000000001", CreatedAt: base.Add(time.Duration(id) * time.Minute)}
+}
+
+// entries 按给定顺序生成条目
+func entries(ids ...int64) []timeline.Entry {
+	result := make([]timeline.Entry, len(ids))
+	for i, id := range ids {
+		result[i] = entry(id)
+	}
+	return result
+}
+
+// postIDs 取出条目的帖子ID
+func postIDs(list []timeline.Entry) []int64 {
+	ids := make([]int64, len(list))
+	for i, e := range list {
+		ids[i] = e.PostID
+	}
+	return ids
+}
+
+func TestMemoryStoreRange(t *testing.T) {
+	after := func(id int64) *timeline.Entry {
+		e := entry(id)
+		return &e
+	}
+
+	tests := []struct {
+		name      string
+		loaded    []timeline.Entry
+		truncated bool
+		after     *timeline.Entry
+		limit     int
+		want      []int64
+		wantOK    bool
+	}{
+		{name: "从最新开始", loaded: entries(1, 2, 3, 4, 5), limit: 2, want: []int64{5, 4}, wantOK: true},
+		{name: "游标之后", loaded: entries(1, 2, 3, 4, 5), after: after(4), limit: 2, want: []int64{3, 2}, wantOK: true},
+		{name: "游标不在时间线中", loaded: entries(1, 3, 5), after: after(4), limit: 5, want: []int64{3, 1}, wantOK: true},
+		{name: "读到末尾未截断", loaded: entries(1, 2, 3), after: after(2), limit: 5, want: []int64{1}, wantOK: true},
+		{name: "游标越过末尾未截断", loaded: entries(2, 3), after: after(1), limit: 5, want: []int64{}, wantOK: true},
+		{name: "截断但范围在内存中", loaded: entries(1, 2, 3, 4), truncated: true, after: after(4), limit: 3, want: []int64{3, 2, 1}, wantOK: true},
+		{name: "截断且超出载入部分", loaded: entries(1, 2, 3, 4), truncated: true, after: after(3), limit: 3, wantOK: false},
+		{name: "截断且游标越过末尾", loaded: entries(2, 3), truncated: true, after: after(1), limit: 1, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := timeline.NewMemoryStore(100, time.Hour)
+			store.Load("u1", tt.loaded, tt.truncated)
+
+			got, ok := store.Range("u1", tt.after, tt.limit)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.want, postIDs(got))
+			} else {
+				assert.Nil(t, got)
+			}
+		})
+	}
+
+	// 未加载的时间线需要回退到数据库
+	got, ok := timeline.NewMemoryStore(100, time.Hour).Range("u1", nil, 10)
+	assert.False(t, ok)
+	assert.Nil(t, got)
+}
+
+func TestMemoryStorePush(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxLength     int
+		loaded        []timeline.Entry
+		push          []timeline.Entry
+		want          []int64
+		wantTruncated bool
+	}{
+		{name: "新帖插在最前", maxLength: 10, loaded: entries(1, 2), push: entries(3), want: []int64{3, 2, 1}},
+		{name: "按时间插入中间", maxLength: 10, loaded: entries(1, 3), push: entries(2), want: []int64{3, 2, 1}},
+		{name: "重复写入去重", maxLength: 10, loaded: entries(1, 2), push: entries(2, 2), want: []int64{2, 1}},
+		{name: "超出长度丢弃最旧的", maxLength: 2, loaded: entries(1, 2), push: entries(3), want: []int64{3, 2}, wantTruncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := timeline.NewMemoryStore(tt.maxLength, time.Hour)
+			store.Load("u1", tt.loaded, false)
+			for _, e := range tt.push {
+				store.Push([]string{"u1", "u2"}, e)
+			}
+
+			got, ok := store.Range("u1", nil, tt.maxLength)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, postIDs(got))
+
+			// 截断后读到末尾需要回退到数据库
+			_, ok = store.Range("u1", nil, tt.maxLength+1)
+			assert.Equal(t, !tt.wantTruncated, ok)
+
+			// 未加载的时间线不会被写入
+			assert.False(t, store.Loaded("u2"))
+		})
+	}
+}
+
+func TestMemoryStoreLoad(t *testing.T) {
+	t.Run("按时间排序去重并截断", func(t *testing.T) {
+		store := timeline.NewMemoryStore(3, time.Hour)
+		store.Load("u1", entries(2, 5, 1, 5, 4), false)
+
+		got, ok := store.Range("u1", nil, 3)
+		assert.True(t, ok)
+		assert.Equal(t, []int64{5, 4, 2}, postIDs(got))
+		_, ok = store.Range("u1", nil, 4)
+		assert.False(t, ok)
+	})
+
+	t.Run("合并回填期间的写入", func(t *testing.T) {
+		store := timeline.NewMemoryStore(10, time.Hour)
+		store.BeginLoad("u1")
+		store.Push([]string{"u1"}, entry(6))
+		store.Remove([]string{"u1"}, 3)
+		store.Push([]string{"u1"}, entry(4))
+		store.Remove([]string{"u1"}, 4)
+		store.Remove([]string{"u1"}, 5)
+		store.Push([]string{"u1"}, entry(5))
+		// 数据库读到的是写入之前的状态
+		store.Load("u1", entries(1, 2, 3, 5), false)
+
+		got, ok := store.Range("u1", nil, 10)
+		assert.True(t, ok)
+		assert.Equal(t, []int64{6, 5, 2, 1}, postIDs(got))
+	})
+
+	t.Run("回填期间被丢弃时不加载", func(t *testing.T) {
+		store := timeline.NewMemoryStore(10, time.Hour)
+		store.BeginLoad("u1")
+		store.Evict("u1")
+		store.Load("u1", entries(1, 2), false)
+		assert.False(t, store.Loaded("u1"))
+
+		// 下次回填正常加载
+		store.BeginLoad("u1")
+		store.Load("u1", entries(1, 2), false)
+		assert.True(t, store.Loaded("u1"))
+	})
+
+	t.Run("并发回填保留先加载的时间线", func(t *testing.T) {
+		store := timeline.NewMemoryStore(10, time.Hour)
+		store.BeginLoad("u1")
+		store.BeginLoad("u1")
+		store.Load("u1", entries(1, 2), false)
+		store.Push([]string{"u1"}, entry(3))
+		// 后完成的回填读到的条目更旧，不能覆盖已增量维护的时间线
+		store.Load("u1", entries(1, 2), false)
+
+		got, ok := store.Range("u1", nil, 10)
+		assert.True(t, ok)
+		assert.Equal(t, []int64{3, 2, 1}, postIDs(got))
+	})
+}
+
+func TestMemoryStoreIdleEviction(t *testing.T) {
+	store := timeline.NewMemoryStore(10, 100*time.Millisecond)
+	store.Load("idle", entries(1), false)
+	store.Load("active", entries(2), false)
+
+	time.Sleep(60 * time.Millisecond)
+	_, ok := store.Range("active", nil, 10)
+	assert.True(t, ok)
+	time.Sleep(60 * time.Millisecond)
+
+	// 回填其他用户时清理闲置的时间线，最近读取过的保留
+	store.Load("other", entries(3), false)
+	assert.False(t, store.Loaded("idle"))
+	assert.True(t, store.Loaded("active"))
+	assert.True(t, store.Loaded("other"))
+}
@@ -0,0 +1,95 @@
+-- 把 comments.replies 中的 JSON 回复转换为 parent_id / root_id 楼层结构（需要 MySQL 8.0）
+-- 执行前请先备份 comments 表；除步骤1外可以重复执行
+
+-- 步骤1: 添加楼层字段（应用启动时 AutoMigrate 已经添加过的话跳过这一步）
+ALTER TABLE comments
+    ADD COLUMN parent_id BIGINT DEFAULT 0 COMMENT '被回复的评论，0表示一级评论' AFTER content,
+    ADD COLUMN root_id BIGINT DEFAULT 0 COMMENT '所属的一级评论，一级评论为0' AFTER parent_id,
+    ADD COLUMN reply_to_user_id CHAR(10) NULL COMMENT '被回复的用户' AFTER root_id,
+    ADD COLUMN reply_count INT DEFAULT 0 COMMENT '一级评论下未删除的回复数' AFTER reply_to_user_id,
+    ADD INDEX idx_comments_root_created (root_id, created_at);
+
+-- 步骤2: 旧版 ReplyComment 会同时创建真实的评论行，用 JSON 中的 id 找回它们的父评论
+-- JSON 由客户端提交，只认同一帖子下、作者与 JSON 中 userId 一致的评论行，避免伪造的 id 把别人的评论挂到其他楼层
+UPDATE comments c
+JOIN (
+    SELECT DISTINCT p.id AS parent_id, p.post_id, p.user_id AS parent_user_id, j.reply_id, j.user_id
+    FROM comments p,
+         JSON_TABLE(p.replies, '$[*]' COLUMNS (
+             reply_id BIGINT   PATH '$.id'     NULL ON ERROR,
+             user_id  CHAR(10) PATH '$.userId' NULL ON ERROR
+         )) j
+    WHERE p.replies IS NOT NULL AND JSON_TYPE(p.replies) = 'ARRAY' AND j.reply_id IS NOT NULL
+) r ON r.reply_id = c.id AND r.parent_id <> c.id AND r.post_id = c.post_id AND r.user_id = c.user_id
+SET c.parent_id = r.parent_id,
+    c.reply_to_user_id = r.parent_user_id
+WHERE c.parent_id = 0;
+
+-- 步骤3: 创建评论时客户端直接提交的回复没有对应的评论行，其中的 userId 未经校验，不能据此创建真实的评论
+-- 这些回复转存到 comment_replies_unverified 表供人工核查，不出现在评论区
+CREATE TABLE IF NOT EXISTS comment_replies_unverified (
+    id              BIGINT AUTO_INCREMENT PRIMARY KEY,
+    comment_id      BIGINT NOT NULL COMMENT '所在的一级评论',
+    post_id         BIGINT NOT NULL,
+    claimed_user_id CHAR(10) NULL COMMENT 'JSON 中声称的回复者，未经校验',
+    user_exists     TINYINT(1) NOT NULL DEFAULT 0 COMMENT '声称的回复者是否为已存在的用户',
+    content         VARCHAR(1000) NULL,
+    created_at      DATETIME NULL COMMENT 'JSON 中声称的回复时间',
+    raw             JSON NOT NULL COMMENT '原始 JSON',
+    raw_hash        CHAR(64) NOT NULL COMMENT '原始 JSON 的 SHA-256，重复执行时去重',
+    UNIQUE KEY idx_unverified_comment_raw (comment_id, raw_hash)
+);
+
+INSERT IGNORE INTO comment_replies_unverified (comment_id, post_id, claimed_user_id, user_exists, content, created_at, raw, raw_hash)
+SELECT p.id, p.post_id, j.user_id, EXISTS (SELECT 1 FROM users u WHERE u.id = j.user_id),
+       j.content, j.created_at, j.raw, SHA2(CAST(j.raw AS CHAR), 256)
+FROM comments p,
+     JSON_TABLE(p.replies, '$[*]' COLUMNS (
+         reply_id   BIGINT        PATH '$.id'        NULL ON ERROR,
+         user_id    CHAR(10)      PATH '$.userId'    NULL ON ERROR,
+         content    VARCHAR(1000) PATH '$.content'   NULL ON ERROR,
+         created_at DATETIME      PATH '$.createdAt' NULL ON ERROR,
+         raw        JSON          PATH '$'
+     )) j
+WHERE p.replies IS NOT NULL AND JSON_TYPE(p.replies) = 'ARRAY'
+  AND NOT EXISTS (
+      SELECT 1 FROM comments e
+      WHERE e.id = j.reply_id AND e.parent_id = p.id
+  );
+
+-- 步骤4: 沿 parent_id 向上找到一级评论，回填 root_id
+UPDATE comments c
+JOIN (
+    WITH RECURSIVE chain (id, root_id, depth) AS (
+        SELECT id, id, 0 FROM comments WHERE parent_id = 0
+        UNION ALL
+        SELECT c2.id, chain.root_id, chain.depth + 1
+        FROM comments c2 JOIN chain ON c2.parent_id = chain.id
+        WHERE chain.depth < 100
+    )
+    SELECT id, root_id FROM chain
+) t ON t.id = c.id
+SET c.root_id = IF(c.parent_id = 0, 0, t.root_id);
+
+-- 步骤5: 重新统计每个一级评论的回复数
+UPDATE comments c
+LEFT JOIN (
+    SELECT root_id, COUNT(*) AS cnt FROM comments
+    WHERE root_id <> 0 AND status = 0
+    GROUP BY root_id
+) r ON r.root_id = c.id
+SET c.reply_count = COALESCE(r.cnt, 0)
+WHERE c.parent_id = 0;
+
+-- 步骤6: 清空旧的 JSON 回复
+UPDATE comments SET replies = NULL WHERE replies IS NOT NULL;
+
+-- 步骤7: 重新统计帖子的评论数
+UPDATE posts p
+LEFT JOIN (
+    SELECT post_id, COUNT(*) AS cnt FROM comments WHERE status = 0 GROUP BY post_id
+) c ON c.post_id = p.id
+SET p.comment_count = COALESCE(c.cnt, 0);
+
+-- 确认数据无误后可以删除旧字段
+-- ALTER TABLE comments DROP COLUMN replies;
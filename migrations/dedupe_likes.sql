@@ -0,0 +1,34 @@
+-- 让 likes 表成为点赞的唯一数据来源：去重、添加 (user_id, target_type, target_id) 唯一索引并删除 posts.liked_users
+-- 必须在部署新版本之前执行：表中还有重复记录时，应用启动时 AutoMigrate 创建唯一索引会失败
+-- 执行前请先备份 likes 和 posts 表；除步骤3、步骤5外可以重复执行
+
+-- 步骤1: 同一用户对同一目标的重复记录只保留最早的一条
+DELETE l FROM likes l
+JOIN likes e ON e.user_id = l.user_id AND e.target_type = l.target_type AND e.target_id = l.target_id AND e.id < l.id;
+
+-- 步骤2: 按去重后的记录重新统计帖子和评论的点赞数
+UPDATE posts p
+LEFT JOIN (
+    SELECT target_id, COUNT(*) AS cnt FROM likes WHERE target_type = 1 GROUP BY target_id
+) l ON l.target_id = p.id
+SET p.like_count = COALESCE(l.cnt, 0);
+
+UPDATE comments c
+LEFT JOIN (
+    SELECT target_id, COUNT(*) AS cnt FROM likes WHERE target_type = 2 GROUP BY target_id
+) l ON l.target_id = c.id
+SET c.like_count = COALESCE(l.cnt, 0);
+
+-- 步骤3: 添加唯一索引，它同时覆盖只按 user_id 的查询，旧的单列索引可以删除
+ALTER TABLE likes
+    ADD UNIQUE INDEX idx_likes_user_target (user_id, target_type, target_id),
+    DROP INDEX idx_likes_user_id;
+
+-- 步骤4: 检查唯一索引已经生效，应返回 0
+SELECT COUNT(*) FROM (
+    SELECT user_id, target_type, target_id FROM likes
+    GROUP BY user_id, target_type, target_id HAVING COUNT(*) > 1
+) d;
+
+-- 步骤5: 点赞用户改为从 likes 表查询，删除旧的 JSON 字段
+ALTER TABLE posts DROP COLUMN liked_users;
@@ -5,6 +5,7 @@ import (
 "log"
 "os"
 "strconv"
+"strings"
 "time"
 
 "github.com/joho/godotenv"
@@ -16,6 +17,11 @@ App      AppConfig
 Server   ServerConfig
 Database DatabaseConfig
 JWT      JWTConfig
+Message  MessageConfig
+Feed     FeedConfig
+Story    StoryConfig
+Reaction ReactionConfig
+Search   SearchConfig
 }
 
 type AppConfig struct {
@@ -47,6 +53,44 @@ Secret      string
 ExpireHours int
 }
 
+// MessageConfig 私信相关配置
+type MessageConfig struct {
+RecallWindow time.Duration // 发送后可撤回的时间
+EditWindow   time.Duration // 发送后可编辑的时间
+}
+
+// FeedConfig 信息流相关配置
+type FeedConfig struct {
+HotLikeWeight        float64       // 热度分中点赞数的权重
+HotCommentWeight     float64       // 热度分中评论数的权重
+HotViewWeight        float64       // 热度分中浏览量的权重
+HotGravity           float64       // 时间衰减指数，越大旧帖下沉越快
+HotRecomputeInterval time.Duration // 后台重算热度分的间隔
+HotWindow            time.Duration // 只重算该时间范围内发布的帖子，更早的帖子热度分归零
+TimelineFanoutLimit  int           // 好友数超过该值的作者发帖不写入好友时间线，由读取方拉取
+TimelineMaxLength    int           // 每个用户时间线在内存中保留的最大条目数
+TimelineIdleTTL      time.Duration // 时间线闲置超过该时间后从内存中丢弃，再次访问时重新回填
+ScheduleInterval     time.Duration // 扫描到期定时帖子的间隔
+}
+
+// StoryConfig 快拍相关配置
+type StoryConfig struct {
+TTL             time.Duration // 快拍发布后的有效期
+CleanupInterval time.Duration // 清理过期快拍及其媒体文件的间隔
+}
+
+// ReactionConfig 表情回应配置
+type ReactionConfig struct {
+Emojis []string // 可用的表情回应，按展示顺序排列
+}
+
+// SearchConfig 全文搜索配置
+type SearchConfig struct {
+SyncInterval  time.Duration // 增量同步索引的间隔，新发布或修改的内容最多延迟该时间可被搜到
+MaxHits       int           // 每类结果最多取回的命中数，可翻页的结果不超过该值
+SnippetLength int           // 高亮片段的最大字数
+}
+
 var Cfg *Config
 
 // Init 初始化配置
@@ -90,6 +134,34 @@ JWT: JWTConfig{
 Secret:      getEnv("JWT_SECRET", "your-default-secret-key"),
 ExpireHours: getEnvAsInt("JWT_EXPIRE_HOURS", 24),
 },
+Message: MessageConfig{
+RecallWindow: time.Duration(getEnvAsInt("MESSAGE_RECALL_WINDOW_SECONDS", 120)) * time.Second,
+EditWindow:   time.Duration(getEnvAsInt("MESSAGE_EDIT_WINDOW_SECONDS", 900)) * time.Second,
+},
+Feed: FeedConfig{
+HotLikeWeight:        getEnvAsFloat("FEED_HOT_LIKE_WEIGHT", 1),
+HotCommentWeight:     getEnvAsFloat("FEED_HOT_COMMENT_WEIGHT", 2),
+HotViewWeight:        getEnvAsFloat("FEED_HOT_VIEW_WEIGHT", 0.1),
+HotGravity:           getEnvAsFloat("FEED_HOT_GRAVITY", 1.8),
+HotRecomputeInterval: time.Duration(getEnvAsInt("FEED_HOT_RECOMPUTE_INTERVAL_SECONDS", 300)) * time.Second,
+HotWindow:            time.Duration(getEnvAsInt("FEED_HOT_WINDOW_DAYS", 7)) * 24 * time.Hour,
+TimelineFanoutLimit:  getEnvAsInt("FEED_TIMELINE_FANOUT_LIMIT", 1000),
+TimelineMaxLength:    getEnvAsInt("FEED_TIMELINE_MAX_LENGTH", 800),
+TimelineIdleTTL:      time.Duration(getEnvAsInt("FEED_TIMELINE_IDLE_MINUTES", 30)) * time.Minute,
+ScheduleInterval:     time.Duration(getEnvAsInt("FEED_SCHEDULE_INTERVAL_SECONDS", 30)) * time.Second,
+},
+Story: StoryConfig{
+TTL:             time.Duration(getEnvAsInt("STORY_TTL_HOURS", 24)) * time.Hour,
+CleanupInterval: time.Duration(getEnvAsInt("STORY_CLEANUP_INTERVAL_SECONDS", 300)) * time.Second,
+},
+Reaction: ReactionConfig{
+Emojis: getEnvAsList("REACTIONS", []string{"👍", "❤️", "😂", "😮", "😢", "🎉"}),
+},
+Search: SearchConfig{
+SyncInterval:  time.Duration(getEnvAsInt("SEARCH_SYNC_INTERVAL_SECONDS", 10)) * time.Second,
+MaxHits:       getEnvAsInt("SEARCH_MAX_HITS", 1000),
+SnippetLength: getEnvAsInt("SEARCH_SNIPPET_LENGTH", 80),
+},
 }
 
 // 构建数据库连接字符串（云服务器）
@@ -119,6 +191,32 @@ return intValue
 return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+if value, exists := os.LookupEnv(key); exists {
+if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+return floatValue
+}
+}
+return defaultValue
+}
+
+func getEnvAsList(key string, defaultValue []string) []string {
+value := os.Getenv(key)
+if value == "" {
+return defaultValue
+}
+var list []string
+for _, item := range strings.Split(value, ",") {
+if item = strings.TrimSpace(item); item != "" {
+list = append(list, item)
+}
+}
+if len(list) == 0 {
+return defaultValue
+}
+return list
+}
+
 // IsProduction 是否为生产环境
 func IsProduction() bool {
 return Cfg.App.Env == "production"